@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/diff"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/backend"
+)
+
+// runDiff — подкоманда "indexer diff": сравнивает две ревизии конфигурации и печатает JSON в stdout.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	configID := fs.String("config", config.ConfigID(), "Configuration identifier. Default: MCP_1C_STRUCTURE_CONFIG_ID or \"default\"")
+	from := fs.Int("from", 0, "Old revision number. Default: the revision before -to")
+	to := fs.Int("to", 0, "New revision number. Default: the latest revision")
+	fromVersion := fs.String("from-version", "", "Old revision by configVersion (latest revision with this version)")
+	toVersion := fs.String("to-version", "", "New revision by configVersion (latest revision with this version)")
+	migrate := fs.String("migrate", config.MigrateMode(), "Schema migrations on startup: apply (apply pending embedded migrations) or check (verify the schema version only, change nothing). Default: MCP_1C_STRUCTURE_MIGRATE or apply")
+	fs.Parse(args)
+	if *configID == "" {
+		*configID = store.DefaultConfigID
+	}
+	if config.DatabaseURL() == "" {
+		log.Fatal("Set MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN to run indexer")
+	}
+
+	s, err := backend.Open(*migrate)
+	if err != nil {
+		log.Fatalf("Connect: %v", err)
+	}
+	defer s.Close()
+	rep, err := diff.Between(context.Background(), s, *configID, diff.Selection{
+		FromRevision: *from, ToRevision: *to, FromVersion: *fromVersion, ToVersion: *toVersion,
+	})
+	if err != nil {
+		log.Fatalf("Diff: %v", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		log.Fatalf("Write: %v", err)
+	}
+}
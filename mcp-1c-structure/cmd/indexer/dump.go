@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/dump"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/backend"
+)
+
+// maxDumpWarnings — сколько замечаний чтения выгрузки печатать; об остальных сообщается числом.
+const maxDumpWarnings = 20
+
+// runDump — подкоманда "indexer dump": собирает снимок из выгрузки конфигурации в файлы (DumpConfigToFiles)
+// и импортирует его; с -out снимок сначала записывается в каталог и импортируется из него.
+func runDump(args []string) {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	dir := fs.String("dir", "", "Path to the DumpConfigToFiles export (the directory with Configuration.xml)")
+	out := fs.String("out", "", "Also write the converted snapshot to this directory (meta.json, objects.json, relations.json, modules/). Without a database only the snapshot is written")
+	configID := fs.String("config", config.ConfigID(), "Configuration identifier to import into. Default: MCP_1C_STRUCTURE_CONFIG_ID or \"default\"")
+	migrate := fs.String("migrate", config.MigrateMode(), "Schema migrations on startup: apply (apply pending embedded migrations) or check (verify the schema version only, change nothing). Default: MCP_1C_STRUCTURE_MIGRATE or apply")
+	strict := fs.Bool("strict", false, "Reject the import if snapshot validation finds errors")
+	fs.Parse(args)
+	if *dir == "" {
+		log.Fatal("Set -dir to the DumpConfigToFiles export directory")
+	}
+	if *configID == "" {
+		*configID = store.DefaultConfigID
+	}
+	if config.DatabaseURL() == "" && *out == "" {
+		log.Fatal("Set MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN to import the dump, or -out to only write the snapshot")
+	}
+	if config.DatabaseURL() != "" && config.Backend() == config.BackendMemory {
+		log.Fatal("Indexer needs a persistent backend (postgres or sqlite), not memory")
+	}
+
+	d, err := dump.Open(*dir)
+	if err != nil {
+		log.Fatalf("Open dump: %v", err)
+	}
+	var src snapshot.Source = d
+	if *out != "" {
+		if err := snapshot.WriteDir(*out, d); err != nil {
+			log.Fatalf("Write snapshot: %v", err)
+		}
+		logDumpWarnings(d.Warnings())
+		meta, _ := d.Meta()
+		log.Printf("Snapshot written: %s %s, %d objects to %s", meta.ConfigName, meta.ConfigVersion, meta.ObjectCount, *out)
+		if config.DatabaseURL() == "" {
+			return
+		}
+		if src, err = snapshot.OpenDir(*out); err != nil {
+			log.Fatalf("Open snapshot: %v", err)
+		}
+	}
+
+	s, err := backend.Open(*migrate)
+	if err != nil {
+		log.Fatalf("Connect: %v", err)
+	}
+	defer s.Close()
+	res, err := s.Import(context.Background(), *configID, src, store.ImportOptions{Strict: *strict})
+	if *out == "" {
+		logDumpWarnings(d.Warnings())
+	}
+	logValidation(res.Validation)
+	if err != nil {
+		log.Fatalf("Import: %v", err)
+	}
+	log.Printf("Import done: %s %s %s, %d objects, %d relations from %s, revision %d", *configID, res.Meta.ConfigName, res.Meta.ConfigVersion, res.ObjectCount, res.RelationCount, *dir, res.Revision)
+	log.Printf("Import stats: clear %v, objects %v, relations %v, finalize %v, total %v", res.Stats.Clear, res.Stats.Objects, res.Stats.Relations, res.Stats.Finalize, res.Stats.Total)
+}
+
+func logDumpWarnings(warnings []string) {
+	for i, w := range warnings {
+		if i == maxDumpWarnings {
+			log.Printf("Dump warning: %d more", len(warnings)-i)
+			break
+		}
+		log.Printf("Dump warning: %s", w)
+	}
+}
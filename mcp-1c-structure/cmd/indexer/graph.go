@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/graph"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/backend"
+)
+
+// runGraph — подкоманда "indexer graph": строит подграф вокруг выбранных объектов и печатает его в stdout
+// в формате DOT, Mermaid или GraphML.
+func runGraph(args []string) {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	configID := fs.String("config", config.ConfigID(), "Configuration identifier. Default: MCP_1C_STRUCTURE_CONFIG_ID or \"default\"")
+	ids := fs.String("ids", "", "Comma-separated object ids to start from (e.g. cat.Контрагенты,doc.Заказ)")
+	objectType := fs.String("type", "", "Start from objects of this metadata type (with -query: filter the search)")
+	query := fs.String("query", "", "Start from objects whose name or synonym contains this substring")
+	depth := fs.Int("depth", graph.DefaultDepth, fmt.Sprintf("Steps over relations from the start objects, 0 to %d; 0 exports only the start objects and relations among them", graph.MaxDepth))
+	direction := fs.String("direction", "both", "Relations to follow: both, incoming or outgoing")
+	kinds := fs.String("kinds", "", "Comma-separated relation kinds to follow and export. Default: all")
+	format := fs.String("format", graph.FormatDOT, "Output format: dot, mermaid or graphml")
+	maxNodes := fs.Int("max-nodes", graph.DefaultMaxNodes, fmt.Sprintf("Maximum objects in the graph, up to %d", graph.MaxNodes))
+	migrate := fs.String("migrate", config.MigrateMode(), "Schema migrations on startup: apply (apply pending embedded migrations) or check (verify the schema version only, change nothing). Default: MCP_1C_STRUCTURE_MIGRATE or apply")
+	fs.Parse(args)
+	if *configID == "" {
+		*configID = store.DefaultConfigID
+	}
+	if config.DatabaseURL() == "" {
+		log.Fatal("Set MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN to run indexer")
+	}
+
+	s, err := backend.Open(*migrate)
+	if err != nil {
+		log.Fatalf("Connect: %v", err)
+	}
+	defer s.Close()
+	g, err := graph.Build(context.Background(), s, *configID, graph.Selection{
+		IDs: splitList(*ids), Type: *objectType, Query: *query, Depth: *depth,
+		Direction: *direction, Kinds: splitList(*kinds), MaxNodes: *maxNodes,
+	})
+	if err != nil {
+		log.Fatalf("Graph: %v", err)
+	}
+	text, err := graph.Render(g, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if g.Truncated {
+		log.Printf("Graph truncated to %d objects (-max-nodes)", len(g.Nodes))
+	}
+	if _, err := os.Stdout.WriteString(text); err != nil {
+		log.Fatalf("Write: %v", err)
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
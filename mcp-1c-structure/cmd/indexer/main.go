@@ -3,29 +3,57 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/ser/mcp-1c-structure/internal/config"
 	"github.com/ser/mcp-1c-structure/internal/snapshot"
 	"github.com/ser/mcp-1c-structure/internal/store"
-	"github.com/ser/mcp-1c-structure/internal/store/postgres"
+	"github.com/ser/mcp-1c-structure/internal/store/backend"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiff(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "graph" {
+		runGraph(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		runDump(os.Args[2:])
+		return
+	}
+
 	snapshotDir := flag.String("snapshot", "", "Path to snapshot directory (meta.json, objects.json, relations.json). Default: MCP_1C_STRUCTURE_SNAPSHOT_DIR or ./snapshot")
 	httpAddr := flag.String("http", "", "If set, run HTTP server on this address (e.g. :8080) and accept POST with snapshot JSON instead of loading from disk")
+	configID := flag.String("config", config.ConfigID(), "Configuration identifier to import into. Default: MCP_1C_STRUCTURE_CONFIG_ID or \"default\"")
+	migrate := flag.String("migrate", config.MigrateMode(), "Schema migrations on startup: apply (apply pending embedded migrations) or check (verify the schema version only, change nothing). Default: MCP_1C_STRUCTURE_MIGRATE or apply")
+	strict := flag.Bool("strict", false, "Reject the import if snapshot validation finds errors (dangling relations, duplicate ids, objects without id or type). In -http mode this is the default for requests without ?strict=")
 	flag.Parse()
+	if *configID == "" {
+		*configID = store.DefaultConfigID
+	}
 
-	dbURL := config.DatabaseURL()
-	if dbURL == "" {
+	if config.DatabaseURL() == "" {
 		log.Fatal("Set MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN to run indexer")
 	}
+	if config.Backend() == config.BackendMemory {
+		log.Fatal("Indexer needs a persistent backend (postgres or sqlite), not memory")
+	}
 
 	if *httpAddr != "" {
-		runHTTPServer(dbURL, *httpAddr)
+		runHTTPServer(*httpAddr, *configID, *migrate, *strict)
 		return
 	}
 
@@ -36,45 +64,82 @@ func main() {
 	if *snapshotDir == "" {
 		*snapshotDir = "snapshot"
 	}
-	meta, objects, relations, err := snapshot.LoadSnapshot(*snapshotDir)
+	if snapshot.IsDeltaDir(*snapshotDir) {
+		applyDeltaDir(*snapshotDir, *configID, *migrate, *strict)
+		os.Exit(0)
+	}
+	src, err := snapshot.OpenDir(*snapshotDir)
 	if err != nil {
-		log.Fatalf("Load snapshot: %v", err)
+		log.Fatalf("Open snapshot: %v", err)
 	}
-	log.Printf("Loaded %d objects, %d relations from %s", len(objects), len(relations), *snapshotDir)
-	s, err := postgres.New(dbURL)
+	s, err := backend.Open(*migrate)
 	if err != nil {
 		log.Fatalf("Connect: %v", err)
 	}
 	defer s.Close()
-	if err := s.Import(context.Background(), meta, objects, relations); err != nil {
+	res, err := s.Import(context.Background(), *configID, src, store.ImportOptions{Strict: *strict})
+	logValidation(res.Validation)
+	if err != nil {
 		log.Fatalf("Import: %v", err)
 	}
-	log.Printf("Import done: %s %s", meta.ConfigName, meta.ConfigVersion)
+	log.Printf("Import done: %s %s %s, %d objects, %d relations from %s, revision %d", *configID, res.Meta.ConfigName, res.Meta.ConfigVersion, res.ObjectCount, res.RelationCount, *snapshotDir, res.Revision)
+	log.Printf("Import stats: clear %v, objects %v, relations %v, finalize %v, total %v", res.Stats.Clear, res.Stats.Objects, res.Stats.Relations, res.Stats.Finalize, res.Stats.Total)
 	os.Exit(0)
 }
 
-// SnapshotPayload — тело POST запроса с полным снимком структуры
-type SnapshotPayload struct {
-	Meta     snapshot.Meta      `json:"meta"`
-	Objects  []snapshot.Object  `json:"objects"`
-	Relations []snapshot.Relation `json:"relations"`
+// DeltaPayload — тело POST /import/delta: изменения относительно текущего снимка конфигурации.
+type DeltaPayload struct {
+	ConfigID string `json:"configId"`
+	snapshot.Delta
+}
+
+// applyDeltaDir применяет delta.json из каталога к конфигурации configID.
+func applyDeltaDir(dir, configID, migrate string, strict bool) {
+	delta, err := snapshot.LoadDelta(dir)
+	if err != nil {
+		log.Fatalf("Load delta: %v", err)
+	}
+	log.Printf("Loaded delta from %s: %d upserted, %d deleted, +%d/-%d relations", dir, len(delta.Upserted), len(delta.Deleted), len(delta.AddRelations), len(delta.RemoveRelations))
+	s, err := backend.Open(migrate)
+	if err != nil {
+		log.Fatalf("Connect: %v", err)
+	}
+	defer s.Close()
+	res, err := s.ApplyDelta(context.Background(), configID, delta, store.ImportOptions{Strict: strict})
+	logValidation(res.Validation)
+	if err != nil {
+		log.Fatalf("Apply delta: %v", err)
+	}
+	log.Printf("Delta applied: %s, revision %d", configID, res.Revision)
+}
+
+// logValidation печатает отчёт проверки снимка: по строке на вид проблемы с примерами.
+func logValidation(report store.ValidationReport) {
+	for _, issue := range report.Errors {
+		log.Printf("Validation error %s (%d): %s, e.g. %s", issue.Code, issue.Count, issue.Message, strings.Join(issue.Examples, "; "))
+	}
+	for _, issue := range report.Warnings {
+		log.Printf("Validation warning %s (%d): %s, e.g. %s", issue.Code, issue.Count, issue.Message, strings.Join(issue.Examples, "; "))
+	}
 }
 
-func runHTTPServer(dbURL, addr string) {
-	s, err := postgres.New(dbURL)
+func runHTTPServer(addr, defaultConfigID, migrate string, defaultStrict bool) {
+	s, err := backend.Open(migrate)
 	if err != nil {
 		log.Fatalf("Connect: %v", err)
 	}
 	defer s.Close()
 
-	http.HandleFunc("/import", handleImport(s))
+	http.HandleFunc("/import", handleImport(s, defaultConfigID, defaultStrict))
+	http.HandleFunc("/import/delta", handleDelta(s, defaultConfigID, defaultStrict))
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
 			http.NotFound(w, r)
 			return
 		}
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		w.Write([]byte("Indexer. POST /import with JSON body: { \"meta\": {...}, \"objects\": [...], \"relations\": [...] }\n"))
+		w.Write([]byte("Indexer. POST /import with JSON body: { \"configId\": \"...\", \"meta\": {...}, \"objects\": [...], \"relations\": [...] }\n" +
+			"POST /import/delta with JSON body: { \"configId\": \"...\", \"meta\": {...}, \"upserted\": [...], \"deleted\": [...], \"addRelations\": [...], \"removeRelations\": [...] }\n"))
 	})
 
 	log.Printf("HTTP indexer listening on %s", addr)
@@ -83,31 +148,124 @@ func runHTTPServer(dbURL, addr string) {
 	}
 }
 
-func handleImport(s store.Store) http.HandlerFunc {
+func handleImport(s store.Store, defaultConfigID string, defaultStrict bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.Header().Set("Allow", "POST")
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		var payload SnapshotPayload
-		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		strict, err := strictParam(r, defaultStrict)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Тело читается потоком (snapshot.PayloadReader): снимок не собирается в памяти целиком.
+		// configId, если указан, должен стоять в теле до objects.
+		payload := snapshot.NewPayloadReader(r.Body)
+		configID, err := payload.ConfigID()
+		if err != nil {
 			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
 			return
 		}
-		if err := s.Import(r.Context(), payload.Meta, payload.Objects, payload.Relations); err != nil {
+		if configID == "" {
+			configID = defaultConfigID
+		}
+		res, err := s.Import(r.Context(), configID, payload, store.ImportOptions{Strict: strict})
+		var verr *store.ValidationError
+		if errors.As(err, &verr) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "configId": configID, "error": err.Error(), "validation": verr.Report})
+			return
+		}
+		if err != nil && payload.Err() != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
 			log.Printf("Import error: %v", err)
 			http.Error(w, "import failed: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		resp := map[string]any{
+			"ok":                true,
+			"configId":          configID,
+			"revision":          res.Revision,
+			"objectCount":       res.ObjectCount,
+			"relationsImported": res.RelationCount,
+			"configName":        res.Meta.ConfigName,
+			"configVersion":     res.Meta.ConfigVersion,
+			"stats":             res.Stats,
+			"validation":        res.Validation,
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func handleDelta(s store.Store, defaultConfigID string, defaultStrict bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		strict, err := strictParam(r, defaultStrict)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var payload DeltaPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		configID := payload.ConfigID
+		if configID == "" {
+			configID = defaultConfigID
+		}
+		res, err := s.ApplyDelta(r.Context(), configID, payload.Delta, store.ImportOptions{Strict: strict})
+		var verr *store.ValidationError
+		if errors.As(err, &verr) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "configId": configID, "error": err.Error(), "validation": verr.Report})
+			return
+		}
+		if errors.Is(err, store.ErrNoRevisions) {
+			http.Error(w, "delta failed: "+err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("Delta error: %v", err)
+			http.Error(w, "delta failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		resp := map[string]any{
 			"ok":               true,
-			"objectCount":      len(payload.Objects),
-			"relationsImported": len(payload.Relations),
-			"configName":       payload.Meta.ConfigName,
-			"configVersion":    payload.Meta.ConfigVersion,
+			"configId":         configID,
+			"revision":         res.Revision,
+			"upserted":         len(payload.Upserted),
+			"deleted":          len(payload.Deleted),
+			"relationsAdded":   len(payload.AddRelations),
+			"relationsRemoved": len(payload.RemoveRelations),
+			"validation":       res.Validation,
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	}
 }
+
+// strictParam читает параметр запроса strict; без него — значение флага -strict.
+func strictParam(r *http.Request, defaultStrict bool) (bool, error) {
+	v := r.URL.Query().Get("strict")
+	if v == "" {
+		return defaultStrict, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, errors.New("invalid strict: " + v)
+	}
+	return b, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+func TestHandleDelta(t *testing.T) {
+	s, err := memory.New("default", "../../snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := handleDelta(s, "default", false)
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{name: "только POST", method: http.MethodGet, target: "/import/delta", status: http.StatusMethodNotAllowed},
+		{name: "невалидный JSON", method: http.MethodPost, target: "/import/delta", body: `{"upserted": [`, status: http.StatusBadRequest},
+		{name: "конфигурация без ревизий", method: http.MethodPost, target: "/import/delta",
+			body: `{"configId": "empty", "upserted": [{"id": "cat.Склады", "type": "Catalog", "name": "Склады"}]}`, status: http.StatusConflict},
+		{name: "неверный strict", method: http.MethodPost, target: "/import/delta?strict=да", body: `{}`, status: http.StatusBadRequest},
+		{name: "strict и связь с отсутствующим концом", method: http.MethodPost, target: "/import/delta?strict=true",
+			body: `{"addRelations": [{"from": "cat.Контрагенты", "to": "cat.НетТакого", "kind": "reference"}]}`, status: http.StatusUnprocessableEntity},
+		{name: "дельта применяется", method: http.MethodPost, target: "/import/delta",
+			body: `{"upserted": [{"id": "cat.Склады", "type": "Catalog", "name": "Склады"}]}`, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/import/delta", strings.NewReader(`{"deleted": ["cat.Склады"]}`)))
+	var resp struct {
+		OK       bool `json:"ok"`
+		Revision int  `json:"revision"`
+		Deleted  int  `json:"deleted"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if !resp.OK || resp.Revision != 3 || resp.Deleted != 1 {
+		t.Errorf("unexpected response %s", rec.Body)
+	}
+	if _, ok, _ := s.GetObject(context.Background(), "default", "cat.Склады"); ok {
+		t.Error("deleted object is still there")
+	}
+}
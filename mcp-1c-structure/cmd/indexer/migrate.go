@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/store/backend"
+)
+
+// runMigrate — подкоманда "indexer migrate": применяет встроенные миграции схемы (или с -check только проверяет версию) и завершается.
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	check := fs.Bool("check", false, "Only verify that the database schema matches this binary; exit with an error if migrations are pending or the schema is newer")
+	fs.Parse(args)
+	if config.DatabaseURL() == "" {
+		log.Fatal("Set MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN to run indexer")
+	}
+	if config.Backend() == config.BackendMemory {
+		log.Fatal("Indexer needs a persistent backend (postgres or sqlite), not memory")
+	}
+	mode := config.MigrateApply
+	if *check {
+		mode = config.MigrateCheck
+	}
+	s, err := backend.Open(mode)
+	if err != nil {
+		log.Fatalf("Schema: %v", err)
+	}
+	s.Close()
+	log.Printf("Schema is up to date")
+}
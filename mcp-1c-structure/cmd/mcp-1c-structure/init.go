@@ -2,25 +2,42 @@ package main
 
 import (
 	"context"
-	"errors"
+	"log"
 
+	"github.com/ser/mcp-1c-structure/internal/config"
 	"github.com/ser/mcp-1c-structure/internal/snapshot"
 	"github.com/ser/mcp-1c-structure/internal/store"
-	"github.com/ser/mcp-1c-structure/internal/store/postgres"
+	"github.com/ser/mcp-1c-structure/internal/store/backend"
 )
 
-func initStore(dbURL string) (store.Store, snapshot.Meta, error) {
-	if dbURL == "" {
-		return nil, snapshot.Meta{}, errors.New("MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN required")
-	}
-	s, err := postgres.New(dbURL)
+func initStore(migrate string) (store.Store, snapshot.Meta, error) {
+	s, err := backend.Open(migrate)
 	if err != nil {
 		return nil, snapshot.Meta{}, err
 	}
-	meta, err := s.Meta(context.Background())
+	checkIndexVersions(s)
+	configID := config.ConfigID()
+	if configID == "" {
+		configID = store.DefaultConfigID
+	}
+	meta, err := s.Meta(context.Background(), configID)
 	if err != nil {
 		_ = s.Close()
 		return nil, snapshot.Meta{}, err
 	}
 	return s, meta, nil
 }
+
+// checkIndexVersions предупреждает о конфигурациях, загруженных из снимка более новой версии формата, чем понимает сервер.
+func checkIndexVersions(s store.Store) {
+	configs, err := s.ListConfigs(context.Background())
+	if err != nil {
+		log.Printf("List configs: %v", err)
+		return
+	}
+	for _, c := range configs {
+		if c.Meta.IndexVersion > snapshot.IndexVersion {
+			log.Printf("Configuration %q has snapshot indexVersion %d, this server supports %d: some data may be missing, upgrade mcp-1c-structure", c.ID, c.Meta.IndexVersion, snapshot.IndexVersion)
+		}
+	}
+}
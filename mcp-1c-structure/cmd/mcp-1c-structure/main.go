@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -13,8 +14,10 @@ const name = "mcp-1c-structure"
 const version = "0.1.0"
 
 func main() {
-	dbURL := config.DatabaseURL()
-	st, meta, err := initStore(dbURL)
+	migrate := flag.String("migrate", config.MigrateMode(), "Schema migrations on startup: apply (apply pending embedded migrations) or check (verify the schema version only, change nothing). Default: MCP_1C_STRUCTURE_MIGRATE or apply")
+	flag.Parse()
+
+	st, meta, err := initStore(*migrate)
 	if err != nil {
 		log.Fatalf("Init store: %v", err)
 	}
@@ -25,34 +28,99 @@ func main() {
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "structure_snapshot_info",
-		Description: "Информация о загруженном снимке структуры конфигурации 1С: имя, версия, дата выгрузки, число объектов.",
+		Description: "Информация о загруженном снимке структуры конфигурации 1С: имя, версия, дата выгрузки, число объектов. Параметр: configId.",
 	}, tools.SnapshotInfo)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_list_configs",
+		Description: "Список загруженных конфигураций 1С: configId и метаданные снимка (имя, версия, дата выгрузки, число объектов).",
+	}, tools.ListConfigs)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "structure_search",
-		Description: "Поиск объектов по имени/синониму (подстрока). Параметры: query (обязательный), type, limit, offset.",
+		Description: "Поиск объектов. mode=substring (по умолчанию) — подстрока в имени или синониме, по алфавиту; mode=fulltext — по словам с учётом русской морфологии в имени, синониме, описании, реквизитах и колонках табличных частей, по убыванию релевантности, с полями score и matchedField; mode=fuzzy — по сходству имени или синонима с запросом, в том числе набранным не в той раскладке (Rjynhfutyns), транслитом (Kontragenty) или с опечатками, по убыванию сходства. fuzzy=true в режиме substring повторяет поиск как fuzzy, если ничего не нашлось. Параметры: query (обязательный), mode, fuzzy, type, limit, offset, configId.",
 	}, tools.Search)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "structure_get_object",
-		Description: "Полное описание объекта по идентификатору (objectId).",
+		Description: "Полное описание объекта по идентификатору (objectId). У регистров у реквизитов есть kind (dimension — измерение, resource — ресурс, attribute — реквизит), а в ответе — register: измерения, ресурсы и реквизиты по именам, свойства (periodicity, writeMode, registerType) и виртуальные таблицы, которые допускает регистр. Формы перечисляются именами, их устройство отдаёт structure_get_form. Параметры: objectId, configId.",
 	}, tools.GetObject)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_find_by_prop",
+		Description: "Поиск объектов по реквизитам: какие объекты имеют реквизит с данным именем или типом (например CatalogRef.Контрагенты), в том числе среди колонок табличных частей. propKind (dimension, resource, attribute) отбирает поля регистров по виду: например, регистры с измерением типа CatalogRef.Контрагенты. Для каждого совпадения — объект, табличная часть (пусто для реквизита объекта) и реквизит. Параметры: propName, propType, tabularSection, propKind (хотя бы один), objectType, limit, offset, configId.",
+	}, tools.FindByProp)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "structure_find_references",
-		Description: "Входящие и исходящие связи объекта. Параметры: objectId, direction (incoming/outgoing/both), kind, limit.",
+		Description: "Входящие и исходящие связи объекта. Связи reference выводятся и из типов реквизитов (CatalogRef.Контрагенты) — у таких связей есть prop, путь реквизита (Контрагент или Товары.Номенклатура), поэтому «где используется справочник» видно и без relations.json. Параметры: objectId, direction (incoming/outgoing/both), kind, limit, configId.",
 	}, tools.FindReferences)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_impact_analysis",
+		Description: "Анализ влияния: все объекты, транзитивно зависящие от объекта (direction=incoming, по умолчанию — что затронет изменение справочника) или от которых он зависит (outgoing), с обходом связей на заданную глубину. Результат по уровням: для каждого объекта глубина, кратчайший путь (id от исходного объекта), вид и реквизит последней связи. Параметры: objectId (обязательный), direction, kinds (виды связей, по умолчанию любые), maxDepth (по умолчанию 3, макс. 10), maxNodes (по умолчанию 200, макс. 1000), configId.",
+	}, tools.ImpactAnalysis)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_find_path",
+		Description: "Кратчайшие пути по связям от одного объекта к другому (по направлению связей): как fromId зависит от toId, например документ от общего модуля. Для каждого пути — объекты по порядку и связи между ними (kind, prop). Если пути нет в пределах maxDepth — found=false и признак reverseFound, есть ли путь в обратную сторону. Параметры: fromId, toId (обязательные), kinds (виды связей, по умолчанию любые), maxDepth (по умолчанию 6, макс. 10), maxPaths (по умолчанию 5, макс. 20), configId.",
+	}, tools.FindPath)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_export_graph",
+		Description: "Экспорт подграфа структуры для документации и ревью: исходные объекты (objectIds, объекты типа type и/или найденные по подстроке query), объекты в пределах depth шагов по связям (по умолчанию 1, макс. 3; 0 — только исходные) и все связи между ними. Формат: dot (Graphviz, по умолчанию), mermaid (flowchart) или graphml. Узлы подписаны синонимом и типом, рёбра — видом связи; исходные объекты выделены. Параметры: objectIds, type, query (хотя бы один), depth, direction (both/incoming/outgoing), kinds, format, maxNodes (по умолчанию 100, макс. 500), configId.",
+	}, tools.ExportGraph)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_subsystem_tree",
+		Description: "Подсистемы конфигурации. Без параметров — дерево подсистем с числом объектов в каждой; subsystemId — подсистема с вложенными, путь к ней от корня и её состав (recursive=true — вместе с составом вложенных подсистем; limit по умолчанию 200, макс. 1000, offset); objectId — подсистемы, в которые входит объект, с путём от корня. Параметры: subsystemId, objectId, recursive, limit, offset, configId.",
+	}, tools.SubsystemTree)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_get_form",
+		Description: "Устройство формы объекта: реквизиты формы с типами, дерево элементов с путями к данным, команды и обработчики событий с процедурами модуля формы. Без form — список форм объекта; form — форма целиком и плоский список обработчиков (handlers: source form/element/command, event, procedure); element (вместе с form) — элементы с таким именем или путём к данным (Контрагент находит Объект.Контрагент) и их обработчики: «что срабатывает при изменении поля». Параметры: objectId (обязательный), form, element, configId.",
+	}, tools.GetForm)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_register_records",
+		Description: "Движения документов по связям register_record. documentId — регистры, в которые документ записывает движения; registerId — документы-регистраторы регистра и recorderMode (required — регистр подчинён регистратору, forbidden — независимый регистр сведений). Указывается ровно один из них. Параметры: documentId, registerId, configId.",
+	}, tools.RegisterRecords)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_get_module",
+		Description: "Текст модуля объекта (ObjectModule, ManagerModule, RecordSetModule, модуль формы и т.п.), если он выгружен в снимок. Без module — список модулей объекта с признаком hasSource и числом строк; с module — текст строк fromLine..toLine (нумерация с 1, по умолчанию весь модуль, не больше 2000 строк за вызов) и lineCount. Параметры: objectId (обязательный), module, fromLine, toLine, configId.",
+	}, tools.GetModule)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_search_code",
+		Description: "Поиск по текстам модулей: где в коде встречается строка (по умолчанию подстрока без учёта регистра) или регулярное выражение (regex: true, синтаксис RE2). Фильтры objectType, objectId, module. Для каждого совпадения — объект, модуль, номер строки, строка и context соседних строк до и после (по умолчанию 2, не больше 10). limit — по умолчанию 50, не больше 500; truncated — совпадений больше. Параметры: query (обязательный), regex, caseSensitive, objectType, objectId, module, context, limit, configId.",
+	}, tools.SearchCode)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_find_method",
+		Description: "Поиск процедур и функций в модулях (общих модулях, модулях объектов, менеджеров и форм) по имени — проверка, что метод существует, прежде чем его вызывать. name — имя метода или «Модуль.Метод» как в вызове (ОбщийМодульКлиент.СообщитьПользователю, CommonModule.X.Метод). Для каждого метода — объект, модуль, вид (procedure/function), параметры (name, byVal — Знач, default — значение по умолчанию), export — Экспорт (вызывать из других модулей можно только экспортные), директивы компиляции (&НаСервере, &НаКлиенте), область (#Область), комментарий-описание, строки объявления и конца. match — exact (по умолчанию), prefix, contains; без учёта регистра. Если метод не найден — similar: методы с похожими именами. Параметры: name (обязательный), match, objectType, objectId, module, exportOnly, limit (по умолчанию 50, не больше 500), configId.",
+	}, tools.FindMethod)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "structure_list_types",
-		Description: "Список типов метаданных в снимке и количество объектов по каждому типу.",
+		Description: "Список типов метаданных в снимке и количество объектов по каждому типу. Параметр: configId.",
 	}, tools.ListTypes)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "structure_import_snapshot",
-		Description: "Загрузить снимок структуры из каталога (meta.json, objects.json, relations.json) в базу данных. Параметр: snapshotDir — путь к каталогу.",
+		Description: "Загрузить снимок структуры из каталога (meta.json, objects.json, relations.json) в базу данных. Параметры: snapshotDir — путь к каталогу, configId — идентификатор конфигурации, strict — отклонить импорт, если проверка снимка нашла ошибки. В ответе — отчёт проверки (validation).",
 	}, tools.ImportSnapshot)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_list_revisions",
+		Description: "История импортов конфигурации: номер ревизии, версия конфигурации, дата выгрузки и импорта. Параметр: configId.",
+	}, tools.ListRevisions)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "structure_diff_snapshots",
+		Description: "Сравнение двух ревизий снимка: добавленные/удалённые объекты, реквизиты и колонки табличных частей (добавлены, удалены, сменили тип), формы, модули, связи. Параметры: fromRevision, toRevision (или fromVersion, toVersion — версия конфигурации), configId. По умолчанию последняя ревизия сравнивается с предыдущей.",
+	}, tools.DiffSnapshots)
+
 	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
@@ -0,0 +1,260 @@
+// Package bsl разбирает тексты модулей 1С (встроенный язык, файлы .bsl) до уровня объявлений: процедуры и функции
+// с параметрами, признаком экспорта, директивами компиляции, областью и комментарием-описанием. Тела методов
+// не разбираются; объявление, как и принято во встроенном языке, должно начинать строку.
+package bsl
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Виды методов.
+const (
+	KindProcedure = "procedure"
+	KindFunction  = "function"
+)
+
+// maxHeaderLines — на скольких строках может быть записано объявление метода; дальше оно считается оборванным.
+const maxHeaderLines = 50
+
+// Param — параметр метода.
+type Param struct {
+	Name    string `json:"name"`
+	ByVal   bool   `json:"byVal,omitempty"`   // Знач
+	Default string `json:"default,omitempty"` // значение по умолчанию как в тексте; пусто — параметр обязательный
+}
+
+// Method — объявление процедуры или функции модуля. Строки считаются с 1, как в конфигураторе.
+type Method struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"` // KindProcedure или KindFunction
+	Params     []Param  `json:"params"`
+	Export     bool     `json:"export"`
+	Async      bool     `json:"async,omitempty"`      // Асинх
+	Directives []string `json:"directives,omitempty"` // &НаСервере, &НаКлиенте, &Вместо("…") — как в тексте
+	Region     string   `json:"region,omitempty"`     // вложенные #Область от внешней к внутренней через «/»
+	Doc        string   `json:"doc,omitempty"`        // комментарий перед объявлением без «//»
+	Line       int      `json:"line"`
+	EndLine    int      `json:"endLine,omitempty"` // 0 — КонецПроцедуры/КонецФункции не найден
+}
+
+// Lines делит текст модуля на строки; номера строк Method и поиска по коду считаются по нему.
+func Lines(source string) []string {
+	return strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+}
+
+// Parse возвращает методы модуля в порядке объявления.
+func Parse(source string) []Method {
+	var (
+		out        []Method
+		open       = -1 // индекс метода в out, для которого ещё не встретился конец
+		regions    []string
+		doc        []string
+		directives []string
+		inString   bool
+		header     []string // строки объявления, пока не закрыта скобка параметров
+		pending    Method   // вид, Асинх и строка объявления из header
+	)
+	emit := func(m Method) {
+		m.Kind, m.Async, m.Line = pending.Kind, pending.Async, pending.Line
+		m.Directives, m.Region, m.Doc = directives, strings.Join(regions, "/"), docText(doc)
+		out = append(out, m)
+		open = len(out) - 1
+		header, doc, directives = nil, nil, nil
+	}
+	for i, raw := range Lines(source) {
+		startedInString := inString
+		code, comment, hasComment, next := splitLine(raw, inString)
+		inString = next
+
+		if header != nil {
+			header = append(header, code)
+			if m, ok := parseHeader(strings.Join(header, " ")); ok {
+				emit(m)
+			} else if len(header) >= maxHeaderLines {
+				header, doc, directives = nil, nil, nil
+			}
+			continue
+		}
+		if startedInString {
+			continue // продолжение многострочного литерала, например текста запроса
+		}
+
+		t := strings.TrimSpace(code)
+		if t == "" {
+			if hasComment {
+				doc = append(doc, comment)
+			} else {
+				doc = nil
+			}
+			continue
+		}
+		switch t[0] {
+		case '#':
+			if rest, ok := keyword(t[1:], "Область", "Region"); ok {
+				regions = append(regions, strings.TrimSpace(rest))
+			} else if _, ok := keyword(t[1:], "КонецОбласти", "EndRegion"); ok && len(regions) > 0 {
+				regions = regions[:len(regions)-1]
+			}
+			doc, directives = nil, nil
+			continue
+		case '&':
+			directives = append(directives, t)
+			continue
+		}
+
+		rest, async := t, false
+		if r, ok := keyword(rest, "Асинх", "Async"); ok {
+			rest, async = strings.TrimSpace(r), true
+		}
+		kind := ""
+		if r, ok := keyword(rest, "Процедура", "Procedure"); ok {
+			rest, kind = r, KindProcedure
+		} else if r, ok := keyword(rest, "Функция", "Function"); ok {
+			rest, kind = r, KindFunction
+		}
+		if kind != "" {
+			open = -1 // незакрытый предыдущий метод остаётся без EndLine
+			pending = Method{Kind: kind, Async: async, Line: i + 1}
+			if m, ok := parseHeader(rest); ok {
+				emit(m)
+			} else {
+				header = []string{rest}
+			}
+			continue
+		}
+		if _, ok := keyword(t, "КонецПроцедуры", "EndProcedure", "КонецФункции", "EndFunction"); ok && open >= 0 {
+			out[open].EndLine = i + 1
+			open = -1
+		}
+		doc, directives = nil, nil
+	}
+	return out
+}
+
+// splitLine отделяет код строки от комментария «//» с учётом строковых литералов. inString — строка начинается
+// внутри литерала, продолженного с предыдущей строки; возвращается то же для следующей строки.
+func splitLine(line string, inString bool) (code, comment string, hasComment, stillInString bool) {
+	start := 0
+	if inString {
+		t := strings.TrimLeft(line, " \t")
+		switch {
+		case strings.HasPrefix(t, "|"):
+			start = len(line) - len(t) + 1
+		case strings.HasPrefix(t, "//"):
+			return "", "", false, true // закомментированная строка внутри текста запроса
+		default:
+			inString = false // продолжение литерала начинается с «|»; иначе литерал был оборван
+		}
+	}
+	for i := start; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString:
+			if c == '"' {
+				if i+1 < len(line) && line[i+1] == '"' {
+					i++
+				} else {
+					inString = false
+				}
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i], line[i+2:], true, false
+		}
+	}
+	return line, "", false, inString
+}
+
+// parseHeader разбирает объявление после ключевого слова: «Имя(Параметры) Экспорт». ok=false — скобка
+// параметров ещё не закрыта.
+func parseHeader(text string) (Method, bool) {
+	open := strings.IndexByte(text, '(')
+	if open < 0 {
+		return Method{}, false
+	}
+	var (
+		params []Param
+		depth  int
+		quoted bool
+		from   = open + 1
+	)
+	for i := open; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				if p, ok := parseParam(text[from:i]); ok {
+					params = append(params, p)
+				}
+				m := Method{Name: strings.TrimSpace(text[:open]), Params: params}
+				if m.Params == nil {
+					m.Params = []Param{}
+				}
+				for _, w := range strings.Fields(text[i+1:]) {
+					if strings.EqualFold(w, "Экспорт") || strings.EqualFold(w, "Export") {
+						m.Export = true
+					}
+				}
+				return m, true
+			}
+		case c == ',' && depth == 1:
+			if p, ok := parseParam(text[from:i]); ok {
+				params = append(params, p)
+			}
+			from = i + 1
+		}
+	}
+	return Method{}, false
+}
+
+// parseParam разбирает «[Знач] Имя [= Значение]»; пустой текст — параметров нет.
+func parseParam(text string) (Param, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Param{}, false
+	}
+	var p Param
+	if rest, ok := keyword(text, "Знач", "Val"); ok {
+		p.ByVal, text = true, strings.TrimSpace(rest)
+	}
+	name, def, _ := strings.Cut(text, "=")
+	p.Name, p.Default = strings.TrimSpace(name), strings.TrimSpace(def)
+	return p, true
+}
+
+// keyword сообщает, начинается ли s с одного из слов (без учёта регистра) как с целого слова, и возвращает остаток.
+func keyword(s string, words ...string) (string, bool) {
+	for _, w := range words {
+		if len(s) < len(w) || !strings.EqualFold(s[:len(w)], w) {
+			continue
+		}
+		rest := s[len(w):]
+		if r := []rune(rest); len(r) > 0 && (unicode.IsLetter(r[0]) || unicode.IsDigit(r[0]) || r[0] == '_') {
+			continue
+		}
+		return rest, true
+	}
+	return "", false
+}
+
+// docText собирает комментарий-описание: без «//» и одного пробела после них, без строк-разделителей из «/»
+// и пустых строк по краям.
+func docText(lines []string) string {
+	var out []string
+	for _, l := range lines {
+		l = strings.TrimRight(strings.TrimPrefix(l, " "), " \t")
+		if strings.Trim(l, "/") == "" && l != "" {
+			continue
+		}
+		out = append(out, l)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
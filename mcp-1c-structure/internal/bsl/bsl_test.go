@@ -0,0 +1,98 @@
+package bsl
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []Method
+	}{
+		{
+			name: "многострочное объявление",
+			source: `// Складывает числа.
+&НаСервере
+Функция Сумма(Знач А,
+	Б = 0,
+	В = Неопределено) Экспорт
+	Возврат А + Б;
+КонецФункции`,
+			want: []Method{{
+				Name: "Сумма", Kind: KindFunction, Export: true, Directives: []string{"&НаСервере"},
+				Params: []Param{{Name: "А", ByVal: true}, {Name: "Б", Default: "0"}, {Name: "В", Default: "Неопределено"}},
+				Doc:    "Складывает числа.", Line: 3, EndLine: 7,
+			}},
+		},
+		{
+			name: "текст запроса со строкой //",
+			source: `Процедура Прочитать()
+	Запрос.Текст = "ВЫБРАТЬ
+	|	Ссылка // внутри литерала
+	//|	ГДЕ НЕ ПометкаУдаления
+	|Процедура Ложная()
+	|ИЗ Справочник.Контрагенты";
+КонецПроцедуры
+
+Процедура Следующая()
+КонецПроцедуры`,
+			want: []Method{
+				{Name: "Прочитать", Kind: KindProcedure, Params: []Param{}, Line: 1, EndLine: 7},
+				{Name: "Следующая", Kind: KindProcedure, Params: []Param{}, Line: 9, EndLine: 10},
+			},
+		},
+		{
+			name:   "запятая в кавычках в значении по умолчанию",
+			source: "Процедура Сообщить(Текст = \"а, б\", Знач Поле = \"(\")\nКонецПроцедуры",
+			want: []Method{{
+				Name: "Сообщить", Kind: KindProcedure, Line: 1, EndLine: 2,
+				Params: []Param{{Name: "Текст", Default: `"а, б"`}, {Name: "Поле", ByVal: true, Default: `"("`}},
+			}},
+		},
+		{
+			name: "метод без конца",
+			source: `Процедура Первая()
+	А = 1;
+
+Процедура Вторая()
+КонецПроцедуры`,
+			want: []Method{
+				{Name: "Первая", Kind: KindProcedure, Params: []Param{}, Line: 1},
+				{Name: "Вторая", Kind: KindProcedure, Params: []Param{}, Line: 4, EndLine: 5},
+			},
+		},
+		{
+			name:   "оборванное объявление",
+			source: "Процедура Оборванная(А,\n" + strings.Repeat("\n", maxHeaderLines) + "Процедура Целая()\nКонецПроцедуры",
+			want: []Method{
+				{Name: "Целая", Kind: KindProcedure, Params: []Param{}, Line: maxHeaderLines + 2, EndLine: maxHeaderLines + 3},
+			},
+		},
+		{
+			name: "области, Асинх и английские ключевые слова",
+			source: `#Область Публичные
+#Region Внутренняя
+Асинх Procedure Load(Val Path) Export
+EndProcedure
+#КонецОбласти
+#EndRegion
+Функция Вне()
+КонецФункции`,
+			want: []Method{
+				{Name: "Load", Kind: KindProcedure, Async: true, Export: true, Region: "Публичные/Внутренняя",
+					Params: []Param{{Name: "Path", ByVal: true}}, Line: 3, EndLine: 4},
+				{Name: "Вне", Kind: KindFunction, Params: []Param{}, Line: 7, EndLine: 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package bsl
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Виды обращений к объектам конфигурации из кода.
+const (
+	RefCall    = "call"           // ОбщийМодуль.Метод(…): вызов метода общего модуля
+	RefManager = "manager_access" // Справочники.Контрагенты: обращение к менеджеру объекта
+	RefQuery   = "query_read"     // Справочник.Контрагенты в тексте запроса
+)
+
+// Ref — обращение к объекту конфигурации из модуля. Type — тип метаданных (Catalog, Document, …), у вызова —
+// CommonModule; Name — имя объекта как в коде. Есть ли такой объект, проверяет вызывающий: в вызове Name.Метод(…)
+// Name может оказаться и переменной.
+type Ref struct {
+	Method string // метод, в котором обращение; пусто — вне методов (раздел объявлений, основная программа модуля)
+	Kind   string
+	Type   string
+	Name   string
+	Callee string // у вызова — вызываемый метод общего модуля (Метод в Name.Метод(…)), у прочих обращений пусто
+	Line   int    // первая строка с таким обращением в методе
+}
+
+// managers — коллекции менеджеров встроенного языка и тип метаданных их элементов (ключи в нижнем регистре).
+var managers = map[string]string{
+	"справочники": "Catalog", "catalogs": "Catalog",
+	"документы": "Document", "documents": "Document",
+	"перечисления": "Enum", "enums": "Enum",
+	"регистрысведений": "InformationRegister", "informationregisters": "InformationRegister",
+	"регистрынакопления": "AccumulationRegister", "accumulationregisters": "AccumulationRegister",
+	"регистрыбухгалтерии": "AccountingRegister", "accountingregisters": "AccountingRegister",
+	"регистрырасчета": "CalculationRegister", "calculationregisters": "CalculationRegister",
+	"планывидовхарактеристик": "ChartOfCharacteristicTypes", "chartsofcharacteristictypes": "ChartOfCharacteristicTypes",
+	"планысчетов": "ChartOfAccounts", "chartsofaccounts": "ChartOfAccounts",
+	"планывидоврасчета": "ChartOfCalculationTypes", "chartsofcalculationtypes": "ChartOfCalculationTypes",
+	"планыобмена": "ExchangePlan", "exchangeplans": "ExchangePlan",
+	"бизнеспроцессы": "BusinessProcess", "businessprocesses": "BusinessProcess",
+	"задачи": "Task", "tasks": "Task",
+	"константы": "Constant", "constants": "Constant",
+	"журналыдокументов": "DocumentJournal", "documentjournals": "DocumentJournal",
+	"отчеты": "Report", "reports": "Report",
+	"обработки": "DataProcessor", "dataprocessors": "DataProcessor",
+}
+
+// tables — имена таблиц языка запросов и тип метаданных (ключи в нижнем регистре).
+var tables = map[string]string{
+	"справочник": "Catalog", "catalog": "Catalog",
+	"документ": "Document", "document": "Document",
+	"перечисление": "Enum", "enum": "Enum",
+	"регистрсведений": "InformationRegister", "informationregister": "InformationRegister",
+	"регистрнакопления": "AccumulationRegister", "accumulationregister": "AccumulationRegister",
+	"регистрбухгалтерии": "AccountingRegister", "accountingregister": "AccountingRegister",
+	"регистррасчета": "CalculationRegister", "calculationregister": "CalculationRegister",
+	"планвидовхарактеристик": "ChartOfCharacteristicTypes", "chartofcharacteristictypes": "ChartOfCharacteristicTypes",
+	"плансчетов": "ChartOfAccounts", "chartofaccounts": "ChartOfAccounts",
+	"планвидоврасчета": "ChartOfCalculationTypes", "chartofcalculationtypes": "ChartOfCalculationTypes",
+	"планобмена": "ExchangePlan", "exchangeplan": "ExchangePlan",
+	"бизнеспроцесс": "BusinessProcess", "businessprocess": "BusinessProcess",
+	"задача": "Task", "task": "Task",
+	"константа": "Constant", "constant": "Constant",
+	"журналдокументов": "DocumentJournal", "documentjournal": "DocumentJournal",
+}
+
+// Refs находит в тексте модуля обращения к объектам конфигурации, по одному на метод, вид и объект
+// (у вызовов — на метод, объект и вызываемый метод):
+//   - Имя.Метод(…) — возможный вызов общего модуля (RefCall с Type CommonModule, Callee — Метод);
+//   - Справочники.Имя, Документы.Имя и другие менеджеры, кроме Метаданные.Справочники.Имя (RefManager);
+//   - Справочник.Имя, РегистрНакопления.Имя.Остатки и другие таблицы в строковых литералах метода, в котором есть
+//     текст запроса — литерал со словом ВЫБРАТЬ или SELECT (RefQuery).
+//
+// Комментарии пропускаются, идентификаторы внутри литералов вызовами и менеджерами не считаются.
+func Refs(source string) []Ref {
+	methods := Parse(source)
+	type key struct{ method, kind, typ, name, callee string }
+	seen := make(map[key]bool)
+	var out []Ref
+	add := func(r Ref) {
+		k := key{r.Method, r.Kind, r.Type, strings.ToLower(r.Name), strings.ToLower(r.Callee)}
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, r)
+		}
+	}
+	// Литералы копятся по методу: таблицы запроса засчитываются, только если в методе есть текст запроса.
+	type literal struct {
+		text string
+		line int
+	}
+	var (
+		literals []literal
+		isQuery  bool
+		current  = -1 // индекс метода в methods, к которому относятся literals
+	)
+	flush := func() {
+		if isQuery {
+			for _, l := range literals {
+				for _, chain := range chains(l.text) {
+					if t, ok := tables[strings.ToLower(chain.parts[0])]; ok && len(chain.parts) > 1 {
+						add(Ref{Method: methodName(methods, current), Kind: RefQuery, Type: t, Name: chain.parts[1], Line: l.line})
+					}
+				}
+			}
+		}
+		literals, isQuery = nil, false
+	}
+
+	lines := Lines(source)
+	owner := methodOwners(methods, len(lines))
+	inString := false
+	for i, line := range lines {
+		m := owner[i]
+		if m != current {
+			flush()
+			current = m
+		}
+		code, lits, next := maskLine(line, inString)
+		inString = next
+		for _, text := range lits {
+			literals = append(literals, literal{text, i + 1})
+			if !isQuery {
+				for _, chain := range chains(text) {
+					w := chain.parts[0]
+					if len(chain.parts) == 1 && (strings.EqualFold(w, "ВЫБРАТЬ") || strings.EqualFold(w, "SELECT")) {
+						isQuery = true
+						break
+					}
+				}
+			}
+		}
+		for _, chain := range chains(code) {
+			if chain.dotted || len(chain.parts) < 2 {
+				continue
+			}
+			if t, ok := managers[strings.ToLower(chain.parts[0])]; ok {
+				add(Ref{Method: methodName(methods, m), Kind: RefManager, Type: t, Name: chain.parts[1], Line: i + 1})
+				continue
+			}
+			if len(chain.parts) == 2 && chain.call {
+				add(Ref{Method: methodName(methods, m), Kind: RefCall, Type: "CommonModule", Name: chain.parts[0], Callee: chain.parts[1], Line: i + 1})
+			}
+		}
+	}
+	flush()
+	return out
+}
+
+// methodOwners возвращает для каждой из n строк (с 0) индекс метода, которому она принадлежит, или -1.
+// Метод без конца продолжается до следующего объявления.
+func methodOwners(methods []Method, n int) []int {
+	owner := make([]int, n)
+	for i := range owner {
+		owner[i] = -1
+	}
+	for i, m := range methods {
+		end := m.EndLine
+		if end == 0 {
+			end = n
+			if i+1 < len(methods) {
+				end = methods[i+1].Line - 1
+			}
+		}
+		for l := m.Line; l <= end && l <= n; l++ {
+			owner[l-1] = i
+		}
+	}
+	return owner
+}
+
+func methodName(methods []Method, i int) string {
+	if i < 0 {
+		return ""
+	}
+	return methods[i].Name
+}
+
+// maskLine отделяет код строки от литералов и комментария: в code литералы заменены пробелами, lits — их содержимое.
+// inString — строка начинается внутри многострочного литерала; возвращается то же для следующей строки.
+func maskLine(line string, inString bool) (code string, lits []string, stillInString bool) {
+	var b, lit strings.Builder
+	i := 0
+	if inString {
+		t := strings.TrimLeft(line, " \t")
+		switch {
+		case strings.HasPrefix(t, "|"):
+			i = len(line) - len(t) + 1
+		case strings.HasPrefix(t, "//"):
+			return "", nil, true
+		default:
+			inString = false
+		}
+	}
+	for ; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString:
+			if c == '"' {
+				if i+1 < len(line) && line[i+1] == '"' {
+					lit.WriteByte('"')
+					i++
+					continue
+				}
+				inString = false
+				lits = append(lits, lit.String())
+				lit.Reset()
+				b.WriteByte('"')
+				continue
+			}
+			lit.WriteByte(c)
+			b.WriteByte(' ')
+		case c == '"':
+			inString = true
+			b.WriteByte('"')
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return b.String(), lits, false
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if inString && lit.Len() > 0 {
+		lits = append(lits, lit.String())
+	}
+	return b.String(), lits, inString
+}
+
+// chain — цепочка идентификаторов через точку: Справочники.Контрагенты.СоздатьЭлемент. dotted — перед цепочкой
+// стоит точка (она продолжает выражение, например Метаданные.Справочники), call — после неё открывается скобка.
+type chain struct {
+	parts  []string
+	dotted bool
+	call   bool
+}
+
+// chains находит цепочки идентификаторов в тексте; пробелы вокруг точек не допускаются.
+func chains(text string) []chain {
+	r := []rune(text)
+	var out []chain
+	isIdent := func(c rune) bool { return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' }
+	for i := 0; i < len(r); {
+		if !isIdent(r[i]) || unicode.IsDigit(r[i]) {
+			i++
+			continue
+		}
+		var c chain
+		c.dotted = i > 0 && r[i-1] == '.'
+		for {
+			start := i
+			for i < len(r) && isIdent(r[i]) {
+				i++
+			}
+			c.parts = append(c.parts, string(r[start:i]))
+			if i+1 < len(r) && r[i] == '.' && isIdent(r[i+1]) && !unicode.IsDigit(r[i+1]) {
+				i++
+				continue
+			}
+			break
+		}
+		j := i
+		for j < len(r) && (r[j] == ' ' || r[j] == '\t') {
+			j++
+		}
+		c.call = j < len(r) && r[j] == '('
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package bsl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefs(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []Ref
+	}{
+		{
+			name: "вызов общего модуля и менеджер",
+			source: `Процедура ПриЗаписи()
+	ОбщегоНазначения.СообщитьПользователю("Записано");
+	Элемент = Справочники.Контрагенты.СоздатьЭлемент();
+	Элемент2 = Справочники.Контрагенты.СоздатьЭлемент();
+КонецПроцедуры`,
+			want: []Ref{
+				{Method: "ПриЗаписи", Kind: RefCall, Type: "CommonModule", Name: "ОбщегоНазначения", Callee: "СообщитьПользователю", Line: 2},
+				{Method: "ПриЗаписи", Kind: RefManager, Type: "Catalog", Name: "Контрагенты", Line: 3},
+			},
+		},
+		{
+			name: "вызовы разных методов одного модуля",
+			source: `Процедура ПередЗаписью(Отказ)
+	ОбщийМодульКлиент.ПроверитьКонтрагента(Контрагент);
+	ОбщийМодульКлиент.СообщитьПользователю("Проверено");
+	общиймодульклиент.проверитьконтрагента(Контрагент);
+КонецПроцедуры`,
+			want: []Ref{
+				{Method: "ПередЗаписью", Kind: RefCall, Type: "CommonModule", Name: "ОбщийМодульКлиент", Callee: "ПроверитьКонтрагента", Line: 2},
+				{Method: "ПередЗаписью", Kind: RefCall, Type: "CommonModule", Name: "ОбщийМодульКлиент", Callee: "СообщитьПользователю", Line: 3},
+			},
+		},
+		{
+			name: "Метаданные.Справочники не обращение к менеджеру",
+			source: `Функция Имя()
+	Возврат Метаданные.Справочники.Контрагенты.Имя;
+КонецФункции`,
+			want: nil,
+		},
+		{
+			name: "комментарии и литералы пропускаются",
+			source: `Процедура П()
+	// Справочники.Номенклатура.НайтиПоКоду(1);
+	Текст = "Документы.Заказ ОбщийМодуль.Метод()";
+КонецПроцедуры`,
+			want: nil,
+		},
+		{
+			name: "таблицы запроса со строкой //",
+			source: `Функция Остатки()
+	Запрос = Новый Запрос;
+	Запрос.Текст = "ВЫБРАТЬ
+	|	Т.Ссылка
+	//|	ИЗ Справочник.Закомментирован
+	|ИЗ
+	|	РегистрНакопления.ВзаиморасчетыСКонтрагентами.Остатки КАК Т";
+	Возврат Запрос.Выполнить();
+КонецФункции`,
+			// Запрос.Выполнить() похоже на вызов общего модуля: переменные отсеивает вызывающий. Таблицы запроса
+			// добавляются в конце метода.
+			want: []Ref{
+				{Method: "Остатки", Kind: RefCall, Type: "CommonModule", Name: "Запрос", Callee: "Выполнить", Line: 8},
+				{Method: "Остатки", Kind: RefQuery, Type: "AccumulationRegister", Name: "ВзаиморасчетыСКонтрагентами", Line: 7},
+			},
+		},
+		{
+			name: "таблица без текста запроса не считается",
+			source: `Процедура П()
+	Сообщить("Справочник.Контрагенты");
+КонецПроцедуры`,
+			want: nil,
+		},
+		{
+			name: "обращения вне методов",
+			source: `Перем Кэш;
+Кэш = Перечисления.ВидыДоговоров.СПоставщиком;`,
+			want: []Ref{
+				{Kind: RefManager, Type: "Enum", Name: "ВидыДоговоров", Line: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Refs(tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Refs:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,20 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
+)
+
+// Реализации хранилища, между которыми выбирает Backend.
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// Режимы миграций схемы при открытии базы (MigrateMode, флаг -migrate).
+const (
+	MigrateApply = "apply" // применить неприменённые встроенные миграции
+	MigrateCheck = "check" // только проверить версию схемы, ничего не меняя
 )
 
 func SnapshotDir() string {
@@ -29,6 +43,35 @@ func DatabaseURL() string {
 	return os.Getenv("POSTGRES_DSN")
 }
 
+// ConfigID возвращает идентификатор конфигурации по умолчанию из MCP_1C_STRUCTURE_CONFIG_ID (пусто, если не задан).
+func ConfigID() string {
+	return strings.TrimSpace(os.Getenv("MCP_1C_STRUCTURE_CONFIG_ID"))
+}
+
+// Backend возвращает выбранную реализацию хранилища: MCP_1C_STRUCTURE_BACKEND, если задана;
+// иначе по схеме URL базы (sqlite://... — sqlite, остальное — postgres) и memory (снимок из SnapshotDir в памяти) без URL.
+func Backend() string {
+	if b := strings.TrimSpace(os.Getenv("MCP_1C_STRUCTURE_BACKEND")); b != "" {
+		return strings.ToLower(b)
+	}
+	u := DatabaseURL()
+	if u == "" {
+		return BackendMemory
+	}
+	if strings.HasPrefix(strings.ToLower(u), "sqlite:") {
+		return BackendSQLite
+	}
+	return BackendPostgres
+}
+
+// MigrateMode возвращает режим миграций из MCP_1C_STRUCTURE_MIGRATE; по умолчанию MigrateApply.
+func MigrateMode() string {
+	if m := strings.TrimSpace(os.Getenv("MCP_1C_STRUCTURE_MIGRATE")); m != "" {
+		return strings.ToLower(m)
+	}
+	return MigrateApply
+}
+
 func dirExists(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && info.IsDir()
@@ -0,0 +1,352 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// Result — различия между двумя ревизиями снимка конфигурации.
+type Result struct {
+	AddedObjects     []ObjectRef         `json:"addedObjects"`
+	RemovedObjects   []ObjectRef         `json:"removedObjects"`
+	ChangedObjects   []ObjectChange      `json:"changedObjects"`
+	AddedRelations   []snapshot.Relation `json:"addedRelations"`
+	RemovedRelations []snapshot.Relation `json:"removedRelations"`
+}
+
+type ObjectRef struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// ObjectChange — изменения объекта, который есть в обеих ревизиях.
+type ObjectChange struct {
+	ObjectRef
+	Props           PropChanges     `json:"props"`
+	TabularSections []SectionChange `json:"tabularSections,omitempty"`
+	FormsAdded      []string        `json:"formsAdded,omitempty"`
+	FormsRemoved    []string        `json:"formsRemoved,omitempty"`
+	FormsChanged    []string        `json:"formsChanged,omitempty"` // формы, у которых изменились реквизиты, элементы, команды или обработчики
+	ModulesAdded    []string        `json:"modulesAdded,omitempty"`
+	ModulesRemoved  []string        `json:"modulesRemoved,omitempty"`
+	// Подсистемы: вложенные подсистемы и объекты состава, добавленные и исключённые.
+	SubsystemsAdded   []string `json:"subsystemsAdded,omitempty"`
+	SubsystemsRemoved []string `json:"subsystemsRemoved,omitempty"`
+	ContentAdded      []string `json:"contentAdded,omitempty"`
+	ContentRemoved    []string `json:"contentRemoved,omitempty"`
+	// Register — изменились свойства регистра (периодичность, режим записи, вид).
+	Register *RegisterChange `json:"register,omitempty"`
+}
+
+type PropChanges struct {
+	Added   []snapshot.Prop `json:"added,omitempty"`
+	Removed []snapshot.Prop `json:"removed,omitempty"`
+	Retyped []Retyped       `json:"retyped,omitempty"`
+}
+
+// Retyped — реквизит, у которого сменился тип или, у регистра, вид поля (измерение стало ресурсом и т. п.).
+type Retyped struct {
+	Name    string `json:"name"`
+	OldType string `json:"oldType"`
+	NewType string `json:"newType"`
+	OldKind string `json:"oldKind,omitempty"`
+	NewKind string `json:"newKind,omitempty"`
+}
+
+// RegisterChange — прежние и новые свойства регистра; nil — свойств не было.
+type RegisterChange struct {
+	Old *snapshot.Register `json:"old"`
+	New *snapshot.Register `json:"new"`
+}
+
+// Статусы табличной части в SectionChange.
+const (
+	SectionAdded   = "added"
+	SectionRemoved = "removed"
+	SectionChanged = "changed"
+)
+
+type SectionChange struct {
+	Name    string      `json:"name"`
+	Status  string      `json:"status"`
+	Columns PropChanges `json:"columns"`
+}
+
+func (c PropChanges) empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Retyped) == 0
+}
+
+func (c ObjectChange) empty() bool {
+	return c.Props.empty() && len(c.TabularSections) == 0 &&
+		len(c.FormsAdded) == 0 && len(c.FormsRemoved) == 0 && len(c.FormsChanged) == 0 &&
+		len(c.ModulesAdded) == 0 && len(c.ModulesRemoved) == 0 &&
+		len(c.SubsystemsAdded) == 0 && len(c.SubsystemsRemoved) == 0 &&
+		len(c.ContentAdded) == 0 && len(c.ContentRemoved) == 0 && c.Register == nil
+}
+
+// Compare сравнивает две ревизии: объекты сопоставляются по идентификатору, реквизиты и табличные части — по имени.
+func Compare(oldObjects []snapshot.Object, oldRelations []snapshot.Relation, newObjects []snapshot.Object, newRelations []snapshot.Relation) Result {
+	oldByID := indexObjects(oldObjects)
+	newByID := indexObjects(newObjects)
+	res := Result{
+		AddedObjects: []ObjectRef{}, RemovedObjects: []ObjectRef{}, ChangedObjects: []ObjectChange{},
+		AddedRelations: []snapshot.Relation{}, RemovedRelations: []snapshot.Relation{},
+	}
+	for id, n := range newByID {
+		o, ok := oldByID[id]
+		if !ok {
+			res.AddedObjects = append(res.AddedObjects, ref(n))
+			continue
+		}
+		if c := compareObject(o, n); !c.empty() {
+			res.ChangedObjects = append(res.ChangedObjects, c)
+		}
+	}
+	for id, o := range oldByID {
+		if _, ok := newByID[id]; !ok {
+			res.RemovedObjects = append(res.RemovedObjects, ref(o))
+		}
+	}
+	sort.Slice(res.AddedObjects, func(i, j int) bool { return res.AddedObjects[i].ID < res.AddedObjects[j].ID })
+	sort.Slice(res.RemovedObjects, func(i, j int) bool { return res.RemovedObjects[i].ID < res.RemovedObjects[j].ID })
+	sort.Slice(res.ChangedObjects, func(i, j int) bool { return res.ChangedObjects[i].ID < res.ChangedObjects[j].ID })
+
+	oldRels := relationSet(oldRelations)
+	newRels := relationSet(newRelations)
+	for k, r := range newRels {
+		if _, ok := oldRels[k]; !ok {
+			res.AddedRelations = append(res.AddedRelations, r)
+		}
+	}
+	for k, r := range oldRels {
+		if _, ok := newRels[k]; !ok {
+			res.RemovedRelations = append(res.RemovedRelations, r)
+		}
+	}
+	sortRelations(res.AddedRelations)
+	sortRelations(res.RemovedRelations)
+	return res
+}
+
+func compareObject(o, n snapshot.Object) ObjectChange {
+	c := ObjectChange{ObjectRef: ref(n), Props: compareProps(o.Props, n.Props)}
+	oldTS := make(map[string]snapshot.TabularSection)
+	for _, ts := range o.TabularSections {
+		oldTS[ts.Name] = ts
+	}
+	newTS := make(map[string]bool)
+	for _, ts := range n.TabularSections {
+		newTS[ts.Name] = true
+		prev, ok := oldTS[ts.Name]
+		if !ok {
+			c.TabularSections = append(c.TabularSections, SectionChange{Name: ts.Name, Status: SectionAdded, Columns: PropChanges{Added: ts.Props}})
+			continue
+		}
+		if cols := compareProps(prev.Props, ts.Props); !cols.empty() {
+			c.TabularSections = append(c.TabularSections, SectionChange{Name: ts.Name, Status: SectionChanged, Columns: cols})
+		}
+	}
+	for _, ts := range o.TabularSections {
+		if !newTS[ts.Name] {
+			c.TabularSections = append(c.TabularSections, SectionChange{Name: ts.Name, Status: SectionRemoved, Columns: PropChanges{Removed: ts.Props}})
+		}
+	}
+	c.FormsAdded, c.FormsRemoved = compareNames(snapshot.FormNames(o.Forms), snapshot.FormNames(n.Forms))
+	c.FormsChanged = changedForms(o.Forms, n.Forms)
+	c.ModulesAdded, c.ModulesRemoved = compareNames(snapshot.ModuleNames(o.Modules), snapshot.ModuleNames(n.Modules))
+	c.SubsystemsAdded, c.SubsystemsRemoved = compareNames(o.Subsystems, n.Subsystems)
+	c.ContentAdded, c.ContentRemoved = compareNames(o.Content, n.Content)
+	if !sameRegister(o.Register, n.Register) {
+		c.Register = &RegisterChange{Old: o.Register, New: n.Register}
+	}
+	return c
+}
+
+func compareProps(oldProps, newProps []snapshot.Prop) PropChanges {
+	var c PropChanges
+	oldByName := make(map[string]snapshot.Prop)
+	for _, p := range oldProps {
+		oldByName[p.Name] = p
+	}
+	newNames := make(map[string]bool)
+	for _, p := range newProps {
+		newNames[p.Name] = true
+		prev, ok := oldByName[p.Name]
+		if !ok {
+			c.Added = append(c.Added, p)
+			continue
+		}
+		if prev.Type != p.Type || prev.Kind != p.Kind {
+			c.Retyped = append(c.Retyped, Retyped{Name: p.Name, OldType: prev.Type, NewType: p.Type, OldKind: prev.Kind, NewKind: p.Kind})
+		}
+	}
+	for _, p := range oldProps {
+		if !newNames[p.Name] {
+			c.Removed = append(c.Removed, p)
+		}
+	}
+	return c
+}
+
+// changedForms возвращает имена форм, которые есть в обеих ревизиях, но отличаются содержимым.
+// Формы сравниваются по JSON: пустой список и отсутствующий в снимке считаются одинаковыми.
+func changedForms(oldForms, newForms []snapshot.Form) []string {
+	oldByName := make(map[string][]byte)
+	for _, f := range oldForms {
+		oldByName[f.Name], _ = json.Marshal(f)
+	}
+	var out []string
+	for _, f := range newForms {
+		prev, ok := oldByName[f.Name]
+		if !ok {
+			continue
+		}
+		if cur, _ := json.Marshal(f); !bytes.Equal(prev, cur) {
+			out = append(out, f.Name)
+		}
+	}
+	return out
+}
+
+func sameRegister(a, b *snapshot.Register) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func compareNames(oldNames, newNames []string) (added, removed []string) {
+	oldSet := make(map[string]bool)
+	for _, n := range oldNames {
+		oldSet[n] = true
+	}
+	newSet := make(map[string]bool)
+	for _, n := range newNames {
+		newSet[n] = true
+		if !oldSet[n] {
+			added = append(added, n)
+		}
+	}
+	for _, n := range oldNames {
+		if !newSet[n] {
+			removed = append(removed, n)
+		}
+	}
+	return added, removed
+}
+
+func indexObjects(objects []snapshot.Object) map[string]snapshot.Object {
+	out := make(map[string]snapshot.Object, len(objects))
+	for _, o := range objects {
+		out[store.NormalizeID(o.ID)] = o
+	}
+	return out
+}
+
+// relationSet индексирует связи по нормализованным концам, сохраняя исходное написание для вывода.
+func relationSet(relations []snapshot.Relation) map[snapshot.Relation]snapshot.Relation {
+	out := make(map[snapshot.Relation]snapshot.Relation, len(relations))
+	for _, r := range relations {
+		out[snapshot.Relation{From: store.NormalizeID(r.From), To: store.NormalizeID(r.To), Kind: r.Kind, Prop: r.Prop}] = r
+	}
+	return out
+}
+
+func sortRelations(list []snapshot.Relation) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].From != list[j].From {
+			return list[i].From < list[j].From
+		}
+		if list[i].To != list[j].To {
+			return list[i].To < list[j].To
+		}
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Prop < list[j].Prop
+	})
+}
+
+func ref(o snapshot.Object) ObjectRef {
+	return ObjectRef{ID: o.ID, Type: o.Type, Name: o.Name}
+}
+
+// Selection задаёт сравниваемые ревизии: номером или версией конфигурации (ConfigVersion).
+// Без указания To берётся последняя ревизия, без указания From — предыдущая перед To.
+type Selection struct {
+	FromRevision int
+	ToRevision   int
+	FromVersion  string
+	ToVersion    string
+}
+
+// Report — результат сравнения вместе с описанием сравниваемых ревизий.
+type Report struct {
+	From   store.Revision `json:"from"`
+	To     store.Revision `json:"to"`
+	Result Result         `json:"diff"`
+}
+
+// Between загружает две ревизии конфигурации из хранилища и сравнивает их.
+func Between(ctx context.Context, s store.Store, configID string, sel Selection) (Report, error) {
+	revs, err := s.ListRevisions(ctx, configID)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(revs) == 0 {
+		return Report{}, fmt.Errorf("configuration %q has no revisions", configID)
+	}
+	to, err := pick(revs, sel.ToRevision, sel.ToVersion, revs[len(revs)-1].Number)
+	if err != nil {
+		return Report{}, err
+	}
+	from, err := pick(revs, sel.FromRevision, sel.FromVersion, to-1)
+	if err != nil {
+		return Report{}, err
+	}
+	if from < 1 {
+		return Report{}, fmt.Errorf("revision %d has no previous revision to compare with", to)
+	}
+	oldRev, ok, err := s.LoadRevision(ctx, configID, from)
+	if err != nil {
+		return Report{}, err
+	}
+	if !ok {
+		return Report{}, fmt.Errorf("revision %d not found", from)
+	}
+	newRev, ok, err := s.LoadRevision(ctx, configID, to)
+	if err != nil {
+		return Report{}, err
+	}
+	if !ok {
+		return Report{}, fmt.Errorf("revision %d not found", to)
+	}
+	return Report{
+		From:   oldRev.Revision,
+		To:     newRev.Revision,
+		Result: Compare(oldRev.Objects, oldRev.Relations, newRev.Objects, newRev.Relations),
+	}, nil
+}
+
+// pick возвращает номер ревизии: явный номер, затем последнюю ревизию с версией version, иначе def.
+func pick(revs []store.Revision, number int, version string, def int) (int, error) {
+	if number > 0 {
+		return number, nil
+	}
+	if version != "" {
+		for i := len(revs) - 1; i >= 0; i-- {
+			if revs[i].Meta.ConfigVersion == version {
+				return revs[i].Number, nil
+			}
+		}
+		return 0, fmt.Errorf("no revision with configVersion %q", version)
+	}
+	return def, nil
+}
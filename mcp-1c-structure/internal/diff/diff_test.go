@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+func TestCompare(t *testing.T) {
+	oldObjects := []snapshot.Object{
+		{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты",
+			Props: []snapshot.Prop{{Name: "ИНН", Type: "String"}, {Name: "КПП", Type: "String"}},
+			TabularSections: []snapshot.TabularSection{
+				{Name: "КонтактныеЛица", Props: []snapshot.Prop{{Name: "Телефон", Type: "String"}}},
+				{Name: "Счета", Props: []snapshot.Prop{{Name: "Номер", Type: "String"}}},
+			}},
+		{ID: "doc.Заказ", Type: "Document", Name: "Заказ"},
+		{ID: "cat.Удалённый", Type: "Catalog", Name: "Удалённый"},
+	}
+	newObjects := []snapshot.Object{
+		// id записан иначе: объекты сопоставляются по нормализованному id
+		{ID: "Catalog.Контрагенты", Type: "Catalog", Name: "Контрагенты",
+			Props: []snapshot.Prop{{Name: "ИНН", Type: "Number"}, {Name: "Адрес", Type: "String"}},
+			TabularSections: []snapshot.TabularSection{
+				{Name: "КонтактныеЛица", Props: []snapshot.Prop{{Name: "Телефон", Type: "String"}, {Name: "Email", Type: "String"}}},
+				{Name: "Банки", Props: []snapshot.Prop{{Name: "БИК", Type: "String"}}},
+			}},
+		{ID: "doc.Заказ", Type: "Document", Name: "Заказ"},
+		{ID: "cat.Новый", Type: "Catalog", Name: "Новый"},
+	}
+	oldRelations := []snapshot.Relation{
+		{From: "doc.Заказ", To: "cat.Контрагенты", Kind: "reference"},
+		{From: "doc.Заказ", To: "cat.Удалённый", Kind: "reference"},
+	}
+	newRelations := []snapshot.Relation{
+		{From: "Document.Заказ", To: "Catalog.Контрагенты", Kind: "reference"},
+		{From: "doc.Заказ", To: "cat.Новый", Kind: "reference"},
+	}
+
+	got := Compare(oldObjects, oldRelations, newObjects, newRelations)
+	want := Result{
+		AddedObjects:   []ObjectRef{{ID: "cat.Новый", Type: "Catalog", Name: "Новый"}},
+		RemovedObjects: []ObjectRef{{ID: "cat.Удалённый", Type: "Catalog", Name: "Удалённый"}},
+		ChangedObjects: []ObjectChange{{
+			ObjectRef: ObjectRef{ID: "Catalog.Контрагенты", Type: "Catalog", Name: "Контрагенты"},
+			Props: PropChanges{
+				Added:   []snapshot.Prop{{Name: "Адрес", Type: "String"}},
+				Removed: []snapshot.Prop{{Name: "КПП", Type: "String"}},
+				Retyped: []Retyped{{Name: "ИНН", OldType: "String", NewType: "Number"}},
+			},
+			TabularSections: []SectionChange{
+				{Name: "КонтактныеЛица", Status: SectionChanged, Columns: PropChanges{Added: []snapshot.Prop{{Name: "Email", Type: "String"}}}},
+				{Name: "Банки", Status: SectionAdded, Columns: PropChanges{Added: []snapshot.Prop{{Name: "БИК", Type: "String"}}}},
+				{Name: "Счета", Status: SectionRemoved, Columns: PropChanges{Removed: []snapshot.Prop{{Name: "Номер", Type: "String"}}}},
+			},
+		}},
+		AddedRelations:   []snapshot.Relation{{From: "doc.Заказ", To: "cat.Новый", Kind: "reference"}},
+		RemovedRelations: []snapshot.Relation{{From: "doc.Заказ", To: "cat.Удалённый", Kind: "reference"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare:\n got %+v\nwant %+v", got, want)
+	}
+
+	if same := Compare(oldObjects, oldRelations, oldObjects, oldRelations); len(same.AddedObjects)+len(same.RemovedObjects)+len(same.ChangedObjects)+len(same.AddedRelations)+len(same.RemovedRelations) != 0 {
+		t.Errorf("Compare of a revision with itself: want no changes, got %+v", same)
+	}
+}
+
+func TestPick(t *testing.T) {
+	revs := []store.Revision{
+		{Number: 1, Meta: snapshot.Meta{ConfigVersion: "1.0"}},
+		{Number: 2, Meta: snapshot.Meta{ConfigVersion: "1.1"}},
+		{Number: 3, Meta: snapshot.Meta{ConfigVersion: "1.1"}},
+	}
+	tests := []struct {
+		name    string
+		number  int
+		version string
+		want    int
+		wantErr bool
+	}{
+		{name: "по умолчанию", want: 3},
+		{name: "номер важнее версии", number: 1, version: "1.1", want: 1},
+		{name: "последняя ревизия с версией", version: "1.1", want: 3},
+		{name: "версия", version: "1.0", want: 1},
+		{name: "неизвестная версия", version: "2.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pick(revs, tt.number, tt.version, 3)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("pick(%d, %q) = %d, %v; want %d, error %v", tt.number, tt.version, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// У регистров сравниваются и вид поля (измерение, ресурс, реквизит), и свойства регистра.
+func TestCompareRegister(t *testing.T) {
+	old := []snapshot.Object{{ID: "informationregister.Курсы", Type: "InformationRegister", Name: "Курсы",
+		Props:    []snapshot.Prop{{Name: "Валюта", Type: "CatalogRef.Валюты", Kind: snapshot.PropDimension}, {Name: "Курс", Type: "Number", Kind: snapshot.PropResource}},
+		Register: &snapshot.Register{Periodicity: "Day", WriteMode: "Independent"}}}
+	cur := []snapshot.Object{{ID: "informationregister.Курсы", Type: "InformationRegister", Name: "Курсы",
+		Props:    []snapshot.Prop{{Name: "Валюта", Type: "CatalogRef.Валюты", Kind: snapshot.PropDimension}, {Name: "Курс", Type: "Number", Kind: snapshot.PropAttribute}},
+		Register: &snapshot.Register{Periodicity: "Month", WriteMode: "Independent"}}}
+	res := Compare(old, nil, cur, nil)
+	if len(res.ChangedObjects) != 1 {
+		t.Fatalf("want one changed object, got %+v", res)
+	}
+	c := res.ChangedObjects[0]
+	want := []Retyped{{Name: "Курс", OldType: "Number", NewType: "Number", OldKind: snapshot.PropResource, NewKind: snapshot.PropAttribute}}
+	if !reflect.DeepEqual(c.Props.Retyped, want) {
+		t.Errorf("retyped: got %+v, want %+v", c.Props.Retyped, want)
+	}
+	if c.Register == nil || c.Register.Old.Periodicity != "Day" || c.Register.New.Periodicity != "Month" {
+		t.Errorf("register: got %+v", c.Register)
+	}
+	if res := Compare(old, nil, old, nil); len(res.ChangedObjects) != 0 {
+		t.Errorf("same register: want no changes, got %+v", res.ChangedObjects)
+	}
+}
+
+func TestCompareForms(t *testing.T) {
+	detailed := snapshot.Form{Name: "ФормаЭлемента", Elements: []snapshot.FormElement{{Name: "ИНН", Type: "InputField", DataPath: "Объект.ИНН"}}}
+	old := []snapshot.Object{{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты",
+		Forms: []snapshot.Form{{Name: "ФормаЭлемента"}, {Name: "ФормаСписка"}, {Name: "ФормаВыбора"}}}}
+	cur := []snapshot.Object{{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты",
+		Forms: []snapshot.Form{detailed, {Name: "ФормаСписка"}, {Name: "ФормаГруппы"}}}}
+	res := Compare(old, nil, cur, nil)
+	if len(res.ChangedObjects) != 1 {
+		t.Fatalf("want one changed object, got %+v", res)
+	}
+	c := res.ChangedObjects[0]
+	if !reflect.DeepEqual(c.FormsAdded, []string{"ФормаГруппы"}) || !reflect.DeepEqual(c.FormsRemoved, []string{"ФормаВыбора"}) ||
+		!reflect.DeepEqual(c.FormsChanged, []string{"ФормаЭлемента"}) {
+		t.Errorf("forms: added %v, removed %v, changed %v", c.FormsAdded, c.FormsRemoved, c.FormsChanged)
+	}
+	if res := Compare(cur, nil, cur, nil); len(res.ChangedObjects) != 0 {
+		t.Errorf("same forms: want no changes, got %+v", res.ChangedObjects)
+	}
+}
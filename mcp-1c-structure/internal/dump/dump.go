@@ -0,0 +1,356 @@
+// Package dump читает выгрузку конфигурации в файлы (конфигуратор «Выгрузить конфигурацию в файлы»,
+// DumpConfigToFiles, иерархический формат) и отдаёт её как снимок (snapshot.Source): объекты с синонимами,
+// реквизитами, табличными частями, формами и текстами модулей, связи register_record из движений документов.
+// Связи reference и связи из кода модулей выводит Import, как для любого снимка. Используется подкомандой indexer dump.
+package dump
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// SourceName — meta.source снимка, собранного из выгрузки.
+const SourceName = "DumpConfigToFiles"
+
+// metadataDirs — каталог выгрузки для типа метаданных. Объекты прочих типов (роли, языки, стили, общие формы, …)
+// в снимок не попадают.
+var metadataDirs = map[string]string{
+	"Subsystem":                  "Subsystems",
+	"CommonModule":               "CommonModules",
+	"Constant":                   "Constants",
+	"Catalog":                    "Catalogs",
+	"Document":                   "Documents",
+	"DocumentJournal":            "DocumentJournals",
+	"Enum":                       "Enums",
+	"Report":                     "Reports",
+	"DataProcessor":              "DataProcessors",
+	"ChartOfCharacteristicTypes": "ChartsOfCharacteristicTypes",
+	"ChartOfAccounts":            "ChartsOfAccounts",
+	"ChartOfCalculationTypes":    "ChartsOfCalculationTypes",
+	"InformationRegister":        "InformationRegisters",
+	"AccumulationRegister":       "AccumulationRegisters",
+	"AccountingRegister":         "AccountingRegisters",
+	"CalculationRegister":        "CalculationRegisters",
+	"BusinessProcess":            "BusinessProcesses",
+	"Task":                       "Tasks",
+	"ExchangePlan":               "ExchangePlans",
+}
+
+// moduleOrder — порядок модулей объекта; модули вне списка идут за ними по имени, модули форм — последними.
+var moduleOrder = []string{"ObjectModule", "ManagerModule", "RecordSetModule", "ValueManagerModule", "Module", "CommandModule"}
+
+// Source — выгрузка как snapshot.Source. Configuration.xml читается в Open, файлы объектов — по одному в Objects;
+// связи register_record копятся по ходу чтения объектов, поэтому Relations вызывается после Objects, как в Import.
+type Source struct {
+	dir       string
+	meta      snapshot.Meta
+	items     []item // объекты из Configuration.xml в порядке описания
+	relations []snapshot.Relation
+	warnings  []string
+	count     int
+}
+
+type item struct{ typ, name string }
+
+// Open читает Configuration.xml каталога выгрузки dir: имя, синоним и версию конфигурации и состав объектов.
+func Open(dir string) (*Source, error) {
+	dir = filepath.Clean(dir)
+	path := filepath.Join(dir, "Configuration.xml")
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f configFile
+	if err := xml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("Configuration.xml: %w", err)
+	}
+	p := f.Configuration.Properties
+	if p.Name == "" {
+		return nil, errors.New("Configuration.xml: no configuration name, not a DumpConfigToFiles export")
+	}
+	s := &Source{dir: dir, meta: snapshot.Meta{
+		Version:       "1.0",
+		ConfigName:    firstNonEmpty(p.Synonym.text(), p.Name),
+		ConfigVersion: p.Version,
+		ExportedAt:    info.ModTime().UTC().Format(time.RFC3339),
+		Source:        SourceName,
+		IndexVersion:  snapshot.IndexVersion,
+	}}
+	for _, c := range f.Configuration.Children.Items {
+		if _, ok := metadataDirs[c.XMLName.Local]; ok {
+			s.items = append(s.items, item{c.XMLName.Local, strings.TrimSpace(c.Name)})
+		}
+	}
+	return s, nil
+}
+
+// Warnings возвращает замечания чтения: объекты из Configuration.xml без файла (частичная выгрузка) пропускаются.
+func (s *Source) Warnings() []string {
+	return s.warnings
+}
+
+func (s *Source) Objects(yield func(snapshot.Object) error) error {
+	for _, it := range s.items {
+		base := filepath.Join(s.dir, metadataDirs[it.typ], it.name)
+		if err := s.readObject(it.typ, base, "", yield); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Source) Relations(yield func(snapshot.Relation) error) error {
+	for _, r := range s.relations {
+		if err := yield(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Meta возвращает meta снимка; objectCount — число объектов, прочитанных Objects.
+func (s *Source) Meta() (snapshot.Meta, error) {
+	m := s.meta
+	m.ObjectCount = s.count
+	return m, nil
+}
+
+// readObject читает объект типа typ из base.xml (Ext и Forms лежат в каталоге base) и отдаёт его в yield;
+// у подсистемы затем читаются вложенные подсистемы из base/Subsystems. parent — id родительской подсистемы
+// у вложенной: имя подсистемы уникально только среди соседей, поэтому её id — путь от корня (subsystemID).
+func (s *Source) readObject(typ, base, parent string, yield func(snapshot.Object) error) error {
+	rel := s.rel(base + ".xml")
+	data, err := os.ReadFile(base + ".xml")
+	if errors.Is(err, fs.ErrNotExist) {
+		s.warnings = append(s.warnings, rel+": file not found, object skipped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f mdFile
+	if err := xml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("%s: %w", rel, err)
+	}
+	if len(f.Objects) == 0 || f.Objects[0].XMLName.Local != typ {
+		return fmt.Errorf("%s: no %s description", rel, typ)
+	}
+	md := &f.Objects[0]
+	p := &md.Properties
+	o := snapshot.Object{
+		ID:              store.NormalizeID(typ + "." + p.Name),
+		Type:            typ,
+		Name:            p.Name,
+		Synonym:         p.Synonym.text(),
+		Props:           standardProps(typ, p),
+		TabularSections: []snapshot.TabularSection{},
+		Forms:           []snapshot.Form{},
+		Description:     firstNonEmpty(strings.TrimSpace(p.Comment), p.Explanation.text()),
+	}
+	if parent != "" {
+		o.ID = subsystemID(parent, p.Name)
+	}
+	if store.IsRegister(typ) {
+		o.Props = append(o.Props, props(md.Children.Dimensions, snapshot.PropDimension)...)
+		o.Props = append(o.Props, props(md.Children.Resources, snapshot.PropResource)...)
+		o.Props = append(o.Props, props(md.Children.Attributes, snapshot.PropAttribute)...)
+		o.Register = register(p)
+	} else {
+		o.Props = append(o.Props, props(md.Children.Attributes, "")...)
+	}
+	for i := range md.Children.TabularSections {
+		ts := &md.Children.TabularSections[i]
+		o.TabularSections = append(o.TabularSections, snapshot.TabularSection{
+			Name: ts.Properties.Name, Props: props(ts.Children.Attributes, ""),
+		})
+	}
+	for _, r := range p.RegisterRecords {
+		s.relations = append(s.relations, snapshot.Relation{From: o.ID, To: store.NormalizeID(strings.TrimSpace(r)), Kind: store.KindRegisterRecord})
+	}
+	if typ == store.TypeSubsystem {
+		o.Subsystems = []string{}
+		for _, name := range md.Children.Subsystems {
+			o.Subsystems = append(o.Subsystems, subsystemID(o.ID, strings.TrimSpace(name)))
+		}
+		o.Content = contentIDs(p.Content)
+	}
+	if o.Modules, err = s.modules(base); err != nil {
+		return err
+	}
+	for _, name := range md.Children.Forms {
+		form, module, err := s.form(filepath.Join(base, "Forms", strings.TrimSpace(name)))
+		if err != nil {
+			return err
+		}
+		o.Forms = append(o.Forms, form)
+		if module != nil {
+			o.Modules = append(o.Modules, *module)
+		}
+	}
+	s.count++
+	if err := yield(o); err != nil {
+		return err
+	}
+	for _, name := range md.Children.Subsystems {
+		if err := s.readObject(typ, filepath.Join(base, "Subsystems", strings.TrimSpace(name)), o.ID, yield); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subsystemID возвращает id вложенной подсистемы: id родителя и её имя через точку (subsystem.Продажи.ОптовыеПродажи).
+func subsystemID(parent, name string) string {
+	return parent + "." + name
+}
+
+// standardProps — стандартные реквизиты, которых нет в ChildObjects: Код и Наименование (если их длина не 0),
+// Номер и Дата документов, бизнес-процессов и задач, Владелец подчинённого справочника.
+func standardProps(typ string, p *mdProperties) []snapshot.Prop {
+	out := []snapshot.Prop{}
+	if p.CodeLength > 0 {
+		out = append(out, snapshot.Prop{Name: "Код", Type: firstNonEmpty(p.CodeType, "String"), Synonym: "Код"})
+	}
+	if p.DescriptionLength > 0 {
+		out = append(out, snapshot.Prop{Name: "Наименование", Type: "String", Synonym: "Наименование"})
+	}
+	if p.NumberLength > 0 {
+		out = append(out, snapshot.Prop{Name: "Номер", Type: firstNonEmpty(p.NumberType, "String"), Synonym: "Номер"})
+	}
+	if typ == "Document" || typ == "BusinessProcess" || typ == "Task" {
+		out = append(out, snapshot.Prop{Name: "Дата", Type: "DateTime", Synonym: "Дата"})
+	}
+	var owners []string
+	for _, ref := range p.Owners {
+		if t, name, ok := strings.Cut(strings.TrimSpace(ref), "."); ok {
+			owners = append(owners, t+"Ref."+name)
+		}
+	}
+	if len(owners) > 0 {
+		out = append(out, snapshot.Prop{Name: "Владелец", Type: strings.Join(owners, ", "), Synonym: "Владелец"})
+	}
+	return out
+}
+
+// props переводит реквизиты (измерения, ресурсы) выгрузки в Prop с видом поля kind.
+func props(list []mdObject, kind string) []snapshot.Prop {
+	out := []snapshot.Prop{}
+	for i := range list {
+		p := &list[i].Properties
+		out = append(out, snapshot.Prop{Name: p.Name, Type: p.Type.String(), Synonym: p.Synonym.text(), Kind: kind})
+	}
+	return out
+}
+
+// register возвращает свойства регистра или nil, если ни одно не задано.
+func register(p *mdProperties) *snapshot.Register {
+	r := snapshot.Register{
+		Periodicity:    p.Periodicity,
+		WriteMode:      p.WriteMode,
+		RegisterType:   p.RegisterType,
+		Correspondence: p.Correspondence,
+	}
+	if c := strings.TrimSpace(p.ChartOfAccounts); c != "" {
+		r.ChartOfAccounts = store.NormalizeID(c)
+	}
+	if r == (snapshot.Register{}) {
+		return nil
+	}
+	return &r
+}
+
+// contentIDs переводит состав подсистемы (Catalog.Контрагенты, …) в id объектов; подчинённые объекты
+// (Catalog.X.Command.Y) и объекты типов, которых нет в снимке, пропускаются.
+func contentIDs(items []string) []string {
+	out := []string{}
+	for _, ref := range items {
+		parts := strings.Split(strings.TrimSpace(ref), ".")
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		if _, ok := metadataDirs[parts[0]]; ok {
+			out = append(out, store.NormalizeID(parts[0]+"."+parts[1]))
+		}
+	}
+	return out
+}
+
+// modules читает тексты модулей объекта из base/Ext/*.bsl; path модуля — путь файла относительно выгрузки.
+func (s *Source) modules(base string) ([]snapshot.Module, error) {
+	entries, err := os.ReadDir(filepath.Join(base, "Ext"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []snapshot.Module{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := []snapshot.Module{}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".bsl")
+		if e.IsDir() || !ok {
+			continue
+		}
+		m, err := s.module(name, filepath.Join(base, "Ext", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, rj := moduleRank(out[i].Name), moduleRank(out[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func moduleRank(name string) int {
+	for i, n := range moduleOrder {
+		if n == name {
+			return i
+		}
+	}
+	return len(moduleOrder)
+}
+
+// module читает файл модуля; BOM выгрузки отбрасывается.
+func (s *Source) module(name, path string) (snapshot.Module, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot.Module{}, err
+	}
+	return snapshot.Module{Name: name, Path: s.rel(path), Source: strings.TrimPrefix(string(data), "\uFEFF")}, nil
+}
+
+// rel — путь внутри выгрузки через «/», для сообщений и Module.Path.
+func (s *Source) rel(path string) string {
+	if r, err := filepath.Rel(s.dir, path); err == nil {
+		path = r
+	}
+	return filepath.ToSlash(path)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package dump
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+const testNS = `xmlns="http://v8.1c.ru/8.3/MDClasses" xmlns:v8="http://v8.1c.ru/8.1/data/core" xmlns:xr="http://v8.1c.ru/8.3/xcf/readable" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`
+
+func writeXML(t *testing.T, dir, rel, body string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `<?xml version="1.0" encoding="UTF-8"?><MetaDataObject ` + testNS + `>` + body + `</MetaDataObject>`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func subsystemXML(name string, children ...string) string {
+	out := `<Subsystem uuid="1"><Properties><Name>` + name + `</Name></Properties><ChildObjects>`
+	for _, c := range children {
+		out += `<Subsystem>` + c + `</Subsystem>`
+	}
+	return out + `</ChildObjects></Subsystem>`
+}
+
+// Имя подсистемы уникально только среди соседей: одноимённые вложенные подсистемы разных родителей
+// получают разные id — путь от корневой подсистемы.
+func TestNestedSubsystemIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeXML(t, dir, "Configuration.xml", `<Configuration uuid="0"><Properties><Name>Тест</Name></Properties>
+		<ChildObjects><Subsystem>Продажи</Subsystem><Subsystem>Закупки</Subsystem></ChildObjects></Configuration>`)
+	writeXML(t, dir, "Subsystems/Продажи.xml", subsystemXML("Продажи", "Настройки"))
+	writeXML(t, dir, "Subsystems/Продажи/Subsystems/Настройки.xml", subsystemXML("Настройки", "Печать"))
+	writeXML(t, dir, "Subsystems/Продажи/Subsystems/Настройки/Subsystems/Печать.xml", subsystemXML("Печать"))
+	writeXML(t, dir, "Subsystems/Закупки.xml", subsystemXML("Закупки", "Настройки"))
+	writeXML(t, dir, "Subsystems/Закупки/Subsystems/Настройки.xml", subsystemXML("Настройки"))
+
+	src, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][]string{}
+	err = src.Objects(func(o snapshot.Object) error {
+		if _, dup := got[o.ID]; dup {
+			t.Errorf("duplicate id %s", o.ID)
+		}
+		got[o.ID] = o.Subsystems
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"subsystem.Продажи":                  {"subsystem.Продажи.Настройки"},
+		"subsystem.Продажи.Настройки":        {"subsystem.Продажи.Настройки.Печать"},
+		"subsystem.Продажи.Настройки.Печать": {},
+		"subsystem.Закупки":                  {"subsystem.Закупки.Настройки"},
+		"subsystem.Закупки.Настройки":        {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subsystems:\n got %v\nwant %v", got, want)
+	}
+}
+
+func writeFile(t *testing.T, dir, rel, data string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(t *testing.T, src *Source) (map[string]snapshot.Object, []snapshot.Relation) {
+	t.Helper()
+	objects := map[string]snapshot.Object{}
+	if err := src.Objects(func(o snapshot.Object) error { objects[o.ID] = o; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	var relations []snapshot.Relation
+	if err := src.Relations(func(r snapshot.Relation) error { relations = append(relations, r); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	return objects, relations
+}
+
+func TestRegistersAndForms(t *testing.T) {
+	dir := t.TempDir()
+	writeXML(t, dir, "Configuration.xml", `<Configuration uuid="0"><Properties><Name>Тест</Name></Properties><ChildObjects>
+		<Document>Реализация</Document><AccumulationRegister>Взаиморасчеты</AccumulationRegister><Catalog>Отсутствующий</Catalog>
+		</ChildObjects></Configuration>`)
+	writeXML(t, dir, "Documents/Реализация.xml", `<Document uuid="1"><Properties><Name>Реализация</Name>
+		<Synonym><v8:item><v8:lang>ru</v8:lang><v8:content>Реализация товаров</v8:content></v8:item></Synonym>
+		<NumberLength>11</NumberLength><NumberType>String</NumberType>
+		<RegisterRecords><xr:Item xsi:type="xr:MDObjectRef">AccumulationRegister.Взаиморасчеты</xr:Item></RegisterRecords>
+		</Properties><ChildObjects>
+		<Attribute uuid="2"><Properties><Name>Контрагент</Name><Type><v8:Type>cfg:CatalogRef.Контрагенты</v8:Type></Type></Properties></Attribute>
+		<Form>ФормаДокумента</Form><Form>ФормаСписка</Form>
+		</ChildObjects></Document>`)
+	writeFile(t, dir, "Documents/Реализация/Ext/ObjectModule.bsl", "\uFEFFПроцедура ОбработкаПроведения(Отказ)\nКонецПроцедуры")
+	writeFile(t, dir, "Documents/Реализация/Forms/ФормаДокумента/Ext/Form.xml", `<?xml version="1.0" encoding="UTF-8"?>
+		<Form xmlns="http://v8.1c.ru/8.3/xcf/logform" xmlns:v8="http://v8.1c.ru/8.1/data/core">
+		<Events><Event name="OnCreateAtServer">ПриСозданииНаСервере</Event></Events>
+		<ChildItems><UsualGroup name="Шапка" id="1"><ChildItems>
+			<InputField name="Контрагент" id="2"><DataPath>Объект.Контрагент</DataPath>
+			<Events><Event name="OnChange">КонтрагентПриИзменении</Event></Events></InputField>
+		</ChildItems></UsualGroup></ChildItems>
+		<Attributes><Attribute name="Объект" id="1"><Type><v8:Type>cfg:DocumentObject.Реализация</v8:Type></Type><MainAttribute>true</MainAttribute></Attribute></Attributes>
+		<Commands><Command name="Заполнить" id="1"><Action>Заполнить</Action></Command></Commands>
+		</Form>`)
+	writeFile(t, dir, "Documents/Реализация/Forms/ФормаДокумента/Ext/Form/Module.bsl", "Процедура КонтрагентПриИзменении(Элемент)\nКонецПроцедуры")
+	writeXML(t, dir, "AccumulationRegisters/Взаиморасчеты.xml", `<AccumulationRegister uuid="3"><Properties><Name>Взаиморасчеты</Name>
+		<RegisterType>Balance</RegisterType></Properties><ChildObjects>
+		<Resource uuid="4"><Properties><Name>Сумма</Name><Type><v8:Type>xs:decimal</v8:Type></Type></Properties></Resource>
+		<Attribute uuid="5"><Properties><Name>Комментарий</Name><Type><v8:Type>xs:string</v8:Type></Type></Properties></Attribute>
+		<Dimension uuid="6"><Properties><Name>Контрагент</Name><Type><v8:Type>cfg:CatalogRef.Контрагенты</v8:Type></Type></Properties></Dimension>
+		</ChildObjects></AccumulationRegister>`)
+
+	src, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, relations := readAll(t, src)
+	if len(objects) != 2 || !reflect.DeepEqual(src.Warnings(), []string{"Catalogs/Отсутствующий.xml: file not found, object skipped"}) {
+		t.Fatalf("objects %d, warnings %v", len(objects), src.Warnings())
+	}
+	if m, _ := src.Meta(); m.ObjectCount != 2 || m.Source != SourceName || m.ConfigName != "Тест" {
+		t.Errorf("meta: %+v", m)
+	}
+
+	reg := objects["accumulationregister.Взаиморасчеты"]
+	wantProps := []snapshot.Prop{
+		{Name: "Контрагент", Type: "CatalogRef.Контрагенты", Kind: snapshot.PropDimension},
+		{Name: "Сумма", Type: "Number", Kind: snapshot.PropResource},
+		{Name: "Комментарий", Type: "String", Kind: snapshot.PropAttribute},
+	}
+	if !reflect.DeepEqual(reg.Props, wantProps) || reg.Register == nil || reg.Register.RegisterType != "Balance" {
+		t.Errorf("register: props %+v, register %+v", reg.Props, reg.Register)
+	}
+	wantRel := []snapshot.Relation{{From: "doc.Реализация", To: "accumulationregister.Взаиморасчеты", Kind: "register_record"}}
+	if !reflect.DeepEqual(relations, wantRel) {
+		t.Errorf("relations: %+v", relations)
+	}
+
+	doc := objects["doc.Реализация"]
+	if doc.Synonym != "Реализация товаров" || len(doc.Props) != 3 || doc.Props[0].Name != "Номер" || doc.Props[2].Type != "CatalogRef.Контрагенты" {
+		t.Errorf("document props: %+v", doc.Props)
+	}
+	if got := snapshot.FormNames(doc.Forms); !reflect.DeepEqual(got, []string{"ФормаДокумента", "ФормаСписка"}) {
+		t.Fatalf("forms: %v", got)
+	}
+	wantForm := snapshot.Form{
+		Name:       "ФормаДокумента",
+		Attributes: []snapshot.FormAttribute{{Name: "Объект", Type: "DocumentObject.Реализация", Main: true}},
+		Elements: []snapshot.FormElement{{Name: "Шапка", Type: "UsualGroup", Children: []snapshot.FormElement{{
+			Name: "Контрагент", Type: "InputField", DataPath: "Объект.Контрагент",
+			Handlers: []snapshot.FormHandler{{Event: "OnChange", Procedure: "КонтрагентПриИзменении"}},
+		}}}},
+		Commands: []snapshot.FormCommand{{Name: "Заполнить", Action: "Заполнить"}},
+		Handlers: []snapshot.FormHandler{{Event: "OnCreateAtServer", Procedure: "ПриСозданииНаСервере"}},
+	}
+	if !reflect.DeepEqual(doc.Forms[0], wantForm) {
+		t.Errorf("form:\n got %+v\nwant %+v", doc.Forms[0], wantForm)
+	}
+	if doc.Forms[1].Detailed() {
+		t.Errorf("form without Form.xml: want name only, got %+v", doc.Forms[1])
+	}
+	var modules []string
+	for _, m := range doc.Modules {
+		modules = append(modules, m.Name+"="+m.Path)
+	}
+	wantModules := []string{"ObjectModule=Documents/Реализация/Ext/ObjectModule.bsl", "Form.ФормаДокумента=Documents/Реализация/Forms/ФормаДокумента/Ext/Form/Module.bsl"}
+	if !reflect.DeepEqual(modules, wantModules) || !strings.HasPrefix(doc.Modules[0].Source, "Процедура") {
+		t.Errorf("modules: %v", modules)
+	}
+}
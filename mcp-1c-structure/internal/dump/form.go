@@ -0,0 +1,110 @@
+package dump
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// formFile — Ext/Form.xml управляемой формы: реквизиты, дерево элементов, команды и события формы.
+type formFile struct {
+	Attributes []struct {
+		Name  string      `xml:"name,attr"`
+		Type  typeDesc    `xml:"Type"`
+		Title localString `xml:"Title"`
+		Main  bool        `xml:"MainAttribute"`
+	} `xml:"Attributes>Attribute"`
+	Items    formItems `xml:"ChildItems"`
+	Commands []struct {
+		Name   string      `xml:"name,attr"`
+		Title  localString `xml:"Title"`
+		Action string      `xml:"Action"`
+	} `xml:"Commands>Command"`
+	Events []formEvent `xml:"Events>Event"`
+}
+
+// formItems — содержимое ChildItems: элементы любого типа (InputField, UsualGroup, Table, …).
+type formItems struct {
+	Items []formItem `xml:",any"`
+}
+
+type formItem struct {
+	XMLName  xml.Name
+	Name     string      `xml:"name,attr"`
+	DataPath string      `xml:"DataPath"`
+	Title    localString `xml:"Title"`
+	Events   []formEvent `xml:"Events>Event"`
+	Children formItems   `xml:"ChildItems"`
+}
+
+// formEvent — <Event name="OnChange">КонтрагентПриИзменении</Event>.
+type formEvent struct {
+	Name      string `xml:"name,attr"`
+	Procedure string `xml:",chardata"`
+}
+
+// FormModulePrefix — префикс имени модуля формы среди модулей объекта: Form.ФормаДокумента.
+const FormModulePrefix = "Form."
+
+// form читает форму из каталога base (Forms/<Имя>): устройство — из Ext/Form.xml, модуль — из Ext/Form/Module.bsl.
+// У формы без Form.xml (обычная форма, частичная выгрузка) остаётся только имя; module — nil, если модуля нет.
+func (s *Source) form(base string) (snapshot.Form, *snapshot.Module, error) {
+	form := snapshot.Form{Name: filepath.Base(base)}
+	data, err := os.ReadFile(filepath.Join(base, "Ext", "Form.xml"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return form, nil, err
+	default:
+		var f formFile
+		if err := xml.Unmarshal(data, &f); err != nil {
+			return form, nil, fmt.Errorf("%s: %w", s.rel(filepath.Join(base, "Ext", "Form.xml")), err)
+		}
+		for _, a := range f.Attributes {
+			form.Attributes = append(form.Attributes, snapshot.FormAttribute{Name: a.Name, Type: a.Type.String(), Title: a.Title.text(), Main: a.Main})
+		}
+		form.Elements = formElements(f.Items.Items)
+		for _, c := range f.Commands {
+			form.Commands = append(form.Commands, snapshot.FormCommand{Name: c.Name, Title: c.Title.text(), Action: strings.TrimSpace(c.Action)})
+		}
+		form.Handlers = formHandlers(f.Events)
+	}
+	path := filepath.Join(base, "Ext", "Form", "Module.bsl")
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return form, nil, nil
+	}
+	m, err := s.module(FormModulePrefix+form.Name, path)
+	if err != nil {
+		return form, nil, err
+	}
+	return form, &m, nil
+}
+
+func formElements(items []formItem) []snapshot.FormElement {
+	var out []snapshot.FormElement
+	for _, it := range items {
+		out = append(out, snapshot.FormElement{
+			Name:     it.Name,
+			Type:     it.XMLName.Local,
+			DataPath: strings.TrimSpace(it.DataPath),
+			Title:    it.Title.text(),
+			Handlers: formHandlers(it.Events),
+			Children: formElements(it.Children.Items),
+		})
+	}
+	return out
+}
+
+func formHandlers(events []formEvent) []snapshot.FormHandler {
+	var out []snapshot.FormHandler
+	for _, e := range events {
+		out = append(out, snapshot.FormHandler{Event: e.Name, Procedure: strings.TrimSpace(e.Procedure)})
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package dump
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Теги сопоставляются по локальному имени: пространства имён выгрузки (MDClasses, v8, xr, logform)
+// от версии формата не зависят, а префиксы в файлах могут отличаться.
+
+// configFile — Configuration.xml: свойства конфигурации и состав объектов (<Catalog>Имя</Catalog>, …).
+type configFile struct {
+	Configuration struct {
+		Properties mdProperties `xml:"Properties"`
+		Children   struct {
+			Items []struct {
+				XMLName xml.Name
+				Name    string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"ChildObjects"`
+	} `xml:"Configuration"`
+}
+
+// mdFile — файл объекта метаданных: корень MetaDataObject с единственным элементом, имя которого — тип объекта.
+type mdFile struct {
+	Objects []mdObject `xml:",any"`
+}
+
+// mdObject — объект, реквизит, измерение, ресурс или табличная часть выгрузки.
+type mdObject struct {
+	XMLName    xml.Name
+	Properties mdProperties `xml:"Properties"`
+	Children   mdChildren   `xml:"ChildObjects"`
+}
+
+type mdChildren struct {
+	Attributes      []mdObject `xml:"Attribute"`
+	Dimensions      []mdObject `xml:"Dimension"`
+	Resources       []mdObject `xml:"Resource"`
+	TabularSections []mdObject `xml:"TabularSection"`
+	Forms           []string   `xml:"Form"`
+	Subsystems      []string   `xml:"Subsystem"`
+}
+
+// mdProperties — свойства, которые нужны снимку; у каждого типа заполнена только часть.
+type mdProperties struct {
+	Name              string      `xml:"Name"`
+	Synonym           localString `xml:"Synonym"`
+	Comment           string      `xml:"Comment"`
+	Explanation       localString `xml:"Explanation"`
+	Version           string      `xml:"Version"`
+	Type              typeDesc    `xml:"Type"`
+	CodeLength        int         `xml:"CodeLength"`
+	CodeType          string      `xml:"CodeType"`
+	DescriptionLength int         `xml:"DescriptionLength"`
+	NumberLength      int         `xml:"NumberLength"`
+	NumberType        string      `xml:"NumberType"`
+	Owners            []string    `xml:"Owners>Item"`          // Catalog.X
+	RegisterRecords   []string    `xml:"RegisterRecords>Item"` // AccumulationRegister.X
+	Content           []string    `xml:"Content>Item"`         // состав подсистемы
+	Periodicity       string      `xml:"InformationRegisterPeriodicity"`
+	WriteMode         string      `xml:"WriteMode"`
+	RegisterType      string      `xml:"RegisterType"`
+	ChartOfAccounts   string      `xml:"ChartOfAccounts"`
+	Correspondence    bool        `xml:"Correspondence"`
+}
+
+// localString — многоязычная строка: <v8:item><v8:lang>ru</v8:lang><v8:content>…</v8:content></v8:item>.
+type localString struct {
+	Items []struct {
+		Lang    string `xml:"lang"`
+		Content string `xml:"content"`
+	} `xml:"item"`
+}
+
+// text возвращает значение на русском языке, если оно есть, иначе первое.
+func (s localString) text() string {
+	for _, it := range s.Items {
+		if it.Lang == "ru" {
+			return strings.TrimSpace(it.Content)
+		}
+	}
+	if len(s.Items) > 0 {
+		return strings.TrimSpace(s.Items[0].Content)
+	}
+	return ""
+}
+
+// typeDesc — описание типа реквизита: <v8:Type>cfg:CatalogRef.Контрагенты</v8:Type>, <v8:Type>xs:string</v8:Type>, …
+type typeDesc struct {
+	Types         []string `xml:"Type"`
+	TypeSets      []string `xml:"TypeSet"` // cfg:DefinedType.X, cfg:AnyRef
+	DateFractions string   `xml:"DateQualifiers>DateFractions"`
+}
+
+// xsTypes — примитивные типы XML Schema и их имена в снимке.
+var xsTypes = map[string]string{
+	"string":       "String",
+	"decimal":      "Number",
+	"boolean":      "Boolean",
+	"base64Binary": "BinaryData",
+}
+
+// String возвращает тип в виде снимка: String, Number, Date/DateTime/Time (по составу даты), CatalogRef.Контрагенты;
+// составной тип — через запятую.
+func (t typeDesc) String() string {
+	var out []string
+	for _, v := range append(append([]string(nil), t.Types...), t.TypeSets...) {
+		v = strings.TrimSpace(v)
+		prefix, name, ok := strings.Cut(v, ":")
+		if !ok {
+			prefix, name = "", v
+		}
+		switch {
+		case prefix == "xs" && name == "dateTime":
+			name = firstNonEmpty(t.DateFractions, "Date")
+		case prefix == "xs" && xsTypes[name] != "":
+			name = xsTypes[name]
+		}
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return strings.Join(out, ", ")
+}
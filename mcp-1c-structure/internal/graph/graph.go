@@ -0,0 +1,201 @@
+// Package graph строит подграф структуры конфигурации вокруг выбранных объектов и выводит его
+// в форматах Graphviz DOT, Mermaid flowchart и GraphML — для документации и ревью.
+// Используется инструментом structure_export_graph и подкомандой indexer graph.
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// Форматы вывода.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatGraphML = "graphml"
+)
+
+// Ограничения подграфа.
+const (
+	DefaultDepth    = 1
+	MaxDepth        = 3
+	DefaultMaxNodes = 100
+	MaxNodes        = 500
+)
+
+// Selection задаёт исходные объекты и окрестность. Исходные объекты — IDs и объекты, найденные по Query
+// (подстрока в имени или синониме) с фильтром Type; Type без Query выбирает все объекты типа.
+type Selection struct {
+	IDs       []string
+	Type      string
+	Query     string
+	Depth     int      // шагов по связям от исходных объектов; 0 — только они сами; <0 — DefaultDepth, не больше MaxDepth
+	Direction string   // both (по умолчанию), incoming или outgoing
+	Kinds     []string // виды связей для обхода и для рёбер подграфа; пусто — любые
+	MaxNodes  int      // 0 — DefaultMaxNodes, не больше MaxNodes
+}
+
+// Node — объект подграфа.
+type Node struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Synonym string `json:"synonym"`
+	Seed    bool   `json:"seed"`  // исходный объект
+	Depth   int    `json:"depth"` // шагов от ближайшего исходного объекта
+}
+
+// Label — подпись узла: синоним (имя, если синонима нет).
+func (n Node) Label() string {
+	if n.Synonym != "" {
+		return n.Synonym
+	}
+	if n.Name != "" {
+		return n.Name
+	}
+	return n.ID
+}
+
+// Graph — порождённый подграф: узлы по глубине, затем id; рёбра — все связи между узлами.
+type Graph struct {
+	Nodes     []Node
+	Edges     []snapshot.Relation
+	Truncated bool // объектов больше MaxNodes: оставлены исходные и ближайшие
+}
+
+// Build выбирает исходные объекты, добавляет объекты в пределах Depth шагов по связям (store.ImpactAnalysis
+// в каждом направлении от каждого исходного объекта) и связи между всеми выбранными объектами.
+func Build(ctx context.Context, s store.Store, configID string, sel Selection) (Graph, error) {
+	if sel.Depth < 0 {
+		sel.Depth = DefaultDepth
+	}
+	if sel.Depth > MaxDepth {
+		sel.Depth = MaxDepth
+	}
+	if sel.MaxNodes <= 0 {
+		sel.MaxNodes = DefaultMaxNodes
+	}
+	if sel.MaxNodes > MaxNodes {
+		sel.MaxNodes = MaxNodes
+	}
+	if len(sel.IDs) == 0 && sel.Type == "" && sel.Query == "" {
+		return Graph{}, fmt.Errorf("nothing selected: set object ids, a type or a query")
+	}
+
+	var g Graph
+	nodes := make(map[string]*Node)
+	add := func(o snapshot.Object, depth int, seed bool) {
+		id := o.ID
+		if n, ok := nodes[id]; ok {
+			if depth < n.Depth {
+				n.Depth = depth
+			}
+			n.Seed = n.Seed || seed
+			return
+		}
+		nodes[id] = &Node{ID: id, Type: o.Type, Name: o.Name, Synonym: o.Synonym, Seed: seed, Depth: depth}
+	}
+	for _, id := range sel.IDs {
+		o, ok, err := s.GetObject(ctx, configID, id)
+		if err != nil {
+			return Graph{}, err
+		}
+		if !ok {
+			return Graph{}, fmt.Errorf("object not found: %s", id)
+		}
+		add(o, 0, true)
+	}
+	if sel.Type != "" || sel.Query != "" {
+		// Search отдаёт не больше 50 объектов за раз; лишние исходные объекты всё равно отрежет MaxNodes.
+		for offset := 0; len(nodes) <= sel.MaxNodes; {
+			list, total, err := s.Search(ctx, configID, sel.Query, sel.Type, 50, offset)
+			if err != nil {
+				return Graph{}, err
+			}
+			for _, o := range list {
+				add(o, 0, true)
+			}
+			offset += len(list)
+			if len(list) == 0 || offset >= total {
+				break
+			}
+		}
+	}
+
+	seeds := make([]string, 0, len(nodes))
+	for id := range nodes {
+		seeds = append(seeds, id)
+	}
+	sort.Strings(seeds)
+	var directions []string
+	switch sel.Direction {
+	case "incoming", "outgoing":
+		directions = []string{sel.Direction}
+	default:
+		directions = []string{"incoming", "outgoing"}
+	}
+	for _, id := range seeds {
+		if sel.Depth == 0 || len(nodes) > sel.MaxNodes {
+			break
+		}
+		for _, dir := range directions {
+			found, _, err := s.ImpactAnalysis(ctx, configID, store.ImpactQuery{ObjectID: id, Direction: dir, Kinds: sel.Kinds, MaxDepth: sel.Depth, MaxNodes: sel.MaxNodes})
+			if err != nil {
+				return Graph{}, err
+			}
+			for _, n := range found {
+				add(snapshot.Object{ID: n.ID, Type: n.Type, Name: n.Name}, n.Depth, false)
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, *n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Depth != g.Nodes[j].Depth {
+			return g.Nodes[i].Depth < g.Nodes[j].Depth
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	if len(g.Nodes) > sel.MaxNodes {
+		g.Nodes, g.Truncated = g.Nodes[:sel.MaxNodes], true
+	}
+	// ImpactNode несёт только тип и имя: синонимы добираются карточками объектов.
+	ids := make([]string, len(g.Nodes))
+	for i := range g.Nodes {
+		n := &g.Nodes[i]
+		ids[i] = n.ID
+		if n.Seed {
+			continue
+		}
+		if o, ok, err := s.GetObject(ctx, configID, n.ID); err != nil {
+			return Graph{}, err
+		} else if ok {
+			n.Synonym = o.Synonym
+		}
+	}
+	edges, err := s.RelationsAmong(ctx, configID, ids, sel.Kinds)
+	if err != nil {
+		return Graph{}, err
+	}
+	g.Edges = edges
+	return g, nil
+}
+
+// Render выводит граф в формате format (FormatDOT, FormatMermaid или FormatGraphML).
+func Render(g Graph, format string) (string, error) {
+	switch format {
+	case FormatDOT, "":
+		return DOT(g), nil
+	case FormatMermaid:
+		return Mermaid(g), nil
+	case FormatGraphML:
+		return GraphML(g), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q: use dot, mermaid or graphml", format)
+}
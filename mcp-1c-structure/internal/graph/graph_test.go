@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	s, err := memory.New("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := []snapshot.Object{
+		{ID: "doc.Заказ", Type: "Document", Name: "Заказ", Props: []snapshot.Prop{{Name: "Контрагент", Type: "CatalogRef.Контрагенты"}}},
+		{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты", Synonym: "Контрагенты и партнёры"},
+		{ID: "cat.Склады", Type: "Catalog", Name: "Склады"},
+		{ID: "cat.Валюты", Type: "Catalog", Name: "Валюты"},
+	}
+	relations := []snapshot.Relation{
+		{From: "doc.Заказ", To: "cat.Склады", Kind: "reference"},
+		{From: "cat.Контрагенты", To: "cat.Валюты", Kind: "reference"},
+	}
+	if _, err := s.Import(ctx, "default", snapshot.FromSlices(snapshot.Meta{}, objects, relations), store.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := Build(ctx, s, "default", Selection{IDs: []string{"Document.Заказ"}, Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if want := "doc.Заказ cat.Контрагенты cat.Склады"; strings.Join(ids, " ") != want {
+		t.Fatalf("nodes: got %q, want %q", strings.Join(ids, " "), want)
+	}
+	if !g.Nodes[0].Seed || g.Nodes[1].Seed || g.Nodes[1].Depth != 1 || g.Nodes[1].Synonym != "Контрагенты и партнёры" {
+		t.Errorf("unexpected nodes %+v", g.Nodes)
+	}
+	if len(g.Edges) != 2 || g.Truncated {
+		t.Errorf("want 2 edges among the nodes and no truncation, got %+v, truncated %v", g.Edges, g.Truncated)
+	}
+
+	g, err = Build(ctx, s, "default", Selection{IDs: []string{"doc.Заказ"}, Depth: 2, MaxNodes: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 2 || !g.Truncated {
+		t.Errorf("MaxNodes 2: want 2 nodes and truncation, got %+v, truncated %v", g.Nodes, g.Truncated)
+	}
+
+	if _, err := Build(ctx, s, "default", Selection{}); err == nil {
+		t.Error("empty selection: want an error")
+	}
+	if _, err := Build(ctx, s, "default", Selection{IDs: []string{"doc.НетТакого"}}); err == nil {
+		t.Error("unknown object: want an error")
+	}
+}
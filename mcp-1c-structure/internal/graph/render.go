@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// DOT выводит граф для Graphviz: узел — «синоним\nтип», исходные объекты выделены, ребро подписано видом связи.
+func DOT(g Graph) string {
+	var b strings.Builder
+	b.WriteString("digraph structure {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, n := range g.Nodes {
+		attrs := ""
+		if n.Seed {
+			attrs = ", style=\"rounded,filled\", fillcolor=\"#fff2cc\""
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(n.ID), dotQuote(n.Label()+"\n"+n.Type), attrs)
+	}
+	for _, e := range g.Edges {
+		label := e.Kind
+		if e.Prop != "" {
+			label += "\n" + e.Prop
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(label))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// Mermaid выводит граф как flowchart: id узлов n1, n2… (id объектов содержат точки и кириллицу),
+// подпись — «синоним<br/>тип», исходные объекты — класс seed.
+func Mermaid(g Graph) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	keys := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		key := fmt.Sprintf("n%d", i+1)
+		keys[n.ID] = key
+		fmt.Fprintf(&b, "  %s[\"%s<br/>%s\"]\n", key, mermaidEscape(n.Label()), mermaidEscape(n.Type))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", keys[e.From], mermaidEscape(e.Kind), keys[e.To])
+	}
+	var seeds []string
+	for _, n := range g.Nodes {
+		if n.Seed {
+			seeds = append(seeds, keys[n.ID])
+		}
+	}
+	if len(seeds) > 0 {
+		b.WriteString("  classDef seed fill:#fff2cc,stroke:#d6b656\n")
+		fmt.Fprintf(&b, "  class %s seed\n", strings.Join(seeds, ","))
+	}
+	return b.String()
+}
+
+func mermaidEscape(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ")
+	return r.Replace(s)
+}
+
+// GraphML выводит граф в GraphML (yEd, Gephi): у узлов данные label, type, name, synonym, seed, у рёбер — kind и prop.
+func GraphML(g Graph) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, k := range []struct{ id, domain, typ string }{
+		{"label", "node", "string"},
+		{"type", "node", "string"},
+		{"name", "node", "string"},
+		{"synonym", "node", "string"},
+		{"seed", "node", "boolean"},
+		{"kind", "edge", "string"},
+		{"prop", "edge", "string"},
+	} {
+		fmt.Fprintf(&b, "  <key id=%q for=%q attr.name=%q attr.type=%q/>\n", k.id, k.domain, k.id, k.typ)
+	}
+	b.WriteString(`  <graph id="structure" edgedefault="directed">` + "\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "    <node id=\"%s\">\n", xmlEscape(n.ID))
+		writeData(&b, "label", n.Label())
+		writeData(&b, "type", n.Type)
+		writeData(&b, "name", n.Name)
+		writeData(&b, "synonym", n.Synonym)
+		writeData(&b, "seed", fmt.Sprint(n.Seed))
+		b.WriteString("    </node>\n")
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i+1, xmlEscape(e.From), xmlEscape(e.To))
+		writeData(&b, "kind", e.Kind)
+		if e.Prop != "" {
+			writeData(&b, "prop", e.Prop)
+		}
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	return b.String()
+}
+
+func writeData(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "      <data key=\"%s\">%s</data>\n", key, xmlEscape(value))
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// sample — граф с кавычками, переводом строки и угловыми скобками в подписях: их экранирует каждый формат.
+func sample() Graph {
+	return Graph{
+		Nodes: []Node{
+			{ID: "doc.Заказ", Type: "Document", Name: "Заказ", Synonym: `Заказ "клиента"`, Seed: true},
+			{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты", Depth: 1},
+		},
+		Edges: []snapshot.Relation{{From: "doc.Заказ", To: "cat.Контрагенты", Kind: "reference", Prop: "Товары.<Покупатель>"}},
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FormatDOT, []string{
+			"digraph structure {\n",
+			`  "doc.Заказ" [label="Заказ \"клиента\"\nDocument", style="rounded,filled", fillcolor="#fff2cc"];`,
+			`  "cat.Контрагенты" [label="Контрагенты\nCatalog"];`,
+			`  "doc.Заказ" -> "cat.Контрагенты" [label="reference\nТовары.<Покупатель>"];`,
+		}},
+		{FormatMermaid, []string{
+			"flowchart LR\n",
+			`  n1["Заказ #quot;клиента#quot;<br/>Document"]`,
+			`  n2["Контрагенты<br/>Catalog"]`,
+			`  n1 -->|"reference"| n2`,
+			"  class n1 seed\n",
+		}},
+		{FormatGraphML, []string{
+			`<node id="doc.Заказ">`,
+			`<data key="label">Заказ &#34;клиента&#34;</data>`,
+			`<data key="seed">true</data>`,
+			`<edge id="e1" source="doc.Заказ" target="cat.Контрагенты">`,
+			`<data key="prop">Товары.&lt;Покупатель&gt;</data>`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := Render(sample(), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("output has no %q:\n%s", w, out)
+				}
+			}
+		})
+	}
+	if _, err := Render(sample(), "svg"); err == nil {
+		t.Error("unknown format: want an error")
+	}
+}
+
+// GraphML должен читаться XML-парсером: узлы и рёбра с данными.
+func TestGraphMLIsValidXML(t *testing.T) {
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal([]byte(GraphML(sample())), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 || doc.Graph.Edges[0].Target != "cat.Контрагенты" {
+		t.Errorf("unexpected graph %+v", doc.Graph)
+	}
+}
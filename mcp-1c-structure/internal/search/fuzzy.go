@@ -0,0 +1,197 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyThreshold — минимальное сходство по триграммам для нечёткого поиска; совпадает со значением
+// pg_trgm.similarity_threshold по умолчанию, которое использует оператор % в PostgreSQL.
+const FuzzyThreshold = 0.3
+
+// Виды вариантов запроса при нечётком поиске.
+const (
+	VariantQuery    = "query"    // запрос как есть
+	VariantLayout   = "layout"   // набран в другой раскладке: Rjynhfutyns -> Контрагенты
+	VariantTranslit = "translit" // латиница вместо кириллицы: Kontragenty -> Контрагенты
+)
+
+// Variant — вариант написания запроса, по которому ищется объект.
+type Variant struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+}
+
+// Variants возвращает запрос и его варианты в другой раскладке и в транслитерации (без повторов, в нижнем регистре).
+func Variants(query string) []Variant {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	out := []Variant{{Text: query, Kind: VariantQuery}}
+	add := func(text, kind string) {
+		for _, v := range out {
+			if v.Text == text {
+				return
+			}
+		}
+		out = append(out, Variant{Text: text, Kind: kind})
+	}
+	add(SwitchLayout(query), VariantLayout)
+	if t := Transliterate(query); t != "" {
+		add(t, VariantTranslit)
+	}
+	return out
+}
+
+// Раскладки ЙЦУКЕН и QWERTY: символ на той же клавише.
+const (
+	layoutEN = "`qwertyuiop[]asdfghjkl;'zxcvbnm,."
+	layoutRU = "ёйцукенгшщзхъфывапролджэячсмитьбю"
+)
+
+var enToRU, ruToEN = layoutMaps()
+
+func layoutMaps() (map[rune]rune, map[rune]rune) {
+	en, ru := []rune(layoutEN), []rune(layoutRU)
+	toRU := make(map[rune]rune, len(en))
+	toEN := make(map[rune]rune, len(en))
+	for i := range en {
+		toRU[en[i]] = ru[i]
+		toEN[ru[i]] = en[i]
+	}
+	return toRU, toEN
+}
+
+// SwitchLayout переводит текст, набранный не в той раскладке: если в нём больше латиницы — в ЙЦУКЕН, иначе в QWERTY.
+// Символы без пары остаются как есть.
+func SwitchLayout(text string) string {
+	latin, cyrillic := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		}
+	}
+	m := ruToEN
+	if latin > cyrillic {
+		m = enToRU
+	}
+	return strings.Map(func(r rune) rune {
+		lower := unicode.ToLower(r)
+		if t, ok := m[lower]; ok {
+			if lower != r {
+				return unicode.ToUpper(t)
+			}
+			return t
+		}
+		return r
+	}, text)
+}
+
+// translit — сочетания латинских букв и соответствующая кириллица; длинные сочетания проверяются первыми.
+var translit = []struct{ lat, cyr string }{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"yo", "ё"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"}, {"yu", "ю"}, {"ya", "я"}, {"ye", "е"},
+	{"ju", "ю"}, {"ja", "я"}, {"jo", "ё"},
+	{"a", "а"}, {"b", "б"}, {"v", "в"}, {"w", "в"}, {"g", "г"}, {"d", "д"}, {"e", "е"}, {"z", "з"}, {"i", "и"},
+	{"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"r", "р"}, {"s", "с"},
+	{"t", "т"}, {"u", "у"}, {"f", "ф"}, {"h", "х"}, {"c", "к"}, {"q", "к"}, {"x", "кс"}, {"y", "ы"},
+}
+
+// Transliterate переводит латиницу в кириллицу (Kontragenty -> контрагенты); y после гласной читается как й.
+// Возвращает "", если в тексте нет латинских букв.
+func Transliterate(text string) string {
+	text = strings.ToLower(text)
+	var b strings.Builder
+	changed := false
+	prevVowel := false
+	for i := 0; i < len(text); {
+		matched := false
+		for _, t := range translit {
+			if !strings.HasPrefix(text[i:], t.lat) {
+				continue
+			}
+			cyr := t.cyr
+			if t.lat == "y" && prevVowel {
+				cyr = "й"
+			}
+			b.WriteString(cyr)
+			i += len(t.lat)
+			prevVowel = strings.ContainsAny(cyr, "аеёиоуыэюя")
+			matched, changed = true, true
+			break
+		}
+		if !matched {
+			r := []rune(text[i:])[0]
+			b.WriteRune(r)
+			i += len(string(r))
+			prevVowel = strings.ContainsRune("аеёиоуыэюя", r)
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return b.String()
+}
+
+// Trigrams возвращает множество триграмм строки так же, как pg_trgm: нижний регистр, слова из букв и цифр,
+// каждое дополнено двумя пробелами в начале и одним в конце.
+func Trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// Similarity — сходство множеств триграмм (доля общих от объединения), как similarity() в pg_trgm.
+func Similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range a {
+		if b[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// Fuzzy — подготовленные варианты запроса для сравнения с именами и синонимами объектов.
+type Fuzzy struct {
+	variants []Variant
+	trigrams []map[string]bool
+}
+
+func NewFuzzy(variants []Variant) *Fuzzy {
+	f := &Fuzzy{variants: variants}
+	for _, v := range variants {
+		f.trigrams = append(f.trigrams, Trigrams(v.Text))
+	}
+	return f
+}
+
+// Match возвращает наибольшее сходство имени или синонима с вариантами запроса, поле (FieldName или FieldSynonym)
+// и вариант; ok=false, если сходство ниже FuzzyThreshold. При равном сходстве предпочитается имя и более ранний вариант.
+func (f *Fuzzy) Match(name, synonym string) (score float64, field string, variant Variant, ok bool) {
+	fields := [2]map[string]bool{Trigrams(name), Trigrams(synonym)}
+	for i, vt := range f.trigrams {
+		for j, ft := range fields {
+			if s := Similarity(vt, ft); s > score {
+				score, variant = s, f.variants[i]
+				field = FieldName
+				if j == 1 {
+					field = FieldSynonym
+				}
+			}
+		}
+	}
+	return score, field, variant, score >= FuzzyThreshold
+}
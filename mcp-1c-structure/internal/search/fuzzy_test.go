@@ -0,0 +1,87 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []Variant
+	}{
+		{"Rjynhfutyns", []Variant{{"rjynhfutyns", VariantQuery}, {"контрагенты", VariantLayout}, {"рйынхфутынс", VariantTranslit}}},
+		{"Kontragenty", []Variant{{"kontragenty", VariantQuery}, {"лщтекфпутен", VariantLayout}, {"контрагенты", VariantTranslit}}},
+		{" Контрагенты ", []Variant{{"контрагенты", VariantQuery}, {"rjynhfutyns", VariantLayout}}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Variants(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Variants(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSwitchLayout(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Rjynhfutyns", "Контрагенты"},
+		{"контрагенты", "rjynhfutyns"},
+		{"ghjdthrf 1", "проверка 1"},
+	}
+	for _, tt := range tests {
+		if got := SwitchLayout(tt.in); got != tt.want {
+			t.Errorf("SwitchLayout(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Kontragenty", "контрагенты"},
+		{"Nomenklatura", "номенклатура"},
+		{"Valyuta", "валюта"},
+		{"shchuka", "щука"},
+		{"Sklady", "склады"},
+		{"Zhurnal", "журнал"},
+		{"Moy", "мой"}, // y после гласной — й
+		{"контрагенты", ""},
+	}
+	for _, tt := range tests {
+		if got := Transliterate(tt.in); got != tt.want {
+			t.Errorf("Transliterate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query, name, synonym string
+		field, kind          string
+		ok                   bool
+	}{
+		{"Rjynhfutyns", "Контрагенты", "Контрагенты", FieldName, VariantLayout, true},
+		{"Kontragenty", "Контрагенты", "", FieldName, VariantTranslit, true},
+		{"контрагнеты", "ДоговорыКонтрагентов", "Контрагенты", FieldSynonym, VariantQuery, true},
+		{"контрагенты", "Номенклатура", "Склады", "", "", false},
+	}
+	for _, tt := range tests {
+		score, field, variant, ok := NewFuzzy(Variants(tt.query)).Match(tt.name, tt.synonym)
+		if ok != tt.ok || field != tt.field || variant.Kind != tt.kind {
+			t.Errorf("Match(%q; %q, %q) = %v, %q, %+v, %v; want field %q, variant %q, ok %v",
+				tt.query, tt.name, tt.synonym, score, field, variant, ok, tt.field, tt.kind, tt.ok)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a := Trigrams("Контрагенты")
+	if got := Similarity(a, Trigrams("контрагенты")); got != 1 {
+		t.Errorf("same word: %v, want 1", got)
+	}
+	if got := Similarity(a, nil); got != 0 {
+		t.Errorf("empty set: %v, want 0", got)
+	}
+	if got := Similarity(Trigrams("кот"), Trigrams("кит")); got <= 0 || got >= FuzzyThreshold {
+		t.Errorf("кот/кит: %v, want between 0 and the threshold", got)
+	}
+}
@@ -0,0 +1,168 @@
+// Package search — полнотекстовый поиск по объектам снимка с русской морфологией: разбиение на слова,
+// основы слов (Stem) и ранжирование по полям. Postgres ищет своим tsvector со словарём russian,
+// SQLite и memory — по основам, которые строит этот пакет; поле совпадения для всех backend определяет MatchedField.
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// Поля объекта, по которым идёт полнотекстовый поиск, в порядке убывания веса.
+const (
+	FieldName            = "name"
+	FieldSynonym         = "synonym"
+	FieldDescription     = "description"
+	FieldProps           = "props"           // имена и синонимы реквизитов
+	FieldTabularSections = "tabularSections" // имена табличных частей, имена и синонимы их колонок
+)
+
+// Fields — поля в порядке Doc.
+var Fields = [...]string{FieldName, FieldSynonym, FieldDescription, FieldProps, FieldTabularSections}
+
+// Weights — вес совпадения в каждом поле (по индексам Fields). Те же веса Postgres передаёт в ts_rank.
+var Weights = [...]float64{1.0, 0.9, 0.3, 0.1, 0.1}
+
+// Doc — основы слов объекта по полям Fields.
+type Doc [len(Fields)][]string
+
+// Document строит Doc объекта.
+func Document(o *snapshot.Object) Doc {
+	var d Doc
+	d[0] = Terms(o.Name)
+	d[1] = Terms(o.Synonym)
+	d[2] = Terms(o.Description)
+	d[3] = PropTerms(o.Props)
+	d[4] = TabularTerms(o.TabularSections)
+	return d
+}
+
+// PropTerms возвращает основы имён и синонимов реквизитов.
+func PropTerms(props []snapshot.Prop) []string {
+	var out []string
+	for _, p := range props {
+		out = append(out, Terms(p.Name)...)
+		out = append(out, Terms(p.Synonym)...)
+	}
+	return out
+}
+
+// TabularTerms возвращает основы имён табличных частей и имён и синонимов их колонок.
+func TabularTerms(sections []snapshot.TabularSection) []string {
+	var out []string
+	for _, ts := range sections {
+		out = append(out, Terms(ts.Name)...)
+		out = append(out, PropTerms(ts.Props)...)
+	}
+	return out
+}
+
+// Words разбивает текст на слова: по небуквенным символам и по границе «строчная — заглавная»
+// в идентификаторах 1С (ДоговорКонтрагента -> Договор Контрагента).
+func Words(text string) []string {
+	var words []string
+	start := -1 // начало текущего слова в байтах
+	var prev rune
+	for i, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if start >= 0 {
+				words = append(words, text[start:i])
+				start = -1
+			}
+		case start < 0:
+			start = i
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			words = append(words, text[start:i])
+			start = i
+		}
+		prev = r
+	}
+	if start >= 0 {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+// Terms возвращает основы слов текста: Words, нижний регистр, ё -> е, без служебных слов, Stem.
+func Terms(text string) []string {
+	words := Words(text)
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.ReplaceAll(strings.ToLower(w), "ё", "е")
+		if stopWords[w] {
+			continue
+		}
+		out = append(out, Stem(w))
+	}
+	return out
+}
+
+// QueryTerms возвращает основы слов запроса без повторов.
+func QueryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range Terms(query) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Match проверяет, что каждая основа запроса есть хотя бы в одном поле, и возвращает оценку и поле совпадения.
+// Оценка — средний по словам запроса суммарный вес полей, в которых слово встретилось; поле — самое весомое
+// из полей, где встретилось хоть одно слово.
+func (d Doc) Match(terms []string) (score float64, field string, ok bool) {
+	if len(terms) == 0 {
+		return 0, "", false
+	}
+	best := len(Fields)
+	for _, t := range terms {
+		found := false
+		for i, f := range d {
+			if contains(f, t) {
+				found = true
+				score += Weights[i]
+				if i < best {
+					best = i
+				}
+			}
+		}
+		if !found {
+			return 0, "", false
+		}
+	}
+	return score / float64(len(terms)), Fields[best], true
+}
+
+// MatchedField возвращает самое весомое поле объекта, где встретилась хоть одна основа запроса
+// (backend, ранжирующие сами, так же определяют поле совпадения).
+func MatchedField(o *snapshot.Object, terms []string) string {
+	d := Document(o)
+	for i, f := range d {
+		for _, t := range terms {
+			if contains(f, t) {
+				return Fields[i]
+			}
+		}
+	}
+	return ""
+}
+
+// Text склеивает основы поля через пробел — в таком виде их индексирует FTS5 в SQLite.
+func Text(terms []string) string {
+	return strings.Join(terms, " ")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Контрагенты", []string{"контрагент"}},
+		{"контрагентов", []string{"контрагент"}},
+		{"ДоговорКонтрагента", []string{"договор", "контрагент"}},
+		{"Номенклатура и ёлки", []string{"номенклатур", "елк"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+	if got := QueryTerms("договоры договора контрагента"); !reflect.DeepEqual(got, []string{"договор", "контрагент"}) {
+		t.Errorf("QueryTerms: %q", got)
+	}
+}
+
+func TestDocMatch(t *testing.T) {
+	o := snapshot.Object{
+		Name:        "ДоговорыКонтрагентов",
+		Synonym:     "Договоры с контрагентами",
+		Description: "Условия расчётов",
+		Props:       []snapshot.Prop{{Name: "Валюта", Synonym: "Валюта взаиморасчётов"}},
+		TabularSections: []snapshot.TabularSection{
+			{Name: "Этапы", Props: []snapshot.Prop{{Name: "СрокОплаты"}}},
+		},
+	}
+	d := Document(&o)
+	tests := []struct {
+		query string
+		field string
+		score float64
+		ok    bool
+	}{
+		{"договор", FieldName, Weights[0] + Weights[1], true},
+		{"расчётов", FieldDescription, Weights[2], true},
+		{"валюта", FieldProps, Weights[3], true},
+		{"оплаты", FieldTabularSections, Weights[4], true},
+		{"договор склад", "", 0, false}, // каждое слово запроса должно найтись
+		{"и", "", 0, false}, // только служебные слова
+	}
+	for _, tt := range tests {
+		score, field, ok := d.Match(QueryTerms(tt.query))
+		if ok != tt.ok || field != tt.field || score != tt.score {
+			t.Errorf("Match(%q) = %v, %q, %v; want %v, %q, %v", tt.query, score, field, ok, tt.score, tt.field, tt.ok)
+		}
+	}
+	if got := MatchedField(&o, QueryTerms("валюта договор")); got != FieldName {
+		t.Errorf("MatchedField: %q, want %q", got, FieldName)
+	}
+}
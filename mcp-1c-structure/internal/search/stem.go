@@ -0,0 +1,189 @@
+package search
+
+// Stem возвращает основу русского слова по алгоритму Snowball (тот же, что у словаря russian_stem в PostgreSQL).
+// Слово должно быть в нижнем регистре, ё заменена на е; слова без кириллицы возвращаются как есть.
+func Stem(word string) string {
+	w := []rune(word)
+	rv, r2 := regions(w)
+	if rv >= len(w) {
+		return word
+	}
+	s := stemmer{w: w, rv: rv}
+
+	// Шаг 1: деепричастие, иначе возвратная частица и затем прилагательное, глагол или существительное.
+	if !s.removeGroups(perfectiveGerund1, perfectiveGerund2) {
+		s.remove(reflexive)
+		if !s.adjectival() && !s.removeGroups(verb1, verb2) {
+			s.remove(noun)
+		}
+	}
+	// Шаг 2.
+	s.remove(step2)
+	// Шаг 3: словообразовательный суффикс в R2.
+	if end, ok := s.suffix(derivational); ok && end >= r2 {
+		s.w = s.w[:end]
+	}
+	// Шаг 4.
+	switch {
+	case s.remove(superlative):
+		s.undoubleN()
+	case s.undoubleN():
+	default:
+		s.remove(softSign)
+	}
+	return string(s.w)
+}
+
+// Окончания хранятся рунами, чтобы не перекодировать их на каждом слове.
+var (
+	perfectiveGerund1 = suffixes("вшись", "вши", "в") // после а или я
+	perfectiveGerund2 = suffixes("ившись", "ывшись", "ивши", "ывши", "ив", "ыв")
+	adjective         = suffixes(
+		"ими", "ыми", "его", "ого", "ему", "ому",
+		"ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	)
+	participle1 = suffixes("ем", "нн", "вш", "ющ", "щ") // после а или я
+	participle2 = suffixes("ивш", "ывш", "ующ")
+	reflexive   = suffixes("ся", "сь")
+	verb1       = suffixes( // после а или я
+		"ете", "йте", "ешь", "нно",
+		"ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть", "й", "л", "н",
+	)
+	verb2 = suffixes(
+		"ейте", "уйте",
+		"ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют", "ены", "ить", "ыть", "ишь",
+		"ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую", "ю",
+	)
+	noun = suffixes(
+		"иями", "ями", "ами", "ией", "иям", "ием", "иях",
+		"ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом", "ах", "ях", "ию", "ью", "ия", "ья",
+		"а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я",
+	)
+	step2        = suffixes("и")
+	derivational = suffixes("ость", "ост")
+	superlative  = suffixes("ейше", "ейш")
+	softSign     = suffixes("ь")
+)
+
+func suffixes(list ...string) [][]rune {
+	out := make([][]rune, len(list))
+	for i, s := range list {
+		out[i] = []rune(s)
+	}
+	return out
+}
+
+type stemmer struct {
+	w  []rune
+	rv int // начало области RV: окончания ищутся только в ней
+}
+
+// suffix находит самое длинное окончание из list, целиком лежащее в RV, и возвращает позицию его начала.
+func (s *stemmer) suffix(list [][]rune) (int, bool) {
+	best := -1
+	for _, suf := range list {
+		start := len(s.w) - len(suf)
+		if start < s.rv || (best >= 0 && start >= best) {
+			continue
+		}
+		if equalRunes(s.w[start:], suf) {
+			best = start
+		}
+	}
+	return best, best >= 0
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *stemmer) remove(list [][]rune) bool {
+	end, ok := s.suffix(list)
+	if ok {
+		s.w = s.w[:end]
+	}
+	return ok
+}
+
+// removeGroups удаляет самое длинное окончание из двух групп; окончание первой группы должно стоять после а или я
+// (сама буква остаётся), как в Snowball: если самое длинное совпадение не проходит это условие, удаления нет.
+func (s *stemmer) removeGroups(afterA, plain [][]rune) bool {
+	e1, ok1 := s.suffix(afterA)
+	e2, ok2 := s.suffix(plain)
+	if ok2 && (!ok1 || e2 <= e1) {
+		s.w = s.w[:e2]
+		return true
+	}
+	if ok1 && e1-1 >= s.rv && (s.w[e1-1] == 'а' || s.w[e1-1] == 'я') {
+		s.w = s.w[:e1]
+		return true
+	}
+	return false
+}
+
+// adjectival удаляет окончание прилагательного и следующий перед ним суффикс причастия, если он есть.
+func (s *stemmer) adjectival() bool {
+	if !s.remove(adjective) {
+		return false
+	}
+	s.removeGroups(participle1, participle2)
+	return true
+}
+
+func (s *stemmer) undoubleN() bool {
+	n := len(s.w)
+	if n-2 >= s.rv && s.w[n-1] == 'н' && s.w[n-2] == 'н' {
+		s.w = s.w[:n-1]
+		return true
+	}
+	return false
+}
+
+func isVowel(r rune) bool {
+	switch r {
+	case 'а', 'е', 'и', 'о', 'у', 'ы', 'э', 'ю', 'я':
+		return true
+	}
+	return false
+}
+
+// regions возвращает начало RV (после первой гласной) и R2 (R1 от R1; R1 — после первой согласной, идущей за гласной).
+func regions(w []rune) (rv, r2 int) {
+	rv, r2 = len(w), len(w)
+	i := 0
+	for i < len(w) && !isVowel(w[i]) {
+		i++
+	}
+	if i == len(w) {
+		return rv, r2
+	}
+	rv = i + 1
+	i = rv
+	for i < len(w) && isVowel(w[i]) {
+		i++
+	}
+	if i == len(w) {
+		return rv, r2
+	}
+	// R1 = i+1; R2 ищется так же внутри R1.
+	i++
+	for i < len(w) && !isVowel(w[i]) {
+		i++
+	}
+	if i == len(w) {
+		return rv, r2
+	}
+	i++
+	for i < len(w) && isVowel(w[i]) {
+		i++
+	}
+	if i < len(w) {
+		r2 = i + 1
+	}
+	return rv, r2
+}
@@ -0,0 +1,67 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Delta — изменения относительно текущего снимка: объекты на добавление или замену (целиком),
+// идентификаторы удалённых объектов и связи на добавление и удаление. Meta, если заполнена, заменяет метаданные снимка.
+type Delta struct {
+	Meta            Meta       `json:"meta"`
+	Upserted        []Object   `json:"upserted"`
+	Deleted         []string   `json:"deleted"`
+	AddRelations    []Relation `json:"addRelations"`
+	RemoveRelations []Relation `json:"removeRelations"`
+}
+
+// IsDeltaDir сообщает, что каталог содержит дельту (delta.json), а не полный снимок.
+func IsDeltaDir(rootDir string) bool {
+	info, err := os.Stat(filepath.Join(rootDir, "delta.json"))
+	return err == nil && !info.IsDir()
+}
+
+// LoadDelta reads delta.json from rootDir; module sources with a path are read from files under rootDir.
+func LoadDelta(rootDir string) (Delta, error) {
+	path := filepath.Join(rootDir, "delta.json")
+	if err := ensureInsideRoot(rootDir, path); err != nil {
+		return Delta{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Delta{}, err
+	}
+	var d Delta
+	if err := json.Unmarshal(data, &d); err != nil {
+		return Delta{}, err
+	}
+	for i := range d.Upserted {
+		loadModuleSources(rootDir, &d.Upserted[i])
+	}
+	return d, nil
+}
+
+// MergeMeta накладывает заполненные поля Meta дельты на метаданные предыдущего снимка.
+func (d Delta) MergeMeta(prev Meta) Meta {
+	m := prev
+	if d.Meta.Version != "" {
+		m.Version = d.Meta.Version
+	}
+	if d.Meta.ConfigName != "" {
+		m.ConfigName = d.Meta.ConfigName
+	}
+	if d.Meta.ConfigVersion != "" {
+		m.ConfigVersion = d.Meta.ConfigVersion
+	}
+	if d.Meta.ExportedAt != "" {
+		m.ExportedAt = d.Meta.ExportedAt
+	}
+	if d.Meta.Source != "" {
+		m.Source = d.Meta.Source
+	}
+	if d.Meta.IndexVersion != 0 {
+		m.IndexVersion = d.Meta.IndexVersion
+	}
+	return m
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Form — форма объекта. В objects.json форма задаётся строкой (только имя, как в прежних снимках) или объектом
+// с реквизитами, деревом элементов, командами и обработчиками событий; форма без подробностей пишется строкой.
+type Form struct {
+	Name       string          `json:"name"`
+	Attributes []FormAttribute `json:"attributes,omitempty"`
+	Elements   []FormElement   `json:"elements,omitempty"` // элементы верхнего уровня, вложенные — в Children
+	Commands   []FormCommand   `json:"commands,omitempty"`
+	Handlers   []FormHandler   `json:"handlers,omitempty"` // события самой формы: ПриСозданииНаСервере, ПриОткрытии, …
+}
+
+// FormAttribute — реквизит формы. Main — основной реквизит (обычно Объект или Список).
+type FormAttribute struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+	Main  bool   `json:"main,omitempty"`
+}
+
+// FormElement — элемент формы: поле, таблица, группа, кнопка и т. п. DataPath — путь к данным
+// (Объект.Контрагент, Объект.Товары.Номенклатура), пусто у групп и декораций.
+type FormElement struct {
+	Name     string        `json:"name"`
+	Type     string        `json:"type"` // InputField, Table, UsualGroup, Button, …
+	DataPath string        `json:"dataPath,omitempty"`
+	Title    string        `json:"title,omitempty"`
+	Handlers []FormHandler `json:"handlers,omitempty"`
+	Children []FormElement `json:"children,omitempty"`
+}
+
+// FormCommand — команда формы; Action — процедура модуля формы, которую она вызывает.
+type FormCommand struct {
+	Name   string `json:"name"`
+	Title  string `json:"title,omitempty"`
+	Action string `json:"action,omitempty"`
+}
+
+// FormHandler — обработчик события: событие (ПриИзменении, OnChange, …) и процедура модуля формы.
+type FormHandler struct {
+	Event     string `json:"event"`
+	Procedure string `json:"procedure"`
+}
+
+// Detailed сообщает, что у формы есть что-то кроме имени.
+func (f Form) Detailed() bool {
+	return len(f.Attributes) > 0 || len(f.Elements) > 0 || len(f.Commands) > 0 || len(f.Handlers) > 0
+}
+
+// formFields — Form без своих методов JSON, чтобы не зациклить MarshalJSON и UnmarshalJSON.
+type formFields Form
+
+func (f Form) MarshalJSON() ([]byte, error) {
+	if !f.Detailed() {
+		return json.Marshal(f.Name)
+	}
+	return json.Marshal(formFields(f))
+}
+
+func (f *Form) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '"' {
+		*f = Form{}
+		return json.Unmarshal(data, &f.Name)
+	}
+	var v formFields
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = Form(v)
+	return nil
+}
+
+// FormNames возвращает имена форм в порядке описания.
+func FormNames(forms []Form) []string {
+	names := make([]string, len(forms))
+	for i, f := range forms {
+		names[i] = f.Name
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFormJSON(t *testing.T) {
+	var forms []Form
+	in := `["ФормаСписка", {"name": "ФормаЭлемента", "elements": [{"name": "Контрагент", "type": "InputField",
+		"dataPath": "Объект.Контрагент", "handlers": [{"event": "ПриИзменении", "procedure": "КонтрагентПриИзменении"}]}]},
+		{"name": "ФормаВыбора"}]`
+	if err := json.Unmarshal([]byte(in), &forms); err != nil {
+		t.Fatal(err)
+	}
+	if got := FormNames(forms); !reflect.DeepEqual(got, []string{"ФормаСписка", "ФормаЭлемента", "ФормаВыбора"}) {
+		t.Fatalf("names: %v", got)
+	}
+	if forms[0].Detailed() || !forms[1].Detailed() || forms[2].Detailed() {
+		t.Errorf("Detailed: %v %v %v", forms[0].Detailed(), forms[1].Detailed(), forms[2].Detailed())
+	}
+	if e := forms[1].Elements[0]; e.DataPath != "Объект.Контрагент" || len(e.Handlers) != 1 || e.Handlers[0].Procedure != "КонтрагентПриИзменении" {
+		t.Errorf("element: %+v", e)
+	}
+
+	// Форма без подробностей пишется строкой, как в прежних снимках; подробная — объектом и читается обратно без потерь.
+	data, err := json.Marshal(forms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if string(raw[0]) != `"ФормаСписка"` || string(raw[2]) != `"ФормаВыбора"` || raw[1][0] != '{' {
+		t.Errorf("marshal: %s", data)
+	}
+	var back []Form
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, forms) {
+		t.Errorf("round trip: %+v, want %+v", back, forms)
+	}
+
+	var f Form
+	if err := json.Unmarshal([]byte(`42`), &f); err == nil {
+		t.Error("number: want error")
+	}
+}
@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Module — модуль объекта. В objects.json модуль задаётся строкой (только имя: ObjectModule, ManagerModule, …,
+// как в прежних снимках) или объектом с текстом модуля (source) или путём к файлу модуля в выгрузке (path).
+// Модуль без текста и пути пишется строкой.
+type Module struct {
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`   // путь к файлу .bsl относительно каталога снимка
+	Source string `json:"source,omitempty"` // текст модуля; при импорте из каталога читается из Path, если пуст
+}
+
+// moduleFields — Module без своих методов JSON.
+type moduleFields Module
+
+func (m Module) MarshalJSON() ([]byte, error) {
+	if m.Path == "" && m.Source == "" {
+		return json.Marshal(m.Name)
+	}
+	return json.Marshal(moduleFields(m))
+}
+
+func (m *Module) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '"' {
+		*m = Module{}
+		return json.Unmarshal(data, &m.Name)
+	}
+	var v moduleFields
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*m = Module(v)
+	return nil
+}
+
+// ModuleNames возвращает имена модулей в порядке описания.
+func ModuleNames(modules []Module) []string {
+	names := make([]string, len(modules))
+	for i, m := range modules {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// loadModuleSources читает тексты модулей объекта по Path относительно каталога rootDir. Файлы вне каталога
+// и нечитаемые пропускаются: у модуля остаётся только путь, проверка при импорте сообщит об этом.
+func loadModuleSources(rootDir string, o *Object) {
+	for i := range o.Modules {
+		m := &o.Modules[i]
+		if m.Path == "" || m.Source != "" {
+			continue
+		}
+		path := filepath.Join(rootDir, filepath.FromSlash(m.Path))
+		if err := ensureInsideRoot(rootDir, path); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		m.Source = strings.TrimPrefix(string(data), "\uFEFF") // выгрузка конфигуратора пишет .bsl с BOM
+	}
+}
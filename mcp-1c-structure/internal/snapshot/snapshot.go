@@ -1,5 +1,10 @@
 package snapshot
 
+// IndexVersion — версия формата снимка, которую понимает этот бинарник (meta.indexVersion).
+// Снимок с большей версией содержит данные, которые импорт потеряет; 0 в meta означает «не указана».
+// 2 — prop у связей, subsystems и content у подсистем, kind реквизитов и register у регистров, формы и модули объектами.
+const IndexVersion = 2
+
 type Meta struct {
 	Version       string `json:"version"`
 	ConfigName    string `json:"configName"`
@@ -10,10 +15,18 @@ type Meta struct {
 	IndexVersion  int    `json:"indexVersion"`
 }
 
+// Виды полей регистра в Prop.Kind. У реквизитов прочих объектов Kind пустой.
+const (
+	PropDimension = "dimension" // измерение
+	PropResource  = "resource"  // ресурс
+	PropAttribute = "attribute" // реквизит регистра
+)
+
 type Prop struct {
 	Name    string `json:"name"`
 	Type    string `json:"type"`
 	Synonym string `json:"synonym"`
+	Kind    string `json:"kind,omitempty"` // у регистров: PropDimension, PropResource или PropAttribute
 }
 
 type TabularSection struct {
@@ -28,13 +41,31 @@ type Object struct {
 	Synonym         string           `json:"synonym"`
 	Props           []Prop           `json:"props"`
 	TabularSections []TabularSection `json:"tabularSections"`
-	Forms           []string         `json:"forms"`
-	Modules         []string         `json:"modules"`
+	Forms           []Form           `json:"forms"`                // имена форм или формы целиком (Form)
+	Modules         []Module         `json:"modules"`              // имена модулей или модули с текстом (Module)
+	Subsystems      []string         `json:"subsystems,omitempty"` // у подсистемы: id вложенных подсистем
+	Content         []string         `json:"content,omitempty"`    // у подсистемы: id входящих в неё объектов
+	Register        *Register        `json:"register,omitempty"`   // у регистров: периодичность, режим записи, вид
 	Description     string           `json:"description"`
 }
 
+// Register — свойства регистра сведений, накопления, бухгалтерии или расчёта; значения — как в выгрузке конфигурации.
+// Измерения, ресурсы и реквизиты регистра лежат в Object.Props с Prop.Kind.
+type Register struct {
+	Periodicity     string `json:"periodicity,omitempty"`     // регистр сведений: Nonperiodical, Second, Day, Month, Quarter, Year, RecorderPosition
+	WriteMode       string `json:"writeMode,omitempty"`       // регистр сведений: Independent или RecorderSubordinate
+	RegisterType    string `json:"registerType,omitempty"`    // регистр накопления: Balance (остатки) или Turnovers (обороты)
+	ChartOfAccounts string `json:"chartOfAccounts,omitempty"` // регистр бухгалтерии: id плана счетов
+	Correspondence  bool   `json:"correspondence,omitempty"`  // регистр бухгалтерии: поддерживает корреспонденцию
+}
+
+// Relation — связь между объектами. Prop — путь реквизита, из типа которого выведена связь
+// (Контрагент или Товары.Номенклатура для колонки табличной части), у связей из кода модулей — место обращения
+// (ObjectModule.ПередЗаписью, у вызова — ObjectModule.ПередЗаписью->ОбщийМодуль.Метод); у связей из relations.json
+// обычно пусто.
 type Relation struct {
 	From string `json:"from"`
 	To   string `json:"to"`
 	Kind string `json:"kind"`
+	Prop string `json:"prop,omitempty"`
 }
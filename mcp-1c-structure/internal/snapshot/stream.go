@@ -0,0 +1,302 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Source — снимок, читаемый потоком: Import сначала перебирает объекты, затем связи, затем запрашивает Meta.
+// Каждый метод вызывается не более одного раза; объект или связь передаётся в yield и после возврата не хранится,
+// поэтому память не растёт с размером снимка.
+type Source interface {
+	Objects(yield func(Object) error) error
+	Relations(yield func(Relation) error) error
+	Meta() (Meta, error)
+}
+
+// FromSlices оборачивает уже загруженный снимок в Source.
+func FromSlices(meta Meta, objects []Object, relations []Relation) Source {
+	return &sliceSource{meta: meta, objects: objects, relations: relations}
+}
+
+type sliceSource struct {
+	meta      Meta
+	objects   []Object
+	relations []Relation
+}
+
+func (s *sliceSource) Objects(yield func(Object) error) error {
+	for i := range s.objects {
+		if err := yield(s.objects[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sliceSource) Relations(yield func(Relation) error) error {
+	for i := range s.relations {
+		if err := yield(s.relations[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sliceSource) Meta() (Meta, error) {
+	return s.meta, nil
+}
+
+// OpenDir возвращает Source каталога снимка: objects.json и relations.json декодируются по одному элементу,
+// тексты модулей с path читаются из файлов каталога.
+func OpenDir(rootDir string) (Source, error) {
+	rootDir = filepath.Clean(rootDir)
+	if rootDir == "" || rootDir == "." {
+		abs, err := filepath.Abs(rootDir)
+		if err != nil {
+			return nil, err
+		}
+		rootDir = abs
+	}
+	for _, name := range []string{"meta.json", "objects.json", "relations.json"} {
+		if err := ensureInsideRoot(rootDir, filepath.Join(rootDir, name)); err != nil {
+			return nil, err
+		}
+	}
+	return dirSource(rootDir), nil
+}
+
+type dirSource string
+
+func (d dirSource) Objects(yield func(Object) error) error {
+	return streamFile(filepath.Join(string(d), "objects.json"), func(o Object) error {
+		loadModuleSources(string(d), &o)
+		return yield(o)
+	})
+}
+
+func (d dirSource) Relations(yield func(Relation) error) error {
+	return streamFile(filepath.Join(string(d), "relations.json"), yield)
+}
+
+func (d dirSource) Meta() (Meta, error) {
+	return LoadMeta(string(d))
+}
+
+func streamFile[T any](path string, yield func(T) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := decodeArray(json.NewDecoder(f), yield); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// decodeArray читает JSON-массив из dec поэлементно; null читается как пустой массив.
+func decodeArray[T any](dec *json.Decoder, yield func(T) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("expected JSON array, got %v", tok)
+	}
+	for dec.More() {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if err := yield(v); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token() // ]
+	return err
+}
+
+// PayloadReader читает тело POST /import ({"configId", "meta", "objects", "relations"}) потоком.
+// configId должен стоять до objects, relations — после objects; meta может быть в любом месте.
+type PayloadReader struct {
+	dec      *json.Decoder
+	configID string
+	meta     Meta
+	started  bool
+	pending  string // ключ верхнего уровня, на котором остановилось чтение
+	objects  bool   // objects уже прочитаны
+	done     bool
+	err      error // первая ошибка разбора тела
+}
+
+func NewPayloadReader(r io.Reader) *PayloadReader {
+	return &PayloadReader{dec: json.NewDecoder(r)}
+}
+
+// ConfigID читает тело до objects и возвращает configId, если он встретился раньше.
+func (p *PayloadReader) ConfigID() (string, error) {
+	if err := p.advance("objects"); err != nil {
+		return "", err
+	}
+	return p.configID, nil
+}
+
+// Err возвращает ошибку разбора тела, если импорт прервался из-за неё, а не из-за хранилища.
+func (p *PayloadReader) Err() error {
+	return p.err
+}
+
+func (p *PayloadReader) Objects(yield func(Object) error) error {
+	if err := p.advance("objects"); err != nil {
+		return err
+	}
+	if p.pending != "objects" {
+		return nil
+	}
+	p.pending = ""
+	p.objects = true
+	return p.decodeArray(func() error {
+		var o Object
+		if err := p.dec.Decode(&o); err != nil {
+			return p.fail(err)
+		}
+		return yield(o)
+	})
+}
+
+func (p *PayloadReader) Relations(yield func(Relation) error) error {
+	if err := p.advance("relations"); err != nil {
+		return err
+	}
+	if p.pending != "relations" {
+		return nil
+	}
+	p.pending = ""
+	return p.decodeArray(func() error {
+		var r Relation
+		if err := p.dec.Decode(&r); err != nil {
+			return p.fail(err)
+		}
+		return yield(r)
+	})
+}
+
+// Meta дочитывает тело до конца и возвращает meta.
+func (p *PayloadReader) Meta() (Meta, error) {
+	if err := p.advance(""); err != nil {
+		return Meta{}, err
+	}
+	return p.meta, nil
+}
+
+func (p *PayloadReader) fail(err error) error {
+	if p.err == nil {
+		p.err = err
+	}
+	return err
+}
+
+// decodeArray читает массив, как функция decodeArray, но запоминает ошибки разбора в p.err.
+func (p *PayloadReader) decodeArray(next func() error) error {
+	tok, err := p.dec.Token()
+	if err != nil {
+		return p.fail(err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return p.fail(fmt.Errorf("expected JSON array, got %v", tok))
+	}
+	for p.dec.More() {
+		if err := next(); err != nil {
+			return err
+		}
+	}
+	if _, err := p.dec.Token(); err != nil { // ]
+		return p.fail(err)
+	}
+	return nil
+}
+
+// advance читает ключи верхнего уровня до ключа want (или до конца объекта при want == ""),
+// разбирая по пути configId и meta.
+func (p *PayloadReader) advance(want string) error {
+	if err := p.scan(want); err != nil {
+		return p.fail(err)
+	}
+	return nil
+}
+
+func (p *PayloadReader) scan(want string) error {
+	if !p.started {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '{' {
+			return errors.New("expected JSON object")
+		}
+		p.started = true
+	}
+	for {
+		if p.pending != "" {
+			if p.pending == want {
+				return nil
+			}
+			if p.pending == "relations" && want == "objects" {
+				return errors.New("relations must follow objects in the request body")
+			}
+			// Массив, который Import не запросил, пропускается целиком.
+			var skip json.RawMessage
+			if err := p.dec.Decode(&skip); err != nil {
+				return err
+			}
+			p.pending = ""
+		}
+		if p.done {
+			return nil
+		}
+		if !p.dec.More() {
+			if _, err := p.dec.Token(); err != nil { // }
+				return err
+			}
+			p.done = true
+			return nil
+		}
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		switch key {
+		case "configId":
+			// Импорт уже идёт в конфигурацию, выбранную до objects: поздний configId нельзя молча проигнорировать.
+			if p.objects {
+				return errors.New("configId must precede objects in the request body")
+			}
+			if err := p.dec.Decode(&p.configID); err != nil {
+				return fmt.Errorf("configId: %w", err)
+			}
+		case "meta":
+			if err := p.dec.Decode(&p.meta); err != nil {
+				return fmt.Errorf("meta: %w", err)
+			}
+		case "objects", "relations":
+			p.pending = key
+		default:
+			var skip json.RawMessage
+			if err := p.dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+}
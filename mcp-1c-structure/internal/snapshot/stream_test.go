@@ -0,0 +1,114 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// collect читает Source целиком в порядке Import: объекты, связи, meta.
+func collect(src Source) (Meta, []Object, []Relation, error) {
+	var objects []Object
+	var relations []Relation
+	if err := src.Objects(func(o Object) error { objects = append(objects, o); return nil }); err != nil {
+		return Meta{}, nil, nil, err
+	}
+	if err := src.Relations(func(r Relation) error { relations = append(relations, r); return nil }); err != nil {
+		return Meta{}, nil, nil, err
+	}
+	meta, err := src.Meta()
+	return meta, objects, relations, err
+}
+
+func writeSnapshot(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestOpenDir(t *testing.T) {
+	dir := writeSnapshot(t, map[string]string{
+		"meta.json":      `{"version": "1.0", "configName": "Тест", "objectCount": 2}`,
+		"objects.json":   `[{"id": "cat.А", "type": "Catalog", "name": "А"}, {"id": "cat.Б", "type": "Catalog", "name": "Б"}]`,
+		"relations.json": `null`,
+	})
+	src, err := OpenDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, objects, relations, err := collect(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.ConfigName != "Тест" || len(objects) != 2 || objects[1].ID != "cat.Б" || len(relations) != 0 {
+		t.Errorf("got meta %+v, objects %+v, relations %+v", meta, objects, relations)
+	}
+
+	// Ошибка yield прерывает чтение и возвращается обёрнутой.
+	stop := errors.New("stop")
+	n := 0
+	if err := src.Objects(func(Object) error { n++; return stop }); !errors.Is(err, stop) || n != 1 {
+		t.Errorf("yield error: got %v after %d objects", err, n)
+	}
+
+	bad := writeSnapshot(t, map[string]string{"meta.json": `{}`, "objects.json": `{"id": "cat.А"}`, "relations.json": `[]`})
+	src, err = OpenDir(bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Objects(func(Object) error { return nil }); err == nil || !strings.Contains(err.Error(), "objects.json") {
+		t.Errorf("objects.json not an array: want error naming the file, got %v", err)
+	}
+}
+
+func TestPayloadReader(t *testing.T) {
+	body := `{"meta": {"configName": "Тест"}, "configId": "erp", "comment": [1, {"a": 2}],
+		"objects": [{"id": "cat.А", "type": "Catalog", "name": "А"}],
+		"relations": [{"from": "cat.А", "to": "cat.А", "kind": "reference"}]}`
+	p := NewPayloadReader(strings.NewReader(body))
+	configID, err := p.ConfigID()
+	if err != nil || configID != "erp" {
+		t.Fatalf("ConfigID: %q, %v", configID, err)
+	}
+	meta, objects, relations, err := collect(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Relation{{From: "cat.А", To: "cat.А", Kind: "reference"}}
+	if meta.ConfigName != "Тест" || len(objects) != 1 || !reflect.DeepEqual(relations, want) || p.Err() != nil {
+		t.Errorf("got meta %+v, objects %+v, relations %+v, err %v", meta, objects, relations, p.Err())
+	}
+
+	// meta после массивов дочитывается в Meta; без configId ConfigID возвращает пустую строку.
+	p = NewPayloadReader(strings.NewReader(`{"objects": [], "relations": [], "meta": {"configName": "Позже"}}`))
+	if configID, err := p.ConfigID(); err != nil || configID != "" {
+		t.Errorf("ConfigID without configId: %q, %v", configID, err)
+	}
+	if meta, _, _, err := collect(p); err != nil || meta.ConfigName != "Позже" {
+		t.Errorf("late meta: %+v, %v", meta, err)
+	}
+
+	failures := []struct {
+		name, body, want string
+	}{
+		{"not an object", `[]`, "expected JSON object"},
+		{"relations before objects", `{"relations": [], "objects": []}`, "relations must follow objects"},
+		{"late configId", `{"objects": [], "configId": "erp"}`, "configId must precede objects"},
+		{"bad object", `{"objects": [{"id": 1}]}`, "cannot unmarshal"},
+	}
+	for _, tt := range failures {
+		p := NewPayloadReader(strings.NewReader(tt.body))
+		_, _, _, err := collect(p)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || p.Err() != err {
+			t.Errorf("%s: want error %q, got %v (Err %v)", tt.name, tt.want, err, p.Err())
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// WriteDir записывает снимок src в каталог dir (meta.json, objects.json, relations.json), создавая каталог;
+// src читается так же, как при импорте: объекты, связи, meta. Тексты модулей выносятся в файлы
+// modules/<Тип.Имя>/<Модуль>.bsl, у модуля в objects.json остаётся path. objectCount в meta — число записанных объектов.
+func WriteDir(dir string, src Source) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	count := 0
+	err := writeArray(filepath.Join(dir, "objects.json"), func(add func(any) error) error {
+		return src.Objects(func(o Object) error {
+			for i := range o.Modules {
+				m := &o.Modules[i]
+				if m.Source == "" {
+					continue
+				}
+				rel := filepath.Join("modules", o.Type+"."+o.Name, m.Name+".bsl")
+				if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0o755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(filepath.Join(dir, rel), []byte(m.Source), 0o644); err != nil {
+					return err
+				}
+				m.Path, m.Source = filepath.ToSlash(rel), ""
+			}
+			count++
+			return add(o)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	err = writeArray(filepath.Join(dir, "relations.json"), func(add func(any) error) error {
+		return src.Relations(func(r Relation) error { return add(r) })
+	})
+	if err != nil {
+		return err
+	}
+	meta, err := src.Meta()
+	if err != nil {
+		return err
+	}
+	meta.ObjectCount = count
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "meta.json"), append(data, '\n'), 0o644)
+}
+
+// writeArray пишет JSON-массив в файл path по одному элементу на строку; элементы передаёт fill.
+func writeArray(path string, fill func(add func(any) error) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	w.WriteString("[")
+	n := 0
+	err = fill(func(v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			w.WriteString(",")
+		}
+		n++
+		w.WriteString("\n  ")
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteString("\n]\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,38 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+	"github.com/ser/mcp-1c-structure/internal/store/postgres"
+	"github.com/ser/mcp-1c-structure/internal/store/sqlite"
+)
+
+// Open открывает хранилище, выбранное config.Backend(). migrate — config.MigrateApply или config.MigrateCheck:
+// применить встроенные миграции схемы или только проверить её версию (для memory не используется).
+func Open(migrate string) (store.Store, error) {
+	if migrate != config.MigrateApply && migrate != config.MigrateCheck {
+		return nil, fmt.Errorf("unknown migrate mode %q (expected %s or %s)", migrate, config.MigrateApply, config.MigrateCheck)
+	}
+	switch b := config.Backend(); b {
+	case config.BackendPostgres:
+		dbURL := config.DatabaseURL()
+		if dbURL == "" {
+			return nil, errors.New("MCP_1C_STRUCTURE_DATABASE_URL or POSTGRES_DSN required for postgres backend")
+		}
+		return postgres.New(dbURL, migrate)
+	case config.BackendSQLite:
+		return sqlite.New(config.DatabaseURL(), migrate)
+	case config.BackendMemory:
+		configID := config.ConfigID()
+		if configID == "" {
+			configID = store.DefaultConfigID
+		}
+		return memory.New(configID, config.SnapshotDir())
+	default:
+		return nil, fmt.Errorf("unknown backend %q (expected %s, %s or %s)", b, config.BackendPostgres, config.BackendSQLite, config.BackendMemory)
+	}
+}
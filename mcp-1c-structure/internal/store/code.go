@@ -0,0 +1,167 @@
+package store
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/bsl"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// Ограничения SearchCode.
+const (
+	DefaultCodeContext = 2
+	MaxCodeContext     = 10
+	DefaultCodeMatches = 50
+	MaxCodeMatches     = 500
+)
+
+// ModuleSource — текст модуля объекта. Тексты хранятся отдельно от объектов (objects и ревизии держат только
+// имя и путь модуля) и только для текущего снимка: история ревизий текстов не хранит.
+type ModuleSource struct {
+	ObjectID   string // id объекта, как он хранится
+	ObjectType string
+	Module     string
+	Path       string
+	Source     string
+}
+
+// SourcesOf возвращает модули объекта с текстом в порядке описания.
+func SourcesOf(o *snapshot.Object) []ModuleSource {
+	var out []ModuleSource
+	for _, m := range o.Modules {
+		if m.Source != "" {
+			out = append(out, ModuleSource{ObjectID: o.ID, ObjectType: o.Type, Module: m.Name, Path: m.Path, Source: m.Source})
+		}
+	}
+	return out
+}
+
+// WithoutSources возвращает объект, у модулей которого нет текста; срез модулей o не меняется.
+func WithoutSources(o snapshot.Object) snapshot.Object {
+	if o.Modules == nil {
+		return o
+	}
+	modules := make([]snapshot.Module, len(o.Modules))
+	for i, m := range o.Modules {
+		modules[i] = snapshot.Module{Name: m.Name, Path: m.Path}
+	}
+	o.Modules = modules
+	return o
+}
+
+// CodeQuery — параметры SearchCode. Пустые фильтры не ограничивают выборку; ObjectType и Module сравниваются
+// без учёта регистра. ObjectID — id, под которым объект хранится (его возвращает GetObject).
+type CodeQuery struct {
+	Query         string
+	Regex         bool // Query — регулярное выражение (синтаксис RE2), иначе подстрока
+	CaseSensitive bool
+	ObjectType    string
+	ObjectID      string
+	Module        string
+	Context       int // строк до и после совпадения; 0 — DefaultCodeContext, отрицательное — без контекста
+	Limit         int // 0 — DefaultCodeMatches, не больше MaxCodeMatches
+}
+
+// Normalize подставляет значения по умолчанию и ограничения.
+func (q CodeQuery) Normalize() CodeQuery {
+	switch {
+	case q.Context == 0:
+		q.Context = DefaultCodeContext
+	case q.Context < 0:
+		q.Context = 0
+	case q.Context > MaxCodeContext:
+		q.Context = MaxCodeContext
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultCodeMatches
+	}
+	if q.Limit > MaxCodeMatches {
+		q.Limit = MaxCodeMatches
+	}
+	return q
+}
+
+// Accepts сообщает, подходит ли модуль под фильтры запроса (без поиска по тексту).
+func (q CodeQuery) Accepts(m *ModuleSource) bool {
+	return (q.ObjectType == "" || strings.EqualFold(m.ObjectType, q.ObjectType)) &&
+		(q.ObjectID == "" || m.ObjectID == q.ObjectID) &&
+		(q.Module == "" || strings.EqualFold(m.Module, q.Module))
+}
+
+// CodeMatch — строка модуля, в которой нашлось совпадение, с номером (с 1) и соседними строками.
+type CodeMatch struct {
+	ObjectID   string   `json:"objectId"`
+	ObjectType string   `json:"objectType"`
+	Module     string   `json:"module"`
+	Line       int      `json:"line"`
+	Text       string   `json:"text"`
+	Before     []string `json:"before"`
+	After      []string `json:"after"`
+}
+
+// CodeSearch ищет строки в текстах модулей. Backend отдают ему модули по возрастанию ObjectID, затем Module,
+// поэтому результаты совпадают для всех backend.
+type CodeSearch struct {
+	q       CodeQuery
+	match   func(string) bool
+	Matches []CodeMatch
+	More    bool // совпадений больше q.Limit, Matches обрезан
+}
+
+// NewCodeSearch проверяет запрос и готовит поиск; ошибка — пустой запрос или неверное регулярное выражение.
+func NewCodeSearch(q CodeQuery) (*CodeSearch, error) {
+	q = q.Normalize()
+	if q.Query == "" {
+		return nil, errors.New("empty code search query")
+	}
+	s := &CodeSearch{q: q, Matches: []CodeMatch{}}
+	switch {
+	case q.Regex:
+		expr := q.Query
+		if !q.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		s.match = re.MatchString
+	case q.CaseSensitive:
+		s.match = func(line string) bool { return strings.Contains(line, q.Query) }
+	default:
+		needle := strings.ToLower(q.Query)
+		s.match = func(line string) bool { return strings.Contains(strings.ToLower(line), needle) }
+	}
+	return s, nil
+}
+
+// Query возвращает запрос с подставленными значениями по умолчанию.
+func (s *CodeSearch) Query() CodeQuery {
+	return s.q
+}
+
+// Module ищет совпадения в модуле m, если он подходит под фильтры, и сообщает, продолжать ли поиск:
+// false — набрано больше q.Limit совпадений.
+func (s *CodeSearch) Module(m *ModuleSource) bool {
+	if !s.q.Accepts(m) {
+		return true
+	}
+	lines := bsl.Lines(m.Source)
+	for i, line := range lines {
+		if !s.match(line) {
+			continue
+		}
+		if len(s.Matches) == s.q.Limit {
+			s.More = true
+			return false
+		}
+		from, to := max(0, i-s.q.Context), min(len(lines), i+1+s.q.Context)
+		s.Matches = append(s.Matches, CodeMatch{
+			ObjectID: m.ObjectID, ObjectType: m.ObjectType, Module: m.Module, Line: i + 1, Text: line,
+			Before: append([]string{}, lines[from:i]...), After: append([]string{}, lines[i+1:to]...),
+		})
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"github.com/ser/mcp-1c-structure/internal/bsl"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// Виды связей, которые Import выводит из текстов модулей (bsl.Refs). Prop такой связи — место в коде объекта-источника:
+// Модуль.Метод или только Модуль для кода вне методов; у вызова к нему добавляется вызываемый метод:
+// Модуль.Метод->ОбщийМодуль.Метод.
+const (
+	KindCall          = bsl.RefCall    // вызов метода общего модуля
+	KindManagerAccess = bsl.RefManager // обращение к менеджеру: Справочники.X, Документы.X
+	KindQueryRead     = bsl.RefQuery   // таблица объекта в тексте запроса
+)
+
+// CodeKinds — виды связей из кода.
+var CodeKinds = []string{KindCall, KindManagerAccess, KindQueryRead}
+
+// IsCodeKind сообщает, выводится ли связь вида kind из текстов модулей.
+func IsCodeKind(kind string) bool {
+	return kind == KindCall || kind == KindManagerAccess || kind == KindQueryRead
+}
+
+// CodePath — место в коде: Модуль.Метод, вне методов — Модуль.
+func CodePath(module, method string) string {
+	if method == "" {
+		return module
+	}
+	return module + "." + method
+}
+
+// CodeProp — prop связи из кода для обращения r в модуле module: CodePath, у вызова — с вызываемым методом
+// через «->», как он записан в коде (ObjectModule.ПередЗаписью->ОбщийМодульКлиент.СообщитьПользователю).
+func CodeProp(module string, r *bsl.Ref) string {
+	p := CodePath(module, r.Method)
+	if r.Callee != "" {
+		p += "->" + r.Name + "." + r.Callee
+	}
+	return p
+}
+
+// CodeRelations возвращает связи из кода модуля ms к объектам, которые есть в снимке. resolve возвращает id,
+// под которым цель хранится (ResolveID по id с полным типом, CommonModule.X), — с ним связь и сохраняется.
+// Обращения объекта к самому себе (модуль документа к Документы.ЭтотДокумент) пропускаются.
+func CodeRelations(ms *ModuleSource, resolve func(id string) (string, bool)) []snapshot.Relation {
+	var out []snapshot.Relation
+	for _, r := range bsl.Refs(ms.Source) {
+		to, ok := resolve(r.Type + "." + r.Name)
+		if !ok || to == ms.ObjectID {
+			continue
+		}
+		out = append(out, snapshot.Relation{From: ms.ObjectID, To: to, Kind: r.Kind, Prop: CodeProp(ms.Module, &r)})
+	}
+	return out
+}
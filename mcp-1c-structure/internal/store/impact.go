@@ -0,0 +1,136 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// Ограничения обхода ImpactAnalysis.
+const (
+	DefaultImpactDepth = 3
+	MaxImpactDepth     = 10
+	DefaultImpactNodes = 200
+	MaxImpactNodes     = 1000
+)
+
+// ImpactQuery — параметры ImpactAnalysis.
+type ImpactQuery struct {
+	ObjectID  string
+	Direction string   // incoming (по умолчанию) — кто зависит от объекта; outgoing — от чего зависит объект
+	Kinds     []string // виды связей, по которым идёт обход; пусто — любые
+	MaxDepth  int      // число шагов от объекта; 0 — DefaultImpactDepth, не больше MaxImpactDepth
+	MaxNodes  int      // сколько объектов вернуть; 0 — DefaultImpactNodes, не больше MaxImpactNodes
+}
+
+// Normalize подставляет значения по умолчанию и ограничения. ObjectID — id, под которым объект хранится
+// (его возвращает GetObject): обход идёт по связям, концы которых приведены к хранимым id.
+func (q ImpactQuery) Normalize() ImpactQuery {
+	if q.Direction != "outgoing" {
+		q.Direction = "incoming"
+	}
+	if q.MaxDepth <= 0 {
+		q.MaxDepth = DefaultImpactDepth
+	}
+	if q.MaxDepth > MaxImpactDepth {
+		q.MaxDepth = MaxImpactDepth
+	}
+	if q.MaxNodes <= 0 {
+		q.MaxNodes = DefaultImpactNodes
+	}
+	if q.MaxNodes > MaxImpactNodes {
+		q.MaxNodes = MaxImpactNodes
+	}
+	if q.Kinds == nil {
+		q.Kinds = []string{}
+	}
+	return q
+}
+
+// ImpactNode — объект, достигнутый обходом: кратчайший путь к нему и последняя связь этого пути.
+// Из нескольких кратчайших путей выбирается наименьший при сравнении id по порядку, затем по kind и prop связи.
+type ImpactNode struct {
+	ID    string   `json:"id"`
+	Type  string   `json:"type"`
+	Name  string   `json:"name"`
+	Depth int      `json:"depth"`
+	Kind  string   `json:"kind"`
+	Prop  string   `json:"prop,omitempty"`
+	Path  []string `json:"path"` // id от исходного объекта до этого включительно
+}
+
+// LayerEdges возвращает связи, ведущие из узлов frontier в направлении обхода: для incoming — связи, где узел — to.
+// Фильтр по видам связей применяет реализация. Так backend отдают связи WalkImpact и ShortestPaths.
+type LayerEdges func(frontier []string) ([]snapshot.Relation, error)
+
+// WalkImpact обходит граф в ширину по слоям, запрашивая связи всего слоя одним вызовом edges: каждый объект
+// посещается один раз, на наименьшей глубине, поэтому циклы не зацикливают обход. Узлы без Type и Name,
+// упорядочены по глубине, затем id. Обход останавливается, когда найдено больше q.MaxNodes объектов.
+// Backend без рекурсивных запросов используют его вместо recursive CTE Postgres; результаты совпадают.
+func WalkImpact(q ImpactQuery, edges LayerEdges) ([]ImpactNode, bool, error) {
+	visited := map[string]bool{q.ObjectID: true}
+	paths := map[string][]string{q.ObjectID: {q.ObjectID}}
+	frontier := []string{q.ObjectID}
+	var out []ImpactNode
+	for depth := 1; depth <= q.MaxDepth && len(frontier) > 0 && len(out) <= q.MaxNodes; depth++ {
+		rels, err := edges(frontier)
+		if err != nil {
+			return nil, false, err
+		}
+		byNode := make(map[string][]snapshot.Relation)
+		for _, r := range rels {
+			from := r.To
+			if q.Direction == "outgoing" {
+				from = r.From
+			}
+			byNode[from] = append(byNode[from], r)
+		}
+		var layer []ImpactNode
+		for _, id := range frontier {
+			list := byNode[id]
+			sort.Slice(list, func(i, j int) bool {
+				if list[i].Kind != list[j].Kind {
+					return list[i].Kind < list[j].Kind
+				}
+				return list[i].Prop < list[j].Prop
+			})
+			for _, r := range list {
+				next := r.From
+				if q.Direction == "outgoing" {
+					next = r.To
+				}
+				if visited[next] {
+					continue
+				}
+				visited[next] = true
+				path := append(append([]string{}, paths[id]...), next)
+				paths[next] = path
+				layer = append(layer, ImpactNode{ID: next, Depth: depth, Kind: r.Kind, Prop: r.Prop, Path: path})
+			}
+		}
+		// Следующий слой раскрывается в порядке путей: так первым достаётся наименьший путь, как в Postgres.
+		sort.Slice(layer, func(i, j int) bool { return lessPath(layer[i].Path, layer[j].Path) })
+		frontier = frontier[:0]
+		for _, n := range layer {
+			frontier = append(frontier, n.ID)
+		}
+		sort.Slice(layer, func(i, j int) bool { return layer[i].ID < layer[j].ID })
+		out = append(out, layer...)
+	}
+	if len(out) > q.MaxNodes {
+		return out[:q.MaxNodes], true, nil
+	}
+	return out, false, nil
+}
+
+func lessPath(a, b []string) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+func TestApplyDeltaWithoutRevisions(t *testing.T) {
+	s, err := New("default", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ApplyDelta(context.Background(), "default", snapshot.Delta{}, store.ImportOptions{}); !errors.Is(err, store.ErrNoRevisions) {
+		t.Fatalf("want ErrNoRevisions, got %v", err)
+	}
+}
+
+// Дельта проверяется вместе с текущим снимком: связь с отсутствующим концом отбрасывается (в строгом режиме
+// дельта отклоняется), остальные добавляются с хранимыми id концов.
+func TestApplyDeltaValidates(t *testing.T) {
+	ctx := context.Background()
+	s, err := New("default", "../../../snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delta := snapshot.Delta{
+		Upserted: []snapshot.Object{{ID: "cat.Склады", Type: "Catalog", Name: "Склады"}},
+		AddRelations: []snapshot.Relation{
+			{From: "Catalog.Склады", To: "commonmodule.ОбщийМодульКлиент", Kind: "reference"},
+			{From: "cat.Склады", To: "cat.НетТакого", Kind: "reference"},
+		},
+	}
+	var verr *store.ValidationError
+	if _, err := s.ApplyDelta(ctx, "default", delta, store.ImportOptions{Strict: true}); !errors.As(err, &verr) {
+		t.Fatalf("strict: want *ValidationError, got %v", err)
+	}
+	if revs, _ := s.ListRevisions(ctx, "default"); len(revs) != 1 {
+		t.Fatalf("strict: rejected delta must not add a revision, got %d", len(revs))
+	}
+	res, err := s.ApplyDelta(ctx, "default", delta, store.ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.Validation.Counts(); n != 1 || res.Validation.Errors[0].Code != store.IssueDanglingRelation {
+		t.Errorf("want one dangling_relation error, got %+v", res.Validation.Errors)
+	}
+	_, out, err := s.FindReferences(ctx, "default", "cat.Склады", "outgoing", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].From != "cat.Склады" || out[0].To != "CommonModule.ОбщийМодульКлиент" {
+		t.Errorf("want cat.Склады -> CommonModule.ОбщийМодульКлиент, got %+v", out)
+	}
+}
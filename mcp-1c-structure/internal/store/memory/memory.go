@@ -0,0 +1,723 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ser/mcp-1c-structure/internal/search"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// memoryStore держит снимки конфигураций в памяти процесса. Данные не переживают перезапуск:
+// источником служит каталог снимка или вызов Import.
+type memoryStore struct {
+	mu      sync.RWMutex
+	configs map[string]*dataset
+	history map[string][]store.RevisionData // ревизии конфигурации по порядку, последняя — текущий снимок
+}
+
+// dataset — проиндексированный снимок; после построения не изменяется, Import подменяет его целиком.
+type dataset struct {
+	meta     snapshot.Meta
+	objects  []snapshot.Object // отсортированы по name, как ORDER BY name в Postgres
+	names    []string          // LOWER(name) для objects[i]
+	synonyms []string          // LOWER(synonym) для objects[i]
+	docs     []search.Doc      // основы слов objects[i] для полнотекстового поиска
+	byID     map[string]int    // id объекта, как он хранится -> индекс в objects
+	byType   map[string][]int  // LOWER(type) -> индексы в objects
+	incoming map[string][]snapshot.Relation
+	outgoing map[string][]snapshot.Relation
+	types    []store.TypeCount
+	kept     []snapshot.Relation             // связи, прошедшие проверку целостности, в порядке импорта
+	sources  map[string][]store.ModuleSource // id объекта -> модули с текстом; в objects текстов нет
+	methods  []store.ModuleMethod            // методы модулей по возрастанию id объекта, модуля и строки
+}
+
+// New создаёт хранилище в памяти. Если dir не пуст, снимок сразу загружается из каталога в конфигурацию configID.
+func New(configID, dir string) (store.Store, error) {
+	m := &memoryStore{configs: make(map[string]*dataset), history: make(map[string][]store.RevisionData)}
+	if dir == "" {
+		return m, nil
+	}
+	src, err := snapshot.OpenDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.Import(context.Background(), configID, src, store.ImportOptions{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var emptyDataset = buildDataset(snapshot.Meta{}, nil, nil, nil, nil)
+
+// current возвращает снимок конфигурации; для незагруженной — пустой, как пустые таблицы в Postgres.
+func (m *memoryStore) current(configID string) *dataset {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if d, ok := m.configs[configID]; ok {
+		return d
+	}
+	return emptyDataset
+}
+
+func (m *memoryStore) Search(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]snapshot.Object, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	query = strings.TrimSpace(strings.ToLower(query))
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	d := m.current(configID)
+	var candidates []int
+	if typeFilter != "" {
+		candidates = d.byType[typeFilter]
+	} else {
+		candidates = make([]int, len(d.objects))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+	var list []snapshot.Object
+	total := 0
+	for _, i := range candidates {
+		if query != "" && !strings.Contains(d.names[i], query) && !strings.Contains(d.synonyms[i], query) {
+			continue
+		}
+		if total >= offset && len(list) < limit {
+			list = append(list, d.objects[i])
+		}
+		total++
+	}
+	return list, total, nil
+}
+
+// SearchFullText оценивает каждый объект по основам слов (search.Doc.Match); равные оценки идут по имени.
+func (m *memoryStore) SearchFullText(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]store.SearchHit, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	terms := search.QueryTerms(query)
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	d := m.current(configID)
+	var hits []store.SearchHit
+	for i := range d.objects {
+		if typeFilter != "" && strings.ToLower(d.objects[i].Type) != typeFilter {
+			continue
+		}
+		if score, field, ok := d.docs[i].Match(terms); ok {
+			hits = append(hits, store.SearchHit{Object: d.objects[i], Score: score, MatchedField: field})
+		}
+	}
+	// objects уже отсортированы по имени, стабильная сортировка сохраняет этот порядок при равных оценках.
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	total := len(hits)
+	if offset >= total {
+		return nil, total, nil
+	}
+	return hits[offset:min(offset+limit, total)], total, nil
+}
+
+// SearchFuzzy сравнивает триграммы имени и синонима каждого объекта с вариантами запроса.
+func (m *memoryStore) SearchFuzzy(ctx context.Context, configID string, variants []search.Variant, typeFilter string, limit, offset int) ([]store.SearchHit, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	fuzzy := search.NewFuzzy(variants)
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	d := m.current(configID)
+	var hits []store.SearchHit
+	for i := range d.objects {
+		o := &d.objects[i]
+		if typeFilter != "" && strings.ToLower(o.Type) != typeFilter {
+			continue
+		}
+		if score, field, v, ok := fuzzy.Match(o.Name, o.Synonym); ok {
+			hits = append(hits, store.SearchHit{Object: *o, Score: score, MatchedField: field, Variant: v})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	total := len(hits)
+	if offset >= total {
+		return nil, total, nil
+	}
+	return hits[offset:min(offset+limit, total)], total, nil
+}
+
+func (m *memoryStore) FindByProp(ctx context.Context, configID string, filter store.PropFilter, limit, offset int) ([]store.PropMatch, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	name := strings.ToLower(strings.TrimSpace(filter.Name))
+	typ := strings.ToLower(strings.TrimSpace(filter.Type))
+	section := strings.ToLower(strings.TrimSpace(filter.TabularSection))
+	objectType := strings.ToLower(strings.TrimSpace(filter.ObjectType))
+	kind := strings.ToLower(strings.TrimSpace(filter.Kind))
+	matches := func(p snapshot.Prop) bool {
+		return (name == "" || strings.ToLower(p.Name) == name) && (typ == "" || strings.ToLower(p.Type) == typ) &&
+			(kind == "" || p.Kind == kind)
+	}
+	d := m.current(configID)
+	var list []store.PropMatch
+	total := 0
+	add := func(o *snapshot.Object, ts string, p snapshot.Prop) {
+		if total >= offset && len(list) < limit {
+			list = append(list, store.PropMatch{ObjectID: o.ID, ObjectType: o.Type, ObjectName: o.Name, ObjectSynonym: o.Synonym, TabularSection: ts, Prop: p})
+		}
+		total++
+	}
+	for i := range d.objects {
+		o := &d.objects[i]
+		if objectType != "" && strings.ToLower(o.Type) != objectType {
+			continue
+		}
+		if section == "" {
+			for _, p := range o.Props {
+				if matches(p) {
+					add(o, "", p)
+				}
+			}
+		}
+		for _, ts := range o.TabularSections {
+			if section != "" && strings.ToLower(ts.Name) != section {
+				continue
+			}
+			for _, p := range ts.Props {
+				if matches(p) {
+					add(o, ts.Name, p)
+				}
+			}
+		}
+	}
+	return list, total, nil
+}
+
+func (m *memoryStore) GetObject(ctx context.Context, configID, id string) (snapshot.Object, bool, error) {
+	d := m.current(configID)
+	id, ok := d.resolve(id)
+	if !ok {
+		return snapshot.Object{}, false, nil
+	}
+	return d.objects[d.byID[id]], true, nil
+}
+
+// resolve возвращает id, под которым объект хранится в снимке (store.ResolveID).
+func (d *dataset) resolve(id string) (string, bool) {
+	return store.ResolveID(id, func(id string) bool {
+		_, ok := d.byID[id]
+		return ok
+	})
+}
+
+func (m *memoryStore) FindReferences(ctx context.Context, configID, id, direction, kind string, limit int) (incoming, outgoing []snapshot.Relation, err error) {
+	d := m.current(configID)
+	id, ok := d.resolve(id)
+	if !ok {
+		return nil, nil, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	wantIn := direction == "incoming" || direction == "both" || direction == ""
+	wantOut := direction == "outgoing" || direction == "both" || direction == ""
+	if wantIn {
+		incoming = filterRelations(d.incoming[id], kind, limit)
+	}
+	if wantOut {
+		outgoing = filterRelations(d.outgoing[id], kind, limit)
+	}
+	return incoming, outgoing, nil
+}
+
+func (m *memoryStore) ImpactAnalysis(ctx context.Context, configID string, q store.ImpactQuery) ([]store.ImpactNode, bool, error) {
+	q = q.Normalize()
+	d := m.current(configID)
+	index := d.incoming
+	if q.Direction == "outgoing" {
+		index = d.outgoing
+	}
+	nodes, truncated, err := store.WalkImpact(q, layerEdges(index, q.Kinds))
+	for i := range nodes {
+		if j, ok := d.byID[nodes[i].ID]; ok {
+			nodes[i].Type, nodes[i].Name = d.objects[j].Type, d.objects[j].Name
+		}
+	}
+	return nodes, truncated, err
+}
+
+func (m *memoryStore) FindPaths(ctx context.Context, configID string, q store.PathQuery) ([][]snapshot.Relation, bool, error) {
+	q = q.Normalize()
+	d := m.current(configID)
+	from, okFrom := d.resolve(q.From)
+	to, okTo := d.resolve(q.To)
+	if !okFrom || !okTo {
+		return nil, false, nil
+	}
+	q.From, q.To = from, to
+	return store.ShortestPaths(q, layerEdges(d.outgoing, q.Kinds))
+}
+
+func (m *memoryStore) RelationsAmong(ctx context.Context, configID string, ids, kinds []string) ([]snapshot.Relation, error) {
+	d := m.current(configID)
+	in := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		in[id] = true
+	}
+	edges, _ := layerEdges(d.outgoing, kinds)(ids)
+	var out []snapshot.Relation
+	for _, r := range edges {
+		if in[r.From] && in[r.To] {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Prop < b.Prop
+	})
+	return out, nil
+}
+
+// layerEdges отдаёт связи слоя обхода из индекса incoming или outgoing с фильтром по видам.
+func layerEdges(index map[string][]snapshot.Relation, kinds []string) store.LayerEdges {
+	want := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		want[k] = true
+	}
+	return func(frontier []string) ([]snapshot.Relation, error) {
+		var out []snapshot.Relation
+		for _, id := range frontier {
+			for _, r := range index[id] {
+				if len(want) == 0 || want[r.Kind] {
+					out = append(out, r)
+				}
+			}
+		}
+		return out, nil
+	}
+}
+
+func (m *memoryStore) Subsystems(ctx context.Context, configID string) ([]snapshot.Object, error) {
+	d := m.current(configID)
+	var out []snapshot.Object
+	for _, i := range d.byType[strings.ToLower(store.TypeSubsystem)] {
+		out = append(out, d.objects[i])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (m *memoryStore) ObjectModules(ctx context.Context, configID, objectID string) ([]store.ModuleSource, error) {
+	d := m.current(configID)
+	id, _ := d.resolve(objectID)
+	return d.sources[id], nil
+}
+
+func (m *memoryStore) SearchCode(ctx context.Context, configID string, q store.CodeQuery) ([]store.CodeMatch, bool, error) {
+	search, err := store.NewCodeSearch(q)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, ms := range m.current(configID).sortedSources() {
+		if !search.Module(&ms) {
+			break
+		}
+	}
+	return search.Matches, search.More, nil
+}
+
+func (m *memoryStore) FindMethods(ctx context.Context, configID string, q store.MethodQuery) ([]store.ModuleMethod, bool, error) {
+	q = q.Normalize()
+	out := []store.ModuleMethod{}
+	d := m.current(configID)
+	for i := range d.methods {
+		if !q.Accepts(&d.methods[i]) {
+			continue
+		}
+		if len(out) == q.Limit {
+			return out, true, nil
+		}
+		out = append(out, d.methods[i])
+	}
+	return out, false, nil
+}
+
+// sortedSources возвращает тексты модулей по возрастанию id объекта, затем имени модуля, как ORDER BY в SQL backend.
+func (d *dataset) sortedSources() []store.ModuleSource {
+	var out []store.ModuleSource
+	for _, list := range d.sources {
+		out = append(out, list...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ObjectID != out[j].ObjectID {
+			return out[i].ObjectID < out[j].ObjectID
+		}
+		return out[i].Module < out[j].Module
+	})
+	return out
+}
+
+func (m *memoryStore) ListTypes(ctx context.Context, configID string) ([]store.TypeCount, error) {
+	d := m.current(configID)
+	out := make([]store.TypeCount, len(d.types))
+	copy(out, d.types)
+	return out, nil
+}
+
+func (m *memoryStore) Meta(ctx context.Context, configID string) (snapshot.Meta, error) {
+	return m.current(configID).meta, nil
+}
+
+func (m *memoryStore) ListConfigs(ctx context.Context) ([]store.ConfigInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]store.ConfigInfo, 0, len(m.configs))
+	for id, d := range m.configs {
+		out = append(out, store.ConfigInfo{ID: id, Meta: d.meta})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Import заменяет снимок конфигурации configID новым целиком: читатели видят либо старый снимок, либо новый.
+// Backend держит снимок в памяти, поэтому поток из src собирается целиком до построения индекса.
+// Снимок проверяется store.Validator: связи с отсутствующим концом отбрасываются и попадают в отчёт,
+// повторяющиеся рёбра отбрасываются молча; к ним добавляются связи reference из типов реквизитов (store.PropRefs)
+// и связи из кода модулей (store.CodeRelations).
+// При opts.Strict и ошибках в отчёте снимок не заменяется.
+// Снимок также сохраняется очередной ревизией конфигурации.
+func (m *memoryStore) Import(ctx context.Context, configID string, src snapshot.Source, opts store.ImportOptions) (store.ImportResult, error) {
+	var stats store.ImportStats
+	started := time.Now()
+	v := store.NewValidator()
+	var objects []snapshot.Object
+	if err := src.Objects(func(o snapshot.Object) error {
+		v.Object(&o)
+		objects = append(objects, o)
+		return nil
+	}); err != nil {
+		return store.ImportResult{}, fmt.Errorf("objects: %w", err)
+	}
+	stats.Objects = time.Since(started)
+	phase := time.Now()
+	var relations []snapshot.Relation
+	relationCount := 0
+	if err := src.Relations(func(r snapshot.Relation) error {
+		if v.Relation(&r) {
+			relations = append(relations, r)
+		}
+		relationCount++
+		return nil
+	}); err != nil {
+		return store.ImportResult{}, fmt.Errorf("relations: %w", err)
+	}
+	for i := range objects {
+		for _, r := range store.PropRefs(&objects[i]) {
+			to, ok := v.Resolve(r.To)
+			if !ok {
+				v.UnresolvedPropRef(&r.Relation)
+				continue
+			}
+			r.To = to
+			relations = append(relations, r.Relation)
+		}
+	}
+	stats.Relations = time.Since(phase)
+	phase = time.Now()
+	meta, err := src.Meta()
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("meta: %w", err)
+	}
+	v.Meta(meta)
+	report := v.Report()
+	d := buildDataset(meta, objects, relations, nil, nil)
+	if opts.Strict && report.HasErrors() {
+		return store.ImportResult{ObjectCount: len(d.objects), RelationCount: relationCount, Validation: report}, &store.ValidationError{Report: report}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rev := store.RevisionData{
+		Revision:  store.Revision{Number: len(m.history[configID]) + 1, Meta: d.meta, ImportedAt: time.Now().UTC()},
+		Objects:   d.objects,
+		Relations: d.kept,
+	}
+	m.history[configID] = append(m.history[configID], rev)
+	m.configs[configID] = d
+	stats.Finalize = time.Since(phase)
+	stats.Total = time.Since(started)
+	return store.ImportResult{Revision: rev.Number, Meta: d.meta, ObjectCount: len(d.objects), RelationCount: relationCount, Stats: stats, Validation: report}, nil
+}
+
+// ApplyDelta применяет дельту к текущему снимку конфигурации configID и сохраняет результат новой ревизией.
+// Удалённые объекты уносят свои связи; добавленные связи попадают в индекс, только если оба конца есть после дельты.
+func (m *memoryStore) ApplyDelta(ctx context.Context, configID string, delta snapshot.Delta, opts store.ImportOptions) (store.ImportResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := m.history[configID]
+	if len(list) == 0 {
+		return store.ImportResult{}, fmt.Errorf("configuration %q has %w", configID, store.ErrNoRevisions)
+	}
+	prev := list[len(list)-1]
+
+	// Объект дельты заменяет и удаляет объект с id в любом из написаний (store.IDVariants), как в SQL backend.
+	replaced := make(map[string]bool)
+	for _, id := range delta.Deleted {
+		for _, v := range store.IDVariants(id) {
+			replaced[v] = true
+		}
+	}
+	deleted := make(map[string]bool, len(replaced))
+	for id := range replaced {
+		deleted[id] = true
+	}
+	for i := range delta.Upserted {
+		for _, v := range store.IDVariants(delta.Upserted[i].ID) {
+			replaced[v] = true
+		}
+	}
+	v := store.NewValidator()
+	var objects []snapshot.Object
+	for _, o := range prev.Objects {
+		if !replaced[o.ID] {
+			v.Known(o.ID, o.Type, o.Register)
+			objects = append(objects, o)
+		}
+	}
+	added := v.Delta(&delta)
+	report := v.Report()
+	if opts.Strict && report.HasErrors() {
+		return store.ImportResult{Validation: report}, &store.ValidationError{Report: report}
+	}
+	objects = append(objects, delta.Upserted...)
+	// Ревизии хранят объекты без текстов модулей: тексты незатронутых объектов переходят из текущего снимка.
+	sources := make(map[string][]store.ModuleSource)
+	for id, list := range m.configs[configID].sources {
+		if !replaced[id] {
+			sources[id] = list
+		}
+	}
+
+	removed := make(map[snapshot.Relation]bool, len(delta.RemoveRelations))
+	for _, r := range delta.RemoveRelations {
+		removed[r] = true
+	}
+	// Связи из типов реквизитов пересчитываются по всем объектам: ссылки на отсутствующие цели отбросит buildDataset.
+	// Связи из кода — только для заменённых объектов (их выведет buildDataset), как в SQL backend.
+	var relations []snapshot.Relation
+	for _, r := range prev.Relations {
+		if removed[r] || (r.Prop != "" && r.Kind == store.KindReference) || deleted[r.From] || deleted[r.To] {
+			continue
+		}
+		if store.IsCodeKind(r.Kind) && replaced[r.From] {
+			continue
+		}
+		relations = append(relations, r)
+	}
+	relations = append(relations, added...)
+	for i := range objects {
+		for _, r := range store.PropRefs(&objects[i]) {
+			relations = append(relations, r.Relation)
+		}
+	}
+
+	d := buildDataset(delta.MergeMeta(prev.Meta), objects, relations, sources, replaced)
+	rev := store.RevisionData{
+		Revision:  store.Revision{Number: len(list) + 1, Meta: d.meta, ImportedAt: time.Now().UTC()},
+		Objects:   d.objects,
+		Relations: d.kept,
+	}
+	m.history[configID] = append(list, rev)
+	m.configs[configID] = d
+	return store.ImportResult{Revision: rev.Number, Meta: d.meta, Validation: report}, nil
+}
+
+func (m *memoryStore) ListRevisions(ctx context.Context, configID string) ([]store.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var out []store.Revision
+	for _, r := range m.history[configID] {
+		out = append(out, r.Revision)
+	}
+	return out, nil
+}
+
+func (m *memoryStore) LoadRevision(ctx context.Context, configID string, number int) (store.RevisionData, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := m.history[configID]
+	if number < 1 || number > len(list) {
+		return store.RevisionData{}, false, nil
+	}
+	return list[number-1], true, nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+// buildDataset строит снимок из objects и relations; sources — тексты модулей, уже известные до objects
+// (при дельте), их дополняют и заменяют тексты из objects. К relations добавляются связи из кода модулей
+// объектов codeOf (nil — всех объектов, store.CodeRelations).
+func buildDataset(meta snapshot.Meta, objects []snapshot.Object, relations []snapshot.Relation, sources map[string][]store.ModuleSource, codeOf map[string]bool) *dataset {
+	d := &dataset{
+		byID:     make(map[string]int),
+		byType:   make(map[string][]int),
+		incoming: make(map[string][]snapshot.Relation),
+		outgoing: make(map[string][]snapshot.Relation),
+		sources:  make(map[string][]store.ModuleSource, len(sources)),
+	}
+	for id, list := range sources {
+		d.sources[id] = list
+	}
+	// Повторный id перекрывает предыдущий, как ON CONFLICT DO UPDATE при импорте в Postgres;
+	// тексты модулей заменяются помодульно, как в таблице module_sources.
+	last := make(map[string]int)
+	for i := range objects {
+		last[objects[i].ID] = i
+		for _, ms := range store.SourcesOf(&objects[i]) {
+			d.sources[ms.ObjectID] = upsertSource(d.sources[ms.ObjectID], ms)
+		}
+	}
+	for i := range objects {
+		if last[objects[i].ID] == i {
+			d.objects = append(d.objects, store.WithoutSources(objects[i]))
+		}
+	}
+	sort.SliceStable(d.objects, func(i, j int) bool { return d.objects[i].Name < d.objects[j].Name })
+
+	d.names = make([]string, len(d.objects))
+	d.synonyms = make([]string, len(d.objects))
+	d.docs = make([]search.Doc, len(d.objects))
+	counts := make(map[string]int64)
+	for i := range d.objects {
+		o := &d.objects[i]
+		d.names[i] = strings.ToLower(o.Name)
+		d.synonyms[i] = strings.ToLower(o.Synonym)
+		d.docs[i] = search.Document(o)
+		d.byID[o.ID] = i
+		lt := strings.ToLower(o.Type)
+		d.byType[lt] = append(d.byType[lt], i)
+		counts[o.Type]++
+	}
+	for t, c := range counts {
+		d.types = append(d.types, store.TypeCount{Type: t, Count: c})
+	}
+	sort.Slice(d.types, func(i, j int) bool { return d.types[i].Type < d.types[j].Type })
+
+	for _, ms := range d.sortedSources() {
+		i, ok := d.byID[ms.ObjectID]
+		if !ok {
+			continue
+		}
+		d.methods = append(d.methods, store.ModuleMethods(&ms, d.objects[i].Name)...)
+		if codeOf == nil || codeOf[ms.ObjectID] {
+			relations = append(relations, store.CodeRelations(&ms, d.resolve)...)
+		}
+	}
+
+	// Концы связей приводятся к id, под которыми объекты хранятся; связь с отсутствующим концом отбрасывается.
+	resolved := relations[:0:0]
+	for _, r := range relations {
+		from, okFrom := d.resolve(r.From)
+		to, okTo := d.resolve(r.To)
+		if okFrom && okTo {
+			r.From, r.To = from, to
+			resolved = append(resolved, r)
+		}
+	}
+	// Связь без prop, повторяющая выведенную из реквизита (те же from, to, kind), отбрасывается, как в SQL backend.
+	derived := make(map[snapshot.Relation]bool)
+	for _, r := range resolved {
+		if r.Prop != "" {
+			derived[snapshot.Relation{From: r.From, To: r.To, Kind: r.Kind}] = true
+		}
+	}
+	seen := make(map[snapshot.Relation]bool)
+	for _, r := range resolved {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		if r.Prop == "" && derived[snapshot.Relation{From: r.From, To: r.To, Kind: r.Kind}] {
+			continue
+		}
+		d.outgoing[r.From] = append(d.outgoing[r.From], r)
+		d.incoming[r.To] = append(d.incoming[r.To], r)
+		d.kept = append(d.kept, r)
+	}
+
+	d.meta = snapshot.Meta{
+		ConfigName:    meta.ConfigName,
+		ConfigVersion: meta.ConfigVersion,
+		ExportedAt:    meta.ExportedAt,
+		Source:        meta.Source,
+		ObjectCount:   len(d.objects),
+		IndexVersion:  meta.IndexVersion,
+	}
+	return d
+}
+
+// upsertSource заменяет модуль с тем же именем или добавляет ms в конец; list не меняется.
+func upsertSource(list []store.ModuleSource, ms store.ModuleSource) []store.ModuleSource {
+	out := append([]store.ModuleSource{}, list...)
+	for i := range out {
+		if out[i].Module == ms.Module {
+			out[i] = ms
+			return out
+		}
+	}
+	return append(out, ms)
+}
+
+func filterRelations(list []snapshot.Relation, kind string, limit int) []snapshot.Relation {
+	var out []snapshot.Relation
+	for _, r := range list {
+		if kind != "" && r.Kind != kind {
+			continue
+		}
+		out = append(out, r)
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
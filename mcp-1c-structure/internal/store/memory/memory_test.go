@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := New("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+func TestFindByProp(t *testing.T) {
+	ctx := context.Background()
+	s, err := New("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := []snapshot.Object{
+		{ID: "doc.Заказ", Type: "Document", Name: "Заказ",
+			Props: []snapshot.Prop{{Name: "Контрагент", Type: "CatalogRef.Контрагенты"}, {Name: "Сумма", Type: "Number"}},
+			TabularSections: []snapshot.TabularSection{{Name: "Товары", Props: []snapshot.Prop{
+				{Name: "Номенклатура", Type: "CatalogRef.Номенклатура"},
+				{Name: "Контрагент", Type: "CatalogRef.Контрагенты"},
+			}}}},
+		{ID: "cat.Договоры", Type: "Catalog", Name: "Договоры",
+			Props: []snapshot.Prop{{Name: "Владелец", Type: "CatalogRef.Контрагенты"}, {Name: "Партнёр", Type: "CatalogRef.Контрагенты, CatalogRef.Партнеры"}}},
+		{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты"},
+		{ID: "accumulationregister.ТоварыНаСкладах", Type: "AccumulationRegister", Name: "ТоварыНаСкладах",
+			Props: []snapshot.Prop{{Name: "Номенклатура", Type: "CatalogRef.Номенклатура", Kind: snapshot.PropDimension}, {Name: "Количество", Type: "Number", Kind: snapshot.PropResource}}},
+	}
+	if _, err := s.Import(ctx, "default", snapshot.FromSlices(snapshot.Meta{}, objects, nil), store.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		filter        store.PropFilter
+		limit, offset int
+		want          []string // объект/табличная часть.реквизит
+		total         int
+	}{
+		{name: "тип без учёта регистра, составной не совпадает", filter: store.PropFilter{Type: "catalogref.контрагенты"},
+			want: []string{"cat.Договоры/.Владелец", "doc.Заказ/.Контрагент", "doc.Заказ/Товары.Контрагент"}, total: 3},
+		{name: "имя", filter: store.PropFilter{Name: "КОНТРАГЕНТ"},
+			want: []string{"doc.Заказ/.Контрагент", "doc.Заказ/Товары.Контрагент"}, total: 2},
+		{name: "табличная часть", filter: store.PropFilter{Type: "CatalogRef.Контрагенты", TabularSection: "товары"},
+			want: []string{"doc.Заказ/Товары.Контрагент"}, total: 1},
+		{name: "тип объекта", filter: store.PropFilter{Type: "CatalogRef.Контрагенты", ObjectType: "Catalog"},
+			want: []string{"cat.Договоры/.Владелец"}, total: 1},
+		{name: "страница", filter: store.PropFilter{Type: "CatalogRef.Контрагенты"}, limit: 1, offset: 1,
+			want: []string{"doc.Заказ/.Контрагент"}, total: 3},
+		{name: "вид поля регистра", filter: store.PropFilter{Kind: "Resource"},
+			want: []string{"accumulationregister.ТоварыНаСкладах/.Количество"}, total: 1},
+		{name: "вид поля и тип", filter: store.PropFilter{Type: "CatalogRef.Номенклатура", Kind: snapshot.PropDimension},
+			want: []string{"accumulationregister.ТоварыНаСкладах/.Номенклатура"}, total: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, total, err := s.FindByProp(ctx, "default", tt.filter, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range list {
+				got = append(got, m.ObjectID+"/"+m.TabularSection+"."+m.Prop.Name)
+			}
+			if total != tt.total || len(got) != len(tt.want) {
+				t.Fatalf("got %q of %d, want %q of %d", got, total, tt.want, tt.total)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %q, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/bsl"
+)
+
+// Ограничения FindMethods.
+const (
+	DefaultMethodMatches = 50
+	MaxMethodMatches     = 500
+)
+
+// Способы сравнения имени метода в FindMethods; все — без учёта регистра.
+const (
+	MethodMatchExact    = "exact"
+	MethodMatchPrefix   = "prefix"
+	MethodMatchContains = "contains"
+)
+
+// ModuleMethod — процедура или функция модуля объекта. Индекс методов строится при импорте из текстов модулей
+// (bsl.Parse) и, как тексты, есть только у текущего снимка.
+type ModuleMethod struct {
+	ObjectID   string `json:"objectId"` // id объекта, как он хранится
+	ObjectType string `json:"objectType"`
+	ObjectName string `json:"objectName"`
+	Module     string `json:"module"`
+	bsl.Method
+}
+
+// ModuleMethods разбирает текст модуля ms объекта с именем objectName.
+func ModuleMethods(ms *ModuleSource, objectName string) []ModuleMethod {
+	var out []ModuleMethod
+	for _, m := range bsl.Parse(ms.Source) {
+		out = append(out, ModuleMethod{ObjectID: ms.ObjectID, ObjectType: ms.ObjectType, ObjectName: objectName, Module: ms.Module, Method: m})
+	}
+	return out
+}
+
+// MethodJSON — описание метода для столбца method_json: SQL backend фильтруют по отдельным столбцам,
+// а остальное (параметры, директивы, область, комментарий) отдают как есть.
+func MethodJSON(m *ModuleMethod) (string, error) {
+	data, err := json.Marshal(m.Method)
+	return string(data), err
+}
+
+// MethodQuery — параметры FindMethods. Пустые фильтры не ограничивают выборку; ObjectType, ObjectName и Module
+// сравниваются без учёта регистра. ObjectID — id, под которым объект хранится (его возвращает GetObject).
+type MethodQuery struct {
+	Name       string
+	Match      string // MethodMatchExact (по умолчанию), MethodMatchPrefix или MethodMatchContains
+	ObjectType string
+	ObjectID   string
+	ObjectName string
+	Module     string
+	ExportOnly bool
+	Limit      int // 0 — DefaultMethodMatches, не больше MaxMethodMatches
+}
+
+// ValidMethodMatch сообщает, известен ли способ сравнения имени (пустой — exact).
+func ValidMethodMatch(match string) bool {
+	switch match {
+	case "", MethodMatchExact, MethodMatchPrefix, MethodMatchContains:
+		return true
+	}
+	return false
+}
+
+// Normalize подставляет значения по умолчанию и ограничения; Name и текстовые фильтры приводятся к нижнему
+// регистру, в котором их сравнивают backend.
+func (q MethodQuery) Normalize() MethodQuery {
+	q.Name = strings.ToLower(strings.TrimSpace(q.Name))
+	if q.Match == "" {
+		q.Match = MethodMatchExact
+	}
+	q.ObjectType = strings.ToLower(q.ObjectType)
+	q.ObjectName = strings.ToLower(q.ObjectName)
+	q.Module = strings.ToLower(q.Module)
+	if q.Limit <= 0 {
+		q.Limit = DefaultMethodMatches
+	}
+	if q.Limit > MaxMethodMatches {
+		q.Limit = MaxMethodMatches
+	}
+	return q
+}
+
+// Accepts сообщает, подходит ли метод под нормализованный запрос.
+func (q MethodQuery) Accepts(m *ModuleMethod) bool {
+	name := strings.ToLower(m.Name)
+	switch q.Match {
+	case MethodMatchPrefix:
+		if !strings.HasPrefix(name, q.Name) {
+			return false
+		}
+	case MethodMatchContains:
+		if !strings.Contains(name, q.Name) {
+			return false
+		}
+	default:
+		if name != q.Name {
+			return false
+		}
+	}
+	return (q.ObjectType == "" || strings.ToLower(m.ObjectType) == q.ObjectType) &&
+		(q.ObjectID == "" || m.ObjectID == q.ObjectID) &&
+		(q.ObjectName == "" || strings.ToLower(m.ObjectName) == q.ObjectName) &&
+		(q.Module == "" || strings.ToLower(m.Module) == q.Module) &&
+		(!q.ExportOnly || m.Export)
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// Ограничения FindPaths.
+const (
+	DefaultPathDepth = 6
+	MaxPathDepth     = 10
+	DefaultPaths     = 5
+	MaxPaths         = 20
+)
+
+// PathQuery — параметры FindPaths: пути по связям от From к To (по направлению связей, from -> to).
+type PathQuery struct {
+	From     string
+	To       string
+	Kinds    []string // виды связей; пусто — любые
+	MaxDepth int      // наибольшая длина пути в связях; 0 — DefaultPathDepth, не больше MaxPathDepth
+	MaxPaths int      // сколько кратчайших путей вернуть; 0 — DefaultPaths, не больше MaxPaths
+}
+
+// Normalize подставляет значения по умолчанию и ограничения. From и To backend приводят к id, под которыми
+// объекты хранятся (ResolveID): связи хранятся с такими концами.
+func (q PathQuery) Normalize() PathQuery {
+	if q.MaxDepth <= 0 {
+		q.MaxDepth = DefaultPathDepth
+	}
+	if q.MaxDepth > MaxPathDepth {
+		q.MaxDepth = MaxPathDepth
+	}
+	if q.MaxPaths <= 0 {
+		q.MaxPaths = DefaultPaths
+	}
+	if q.MaxPaths > MaxPaths {
+		q.MaxPaths = MaxPaths
+	}
+	if q.Kinds == nil {
+		q.Kinds = []string{}
+	}
+	return q
+}
+
+// ShortestPaths ищет кратчайшие пути от q.From к q.To обходом в ширину по исходящим связям (edges получает слой
+// целиком) и возвращает их в порядке сравнения id узлов, затем kind и prop связей; связи между одной парой
+// объектов разного вида или из разных реквизитов дают разные пути. truncated — путей больше q.MaxPaths.
+// Пустой результат — пути не длиннее q.MaxDepth нет.
+func ShortestPaths(q PathQuery, edges LayerEdges) (paths [][]snapshot.Relation, truncated bool, err error) {
+	if q.From == q.To {
+		return nil, false, nil
+	}
+	// preds[узел] — связи, по которым узел достигнут на своей (наименьшей) глубине.
+	preds := make(map[string][]snapshot.Relation)
+	depth := map[string]int{q.From: 0}
+	frontier := []string{q.From}
+	found := false
+	for d := 1; d <= q.MaxDepth && len(frontier) > 0 && !found; d++ {
+		rels, err := edges(frontier)
+		if err != nil {
+			return nil, false, err
+		}
+		var next []string
+		for _, r := range rels {
+			if prev, ok := depth[r.To]; ok && prev < d {
+				continue
+			}
+			if _, ok := depth[r.To]; !ok {
+				depth[r.To] = d
+				next = append(next, r.To)
+			}
+			preds[r.To] = append(preds[r.To], r)
+			if r.To == q.To {
+				found = true
+			}
+		}
+		sort.Strings(next)
+		frontier = next
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	// Связи, лежащие на кратчайших путях к q.To: обратный проход по preds.
+	onPath := make(map[string][]snapshot.Relation) // from -> связи кратчайших путей, выходящие из него
+	seen := map[string]bool{q.To: true}
+	stack := []string{q.To}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, r := range preds[id] {
+			onPath[r.From] = append(onPath[r.From], r)
+			if !seen[r.From] {
+				seen[r.From] = true
+				stack = append(stack, r.From)
+			}
+		}
+	}
+	for _, list := range onPath {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].To != list[j].To {
+				return list[i].To < list[j].To
+			}
+			if list[i].Kind != list[j].Kind {
+				return list[i].Kind < list[j].Kind
+			}
+			return list[i].Prop < list[j].Prop
+		})
+	}
+	var walk func(id string, path []snapshot.Relation) bool
+	walk = func(id string, path []snapshot.Relation) bool {
+		if id == q.To {
+			if len(paths) == q.MaxPaths {
+				truncated = true
+				return false
+			}
+			paths = append(paths, append([]snapshot.Relation{}, path...))
+			return true
+		}
+		for _, r := range onPath[id] {
+			if !walk(r.To, append(path, r)) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(q.From, nil)
+	return paths, truncated, nil
+}
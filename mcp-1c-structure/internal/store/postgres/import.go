@@ -2,71 +2,494 @@ package postgres
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
 )
 
-// Import writes meta, objects, and relations into the database. Relations are only inserted if from_id and to_id exist in objects (service-level integrity).
-func (p *postgresStore) Import(ctx context.Context, meta snapshot.Meta, objects []snapshot.Object, relations []snapshot.Relation) error {
-	objectIDs := make(map[string]bool)
-	for i := range objects {
-		objectIDs[objects[i].ID] = true
-		objectIDs[normalizeID(objects[i].ID)] = true
+// Import replaces the snapshot of configuration configID with the one read from src in a single transaction:
+// readers see either the previous snapshot or the new one, and a failed import leaves the previous data intact.
+// Objects and relations are streamed into temporary staging tables with COPY in chunks of store.ImportBatchSize rows
+// and then merged into objects, relations and the revision tables with a few INSERT ... SELECT statements.
+// The snapshot is checked by store.Validator on the fly: relations with a missing end are dropped and reported,
+// duplicate edges are dropped silently. Reference relations derived from prop types are staged along with the objects
+// and merged after the explicit relations and the relations derived from module code (deriveCodeRelations, derivePropRelations).
+// With opts.Strict the import is rolled back if the report has errors.
+// The imported snapshot is also kept as the next numbered revision of the configuration.
+func (p *postgresStore) Import(ctx context.Context, configID string, src snapshot.Source, opts store.ImportOptions) (store.ImportResult, error) {
+	started := time.Now()
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return store.ImportResult{}, err
 	}
-	// meta
-	if err := p.setMeta(ctx, "configName", meta.ConfigName); err != nil {
-		return err
+	defer tx.Rollback(ctx)
+
+	// Чтение не блокируется (ACCESS SHARE совместим с EXCLUSIVE), параллельный импорт ждёт окончания текущего.
+	if _, err := tx.Exec(ctx, `LOCK TABLE meta, objects, relations, module_sources, module_methods IN EXCLUSIVE MODE`); err != nil {
+		return store.ImportResult{}, fmt.Errorf("lock tables: %w", err)
 	}
-	if err := p.setMeta(ctx, "configVersion", meta.ConfigVersion); err != nil {
-		return err
+	for _, table := range []string{"relations", "module_methods", "module_sources", "objects", "meta"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE config_id = $1`, configID); err != nil {
+			return store.ImportResult{}, fmt.Errorf("clear %s: %w", table, err)
+		}
 	}
-	if err := p.setMeta(ctx, "exportedAt", meta.ExportedAt); err != nil {
-		return err
+	var rev int
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(revision), 0) + 1 FROM revisions WHERE config_id = $1`, configID).Scan(&rev); err != nil {
+		return store.ImportResult{}, fmt.Errorf("next revision: %w", err)
 	}
-	if err := p.setMeta(ctx, "source", meta.Source); err != nil {
-		return err
+	res := store.ImportResult{Revision: rev}
+	res.Stats.Clear = time.Since(started)
+
+	// objects: staging, затем перенос; при повторном id побеждает последний, как ON CONFLICT DO UPDATE
+	phase := time.Now()
+	_, err = tx.Exec(ctx, `CREATE TEMP TABLE import_objects (
+		seq BIGINT NOT NULL, id TEXT NOT NULL, type TEXT NOT NULL, name TEXT NOT NULL, synonym TEXT NOT NULL,
+		props_json TEXT NOT NULL, tabular_sections_json TEXT NOT NULL, forms TEXT NOT NULL, modules TEXT NOT NULL,
+		subsystems TEXT NOT NULL, content TEXT NOT NULL, register_json TEXT NOT NULL, description TEXT NOT NULL, object_json TEXT NOT NULL
+	) ON COMMIT DROP`)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("create staging objects: %w", err)
 	}
-	if err := p.setMeta(ctx, "objectCount", fmt.Sprintf("%d", len(objects))); err != nil {
-		return err
+	refCopy, err := createPropRefs(ctx, tx)
+	if err != nil {
+		return store.ImportResult{}, err
 	}
-	// objects
-	for i := range objects {
-		o := &objects[i]
-		propsJSON, _ := json.Marshal(o.Props)
-		tabJSON, _ := json.Marshal(o.TabularSections)
-		formsJSON, _ := json.Marshal(o.Forms)
-		modsJSON, _ := json.Marshal(o.Modules)
-		_, err := p.pool.Exec(ctx,
-			`INSERT INTO objects (id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-			 ON CONFLICT (id) DO UPDATE SET type=$2, name=$3, synonym=$4, props_json=$5, tabular_sections_json=$6, forms=$7, modules=$8, description=$9`,
-			o.ID, o.Type, o.Name, o.Synonym, string(propsJSON), string(tabJSON), string(formsJSON), string(modsJSON), o.Description)
+	_, err = tx.Exec(ctx, `CREATE TEMP TABLE import_modules (
+		seq BIGINT NOT NULL, object_id TEXT NOT NULL, module TEXT NOT NULL, position INTEGER NOT NULL,
+		object_type TEXT NOT NULL, path TEXT NOT NULL, source TEXT NOT NULL
+	) ON COMMIT DROP`)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("create staging modules: %w", err)
+	}
+	modCopy := newCopier(tx, "import_modules", "seq", "object_id", "module", "position", "object_type", "path", "source")
+	_, err = tx.Exec(ctx, `CREATE TEMP TABLE import_methods (
+		seq BIGINT NOT NULL, object_id TEXT NOT NULL, module TEXT NOT NULL, line INTEGER NOT NULL, name TEXT NOT NULL,
+		name_lower TEXT NOT NULL, object_type TEXT NOT NULL, object_name TEXT NOT NULL, export BOOLEAN NOT NULL, method_json TEXT NOT NULL
+	) ON COMMIT DROP`)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("create staging methods: %w", err)
+	}
+	methodCopy := newCopier(tx, "import_methods", "seq", "object_id", "module", "line", "name", "name_lower", "object_type", "object_name", "export", "method_json")
+	v := store.NewValidator()
+	objCopy := newCopier(tx, "import_objects", "seq", "id", "type", "name", "synonym", "props_json", "tabular_sections_json", "forms", "modules", "subsystems", "content", "register_json", "description", "object_json")
+	var seq int64
+	err = src.Objects(func(o snapshot.Object) error {
+		v.Object(&o)
+		seq++
+		row, err := objectRow(&o)
 		if err != nil {
-			return fmt.Errorf("insert object %s: %w", o.ID, err)
+			return err
+		}
+		for _, r := range store.PropRefs(&o) {
+			if err := refCopy.add(ctx, propRefRow(r)); err != nil {
+				return err
+			}
+		}
+		for i, m := range store.SourcesOf(&o) {
+			if err := modCopy.add(ctx, []any{seq, m.ObjectID, m.Module, i + 1, m.ObjectType, m.Path, m.Source}); err != nil {
+				return err
+			}
+			for _, mm := range store.ModuleMethods(&m, o.Name) {
+				row, err := methodRow(&mm)
+				if err != nil {
+					return err
+				}
+				if err := methodCopy.add(ctx, append([]any{seq}, row...)); err != nil {
+					return err
+				}
+			}
 		}
+		return objCopy.add(ctx, append([]any{seq}, row...))
+	})
+	if err == nil {
+		err = objCopy.flush(ctx)
+	}
+	if err == nil {
+		err = refCopy.flush(ctx)
+	}
+	if err == nil {
+		err = modCopy.flush(ctx)
+	}
+	if err == nil {
+		err = methodCopy.flush(ctx)
 	}
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("objects: %w", err)
+	}
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO objects (config_id, id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description)
+		 SELECT DISTINCT ON (id) $1, id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+		 FROM import_objects ORDER BY id, seq DESC`, configID)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("merge objects: %w", err)
+	}
+	res.ObjectCount = int(tag.RowsAffected())
+	_, err = tx.Exec(ctx,
+		`INSERT INTO revision_objects (config_id, revision, id, object_json)
+		 SELECT DISTINCT ON (id) $1, $2, id, object_json FROM import_objects ORDER BY id, seq DESC`, configID, rev)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("merge revision objects: %w", err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO module_sources (config_id, object_id, module, position, object_type, path, source)
+		 SELECT DISTINCT ON (object_id, module) $1, object_id, module, position, object_type, path, source
+		 FROM import_modules ORDER BY object_id, module, seq DESC`, configID)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("merge module sources: %w", err)
+	}
+	// Методы берутся из того же текста модуля, что попал в module_sources: из последнего объекта с этим id.
+	_, err = tx.Exec(ctx,
+		`INSERT INTO module_methods (config_id, object_id, module, line, name, name_lower, object_type, object_name, export, method_json)
+		 SELECT $1, m.object_id, m.module, m.line, m.name, m.name_lower, m.object_type, m.object_name, m.export, m.method_json
+		 FROM import_methods m
+		 JOIN (SELECT DISTINCT ON (object_id, module) object_id, module, seq FROM import_modules ORDER BY object_id, module, seq DESC) l
+		   ON l.object_id = m.object_id AND l.module = m.module AND l.seq = m.seq
+		 ON CONFLICT DO NOTHING`, configID)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("merge module methods: %w", err)
+	}
+	res.Stats.Objects = time.Since(phase)
+
 	// relations (only if both ends exist)
-	for i := range relations {
-		r := &relations[i]
-		from := normalizeID(r.From)
-		to := normalizeID(r.To)
-		if !objectIDs[from] && !objectIDs[r.From] {
-			continue
+	phase = time.Now()
+	_, err = tx.Exec(ctx, `CREATE TEMP TABLE import_relations (from_id TEXT NOT NULL, to_id TEXT NOT NULL, kind TEXT NOT NULL, prop TEXT NOT NULL) ON COMMIT DROP`)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("create staging relations: %w", err)
+	}
+	relCopy := newCopier(tx, "import_relations", "from_id", "to_id", "kind", "prop")
+	err = src.Relations(func(r snapshot.Relation) error {
+		res.RelationCount++
+		if !v.Relation(&r) {
+			return nil
+		}
+		return relCopy.add(ctx, []any{r.From, r.To, r.Kind, r.Prop})
+	})
+	if err == nil {
+		err = relCopy.flush(ctx)
+	}
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("relations: %w", err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO relations (config_id, from_id, to_id, kind, prop)
+		 SELECT $1, from_id, to_id, kind, prop FROM import_relations ON CONFLICT DO NOTHING`, configID)
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("merge relations: %w", err)
+	}
+	if err := deriveCodeRelations(ctx, tx, configID, nil); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := derivePropRelations(ctx, tx, configID, v); err != nil {
+		return store.ImportResult{}, err
+	}
+	res.Stats.Relations = time.Since(phase)
+
+	// meta: читается последней, objectCount — фактическое число объектов
+	phase = time.Now()
+	meta, err := src.Meta()
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("meta: %w", err)
+	}
+	v.Meta(meta)
+	res.Validation = v.Report()
+	if opts.Strict && res.Validation.HasErrors() {
+		return res, &store.ValidationError{Report: res.Validation}
+	}
+	meta.ObjectCount = res.ObjectCount
+	if err := insertRevision(ctx, tx, configID, rev, meta, res.ObjectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := writeMeta(ctx, tx, configID, meta, res.ObjectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := copyRevisionRelations(ctx, tx, configID, rev); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return store.ImportResult{}, err
+	}
+	res.Stats.Finalize = time.Since(phase)
+	res.Stats.Total = time.Since(started)
+	res.Meta = meta
+	return res, nil
+}
+
+// createPropRefs создаёт временную таблицу для ссылок из типов реквизитов (store.PropRef) и copier для неё.
+func createPropRefs(ctx context.Context, tx pgx.Tx) (*copier, error) {
+	_, err := tx.Exec(ctx, `CREATE TEMP TABLE import_prop_refs (from_id TEXT NOT NULL, to_id TEXT NOT NULL, full_id TEXT NOT NULL, prop TEXT NOT NULL) ON COMMIT DROP`)
+	if err != nil {
+		return nil, fmt.Errorf("create staging prop refs: %w", err)
+	}
+	return newCopier(tx, "import_prop_refs", "from_id", "to_id", "full_id", "prop"), nil
+}
+
+func propRefRow(r store.PropRef) []any {
+	return []any{r.From, r.To, r.FullID, r.Prop}
+}
+
+// derivePropRelations переносит ссылки из import_prop_refs в relations как связи reference, если цель есть в снимке,
+// с id цели, под которым она хранится (нормализованный, иначе полный — store.ResolveID);
+// ссылки на отсутствующие объекты попадают в отчёт v (nil — не сообщать). Связь из relations.json без prop,
+// повторяющая выведенную (те же from, to, kind), удаляется: выведенная несёт путь реквизита.
+func derivePropRelations(ctx context.Context, tx pgx.Tx, configID string, v *store.Validator) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO relations (config_id, from_id, to_id, kind, prop)
+		 SELECT DISTINCT $1, r.from_id, COALESCE(
+		   (SELECT o.id FROM objects o WHERE o.config_id = $1 AND o.id = r.to_id),
+		   (SELECT o.id FROM objects o WHERE o.config_id = $1 AND o.id = r.full_id)), $2, r.prop
+		 FROM import_prop_refs r
+		 WHERE EXISTS (SELECT 1 FROM objects o WHERE o.config_id = $1 AND o.id IN (r.to_id, r.full_id))
+		 ON CONFLICT DO NOTHING`, configID, store.KindReference)
+	if err != nil {
+		return fmt.Errorf("derive prop relations: %w", err)
+	}
+	if v != nil {
+		rows, err := tx.Query(ctx,
+			`SELECT r.from_id, r.to_id, r.prop FROM import_prop_refs r
+			 WHERE NOT EXISTS (SELECT 1 FROM objects o WHERE o.config_id = $1 AND o.id IN (r.to_id, r.full_id))`, configID)
+		if err != nil {
+			return fmt.Errorf("unresolved prop references: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			r := snapshot.Relation{Kind: store.KindReference}
+			if err := rows.Scan(&r.From, &r.To, &r.Prop); err != nil {
+				return err
+			}
+			v.UnresolvedPropRef(&r)
 		}
-		if !objectIDs[to] && !objectIDs[r.To] {
-			continue
+		if err := rows.Err(); err != nil {
+			return err
 		}
-		_, err := p.pool.Exec(ctx, `INSERT INTO relations (from_id, to_id, kind) VALUES ($1, $2, $3)`, r.From, r.To, r.Kind)
+	}
+	_, err = tx.Exec(ctx,
+		`DELETE FROM relations e USING relations d
+		 WHERE e.config_id = $1 AND e.prop = '' AND d.config_id = e.config_id AND d.from_id = e.from_id
+		   AND d.to_id = e.to_id AND d.kind = e.kind AND d.prop <> ''`, configID)
+	if err != nil {
+		return fmt.Errorf("merge prop relations: %w", err)
+	}
+	return nil
+}
+
+// deriveCodeRelations добавляет связи из кода модулей (store.CodeRelations) объектов objectIDs, nil — всех модулей
+// конфигурации. Тексты читаются курсором, в памяти копятся только связи: пока курсор открыт, пакет не отправить.
+func deriveCodeRelations(ctx context.Context, tx pgx.Tx, configID string, objectIDs []string) error {
+	stored := make(map[string]bool)
+	rows, err := tx.Query(ctx, `SELECT id FROM objects WHERE config_id = $1`, configID)
+	if err != nil {
+		return fmt.Errorf("read object ids: %w", err)
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		stored[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	resolve := func(id string) (string, bool) {
+		return store.ResolveID(id, func(id string) bool { return stored[id] })
+	}
+	q := `SELECT object_id, object_type, module, path, source FROM module_sources WHERE config_id = $1`
+	args := []any{configID}
+	if objectIDs != nil {
+		q += ` AND object_id = ANY($2)`
+		args = append(args, objectIDs)
+	}
+	rows, err = tx.Query(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("read module sources: %w", err)
+	}
+	var relations []snapshot.Relation
+	for rows.Next() {
+		var m store.ModuleSource
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.Module, &m.Path, &m.Source); err != nil {
+			rows.Close()
+			return err
+		}
+		relations = append(relations, store.CodeRelations(&m, resolve)...)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	b := newBatcher(tx)
+	for _, r := range relations {
+		err := b.queue(ctx, `INSERT INTO relations (config_id, from_id, to_id, kind, prop) VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`,
+			configID, r.From, r.To, r.Kind, r.Prop)
 		if err != nil {
-			return fmt.Errorf("insert relation %s -> %s: %w", r.From, r.To, err)
+			return fmt.Errorf("insert code relations: %w", err)
 		}
 	}
+	if err := b.flush(ctx); err != nil {
+		return fmt.Errorf("insert code relations: %w", err)
+	}
+	return nil
+}
+
+// copier копит строки и отправляет их в таблицу через COPY, как только набирается store.ImportBatchSize.
+type copier struct {
+	tx      pgx.Tx
+	table   pgx.Identifier
+	columns []string
+	rows    [][]any
+}
+
+func newCopier(tx pgx.Tx, table string, columns ...string) *copier {
+	return &copier{tx: tx, table: pgx.Identifier{table}, columns: columns}
+}
+
+func (c *copier) add(ctx context.Context, row []any) error {
+	c.rows = append(c.rows, row)
+	if len(c.rows) >= store.ImportBatchSize {
+		return c.flush(ctx)
+	}
+	return nil
+}
+
+func (c *copier) flush(ctx context.Context) error {
+	if len(c.rows) == 0 {
+		return nil
+	}
+	_, err := c.tx.CopyFrom(ctx, c.table, c.columns, pgx.CopyFromRows(c.rows))
+	c.rows = c.rows[:0]
+	return err
+}
+
+// objectRow — значения колонок objects (без config_id) и JSON объекта целиком для revision_objects.
+func objectRow(o *snapshot.Object) ([]any, error) {
+	cols, err := store.ObjectColumns(o)
+	if err != nil {
+		return nil, err
+	}
+	return []any{o.ID, o.Type, o.Name, o.Synonym, cols.Props, cols.TabularSections, cols.Forms, cols.Modules, cols.Subsystems, cols.Content, cols.Register, o.Description, cols.Object}, nil
+}
+
+// batcher копит запросы и отправляет их одним pgx.Batch, как только набирается store.ImportBatchSize.
+type batcher struct {
+	tx    pgx.Tx
+	batch *pgx.Batch
+}
+
+func newBatcher(tx pgx.Tx) *batcher {
+	return &batcher{tx: tx, batch: &pgx.Batch{}}
+}
+
+func (b *batcher) queue(ctx context.Context, sql string, args ...any) error {
+	b.batch.Queue(sql, args...)
+	if b.batch.Len() >= store.ImportBatchSize {
+		return b.flush(ctx)
+	}
+	return nil
+}
+
+func (b *batcher) flush(ctx context.Context) error {
+	if b.batch.Len() == 0 {
+		return nil
+	}
+	err := b.tx.SendBatch(ctx, b.batch).Close()
+	b.batch = &pgx.Batch{}
+	return err
+}
+
+// queueObject ставит в пакет запись объекта в текущий снимок и в ревизию rev и тексты его модулей.
+func (b *batcher) queueObject(ctx context.Context, configID string, rev int, o *snapshot.Object) error {
+	row, err := objectRow(o)
+	if err != nil {
+		return err
+	}
+	b.batch.Queue(
+		`INSERT INTO objects (config_id, id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		 ON CONFLICT (config_id, id) DO UPDATE SET type=$3, name=$4, synonym=$5, props_json=$6, tabular_sections_json=$7, forms=$8, modules=$9,
+		   subsystems=$10, content=$11, register_json=$12, description=$13`,
+		append([]any{configID}, row[:12]...)...)
+	for i, m := range store.SourcesOf(o) {
+		b.batch.Queue(
+			`INSERT INTO module_sources (config_id, object_id, module, position, object_type, path, source) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			 ON CONFLICT (config_id, object_id, module) DO UPDATE SET position = $4, object_type = $5, path = $6, source = $7`,
+			configID, m.ObjectID, m.Module, i+1, m.ObjectType, m.Path, m.Source)
+		b.batch.Queue(`DELETE FROM module_methods WHERE config_id = $1 AND object_id = $2 AND module = $3`, configID, m.ObjectID, m.Module)
+		for _, mm := range store.ModuleMethods(&m, o.Name) {
+			row, err := methodRow(&mm)
+			if err != nil {
+				return err
+			}
+			b.batch.Queue(
+				`INSERT INTO module_methods (config_id, object_id, module, line, name, name_lower, object_type, object_name, export, method_json)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING`,
+				append([]any{configID}, row...)...)
+		}
+	}
+	return b.queue(ctx,
+		`INSERT INTO revision_objects (config_id, revision, id, object_json) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (config_id, revision, id) DO UPDATE SET object_json = $4`,
+		configID, rev, o.ID, row[12])
+}
+
+// methodRow — столбцы module_methods после config_id.
+func methodRow(m *store.ModuleMethod) ([]any, error) {
+	data, err := store.MethodJSON(m)
+	if err != nil {
+		return nil, err
+	}
+	return []any{m.ObjectID, m.Module, m.Line, m.Name, strings.ToLower(m.Name), m.ObjectType, m.ObjectName, m.Export, data}, nil
+}
+
+// insertRevision регистрирует ревизию rev с метаданными снимка.
+func insertRevision(ctx context.Context, tx pgx.Tx, configID string, rev int, meta snapshot.Meta, objectCount int) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO revisions (config_id, revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		configID, rev, meta.ConfigName, meta.ConfigVersion, meta.ExportedAt, meta.Source, objectCount, meta.IndexVersion, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+	return nil
+}
+
+// writeMeta перезаписывает ключи meta конфигурации.
+func writeMeta(ctx context.Context, tx pgx.Tx, configID string, meta snapshot.Meta, objectCount int) error {
+	if err := setMeta(ctx, tx, configID, "configName", meta.ConfigName); err != nil {
+		return err
+	}
+	if err := setMeta(ctx, tx, configID, "configVersion", meta.ConfigVersion); err != nil {
+		return err
+	}
+	if err := setMeta(ctx, tx, configID, "exportedAt", meta.ExportedAt); err != nil {
+		return err
+	}
+	if err := setMeta(ctx, tx, configID, "source", meta.Source); err != nil {
+		return err
+	}
+	if err := setMeta(ctx, tx, configID, "indexVersion", fmt.Sprintf("%d", meta.IndexVersion)); err != nil {
+		return err
+	}
+	return setMeta(ctx, tx, configID, "objectCount", fmt.Sprintf("%d", objectCount))
+}
+
+// copyRevisionRelations сохраняет текущие связи конфигурации в ревизию rev.
+func copyRevisionRelations(ctx context.Context, tx pgx.Tx, configID string, rev int) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO revision_relations (config_id, revision, from_id, to_id, kind, prop)
+		 SELECT config_id, $2, from_id, to_id, kind, prop FROM relations WHERE config_id = $1`,
+		configID, rev)
+	if err != nil {
+		return fmt.Errorf("copy revision relations: %w", err)
+	}
 	return nil
 }
 
-func (p *postgresStore) setMeta(ctx context.Context, key, value string) error {
-	_, err := p.pool.Exec(ctx, `INSERT INTO meta (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = $2`, key, value)
+func setMeta(ctx context.Context, tx pgx.Tx, configID, key, value string) error {
+	_, err := tx.Exec(ctx, `INSERT INTO meta (config_id, key, value) VALUES ($1, $2, $3) ON CONFLICT (config_id, key) DO UPDATE SET value = $3`, configID, key, value)
 	return err
 }
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/migrations"
+)
+
+// migrationLockKey — ключ pg_advisory_xact_lock, чтобы два процесса не применяли миграции одновременно.
+const migrationLockKey = 0x31c5_7275
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+}
+
+// migrate сверяет схему с встроенными миграциями (migrations.FS). Применённые версии хранятся в goose_db_version,
+// как у goose, поэтому базы, обновлённые goose вручную, продолжают работать. Версия базы новее бинарника — всегда ошибка;
+// в режиме config.MigrateCheck схема не меняется, и ошибкой считаются также неприменённые миграции.
+func migrate(ctx context.Context, pool *pgxpool.Pool, mode string) error {
+	list, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	latest := list[len(list)-1].version
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if mode != config.MigrateCheck {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+			return fmt.Errorf("migration lock: %w", err)
+		}
+	}
+	current, exists, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d): upgrade mcp-1c-structure", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+	if mode == config.MigrateCheck {
+		return fmt.Errorf("database schema version %d is behind this binary (%d): run with -migrate=apply or goose up", current, latest)
+	}
+	if !exists {
+		_, err := tx.Exec(ctx, `CREATE TABLE goose_db_version (
+			id SERIAL PRIMARY KEY, version_id BIGINT NOT NULL, is_applied BOOLEAN NOT NULL, tstamp TIMESTAMP DEFAULT now()
+		)`)
+		if err != nil {
+			return fmt.Errorf("create goose_db_version: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, true)`); err != nil {
+			return err
+		}
+	}
+	for _, m := range list {
+		if m.version <= current {
+			continue
+		}
+		if _, err := tx.Exec(ctx, m.up); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)`, m.version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// schemaVersion возвращает последнюю применённую версию; exists=false, если таблицы версий ещё нет.
+func schemaVersion(ctx context.Context, tx pgx.Tx) (version int64, exists bool, err error) {
+	if err := tx.QueryRow(ctx, `SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied`).Scan(&version)
+	return version, true, err
+}
+
+// loadMigrations читает встроенные миграции NNNNN_описание.sql и оставляет из каждой секцию «-- +goose Up».
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	var list []migration
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		prefix, _, _ := strings.Cut(e.Name(), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: bad version prefix", e.Name())
+		}
+		body, err := migrations.FS.ReadFile(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		up, ok := gooseUp(string(body))
+		if !ok {
+			return nil, fmt.Errorf("migration %s: no -- +goose Up section", e.Name())
+		}
+		list = append(list, migration{version: version, name: e.Name(), up: up})
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no embedded migrations")
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+	return list, nil
+}
+
+func gooseUp(body string) (string, bool) {
+	_, rest, ok := strings.Cut(body, "-- +goose Up")
+	if !ok {
+		return "", false
+	}
+	up, _, _ := strings.Cut(rest, "-- +goose Down")
+	return up, true
+}
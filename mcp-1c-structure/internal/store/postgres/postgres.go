@@ -9,6 +9,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ser/mcp-1c-structure/internal/search"
 	"github.com/ser/mcp-1c-structure/internal/snapshot"
 	"github.com/ser/mcp-1c-structure/internal/store"
 )
@@ -17,7 +18,8 @@ type postgresStore struct {
 	pool *pgxpool.Pool
 }
 
-func New(dbURL string) (store.Store, error) {
+// New подключается к базе и применяет или проверяет встроенные миграции (migrateMode — config.MigrateApply или config.MigrateCheck).
+func New(dbURL, migrateMode string) (store.Store, error) {
 	if dbURL == "" {
 		return nil, fmt.Errorf("database URL is empty")
 	}
@@ -29,10 +31,14 @@ func New(dbURL string) (store.Store, error) {
 		pool.Close()
 		return nil, err
 	}
+	if err := migrate(context.Background(), pool, migrateMode); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("schema: %w", err)
+	}
 	return &postgresStore{pool: pool}, nil
 }
 
-func (p *postgresStore) Search(ctx context.Context, query, typeFilter string, limit, offset int) ([]snapshot.Object, int, error) {
+func (p *postgresStore) Search(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]snapshot.Object, int, error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -44,16 +50,16 @@ func (p *postgresStore) Search(ctx context.Context, query, typeFilter string, li
 	likeQ := "%" + query + "%"
 	var total int
 	err := p.pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM objects WHERE ($1 = '' OR LOWER(name) LIKE $2 OR LOWER(synonym) LIKE $2) AND ($3 = '' OR LOWER(type) = $3)`,
-		query, likeQ, typeFilter).Scan(&total)
+		`SELECT COUNT(*) FROM objects WHERE config_id = $1 AND ($2 = '' OR LOWER(name) LIKE $3 OR LOWER(synonym) LIKE $3) AND ($4 = '' OR LOWER(type) = $4)`,
+		configID, query, likeQ, typeFilter).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 	rows, err := p.pool.Query(ctx,
-		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description
-		 FROM objects WHERE ($1 = '' OR LOWER(name) LIKE $2 OR LOWER(synonym) LIKE $2) AND ($3 = '' OR LOWER(type) = $3)
-		 ORDER BY name LIMIT $4 OFFSET $5`,
-		query, likeQ, typeFilter, limit, offset)
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+		 FROM objects WHERE config_id = $1 AND ($2 = '' OR LOWER(name) LIKE $3 OR LOWER(synonym) LIKE $3) AND ($4 = '' OR LOWER(type) = $4)
+		 ORDER BY name LIMIT $5 OFFSET $6`,
+		configID, query, likeQ, typeFilter, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -61,8 +67,8 @@ func (p *postgresStore) Search(ctx context.Context, query, typeFilter string, li
 	var list []snapshot.Object
 	for rows.Next() {
 		var o snapshot.Object
-		var propsJSON, tabSecJSON, formsJSON, modsJSON string
-		err := rows.Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &o.Description)
+		var propsJSON, tabSecJSON, formsJSON, modsJSON, subsJSON, contentJSON, registerJSON string
+		err := rows.Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &subsJSON, &contentJSON, &registerJSON, &o.Description)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -70,18 +76,142 @@ func (p *postgresStore) Search(ctx context.Context, query, typeFilter string, li
 		_ = json.Unmarshal([]byte(tabSecJSON), &o.TabularSections)
 		_ = json.Unmarshal([]byte(formsJSON), &o.Forms)
 		_ = json.Unmarshal([]byte(modsJSON), &o.Modules)
+		_ = json.Unmarshal([]byte(subsJSON), &o.Subsystems)
+		_ = json.Unmarshal([]byte(contentJSON), &o.Content)
+		_ = json.Unmarshal([]byte(registerJSON), &o.Register)
 		list = append(list, o)
 	}
 	return list, total, rows.Err()
 }
 
-func (p *postgresStore) GetObject(ctx context.Context, id string) (snapshot.Object, bool, error) {
-	id = normalizeID(id)
-	var o snapshot.Object
-	var propsJSON, tabSecJSON, formsJSON, modsJSON string
+// SearchFullText ищет по столбцу search_tsv (миграция 00006): plainto_tsquery со словарём russian, релевантность —
+// ts_rank с весами search.Weights (A — имя, B — синоним, C — описание, D — реквизиты и табличные части).
+func (p *postgresStore) SearchFullText(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]store.SearchHit, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	// Слова разбиваются так же, как в search_tsv: ДоговорКонтрагента ищется как «договор контрагент».
+	words := strings.Join(search.Words(query), " ")
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	w := search.Weights
+	weights := []float32{float32(w[3]), float32(w[2]), float32(w[1]), float32(w[0])} // порядок ts_rank: D, C, B, A
+	var total int
 	err := p.pool.QueryRow(ctx,
-		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description FROM objects WHERE id = $1`,
-		id).Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &o.Description)
+		`SELECT COUNT(*) FROM objects WHERE config_id = $1 AND search_tsv @@ plainto_tsquery('russian', $2) AND ($3 = '' OR LOWER(type) = $3)`,
+		configID, words, typeFilter).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := p.pool.Query(ctx,
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description,
+		        ts_rank($4::float4[], search_tsv, q) AS score
+		 FROM objects, plainto_tsquery('russian', $2) q
+		 WHERE config_id = $1 AND search_tsv @@ q AND ($3 = '' OR LOWER(type) = $3)
+		 ORDER BY score DESC, name LIMIT $5 OFFSET $6`,
+		configID, words, typeFilter, weights, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	terms := search.QueryTerms(query)
+	var hits []store.SearchHit
+	for rows.Next() {
+		var h store.SearchHit
+		var score float32
+		var propsJSON, tabSecJSON, formsJSON, modsJSON, subsJSON, contentJSON, registerJSON string
+		o := &h.Object
+		if err := rows.Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &subsJSON, &contentJSON, &registerJSON, &o.Description, &score); err != nil {
+			return nil, 0, err
+		}
+		_ = json.Unmarshal([]byte(propsJSON), &o.Props)
+		_ = json.Unmarshal([]byte(tabSecJSON), &o.TabularSections)
+		_ = json.Unmarshal([]byte(formsJSON), &o.Forms)
+		_ = json.Unmarshal([]byte(modsJSON), &o.Modules)
+		_ = json.Unmarshal([]byte(subsJSON), &o.Subsystems)
+		_ = json.Unmarshal([]byte(contentJSON), &o.Content)
+		_ = json.Unmarshal([]byte(registerJSON), &o.Register)
+		h.Score = float64(score)
+		h.MatchedField = search.MatchedField(o, terms)
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+// fuzzyMatches — объекты, имя или синоним которых похожи на один из вариантов запроса (оператор % из pg_trgm
+// по индексам idx_objects_name_trgm и idx_objects_synonym_trgm), с лучшим вариантом для каждого объекта.
+const fuzzyMatches = `WITH v(q, kind) AS (SELECT * FROM unnest($2::text[], $3::text[])),
+matches AS (
+	SELECT DISTINCT ON (o.id) o.id, o.type, o.name, o.synonym, o.props_json, o.tabular_sections_json, o.forms, o.modules, o.subsystems, o.content, o.register_json, o.description,
+	       GREATEST(similarity(o.name, v.q), similarity(o.synonym, v.q)) AS score,
+	       CASE WHEN similarity(o.name, v.q) >= similarity(o.synonym, v.q) THEN 'name' ELSE 'synonym' END AS field,
+	       v.q, v.kind
+	FROM objects o JOIN v ON o.name % v.q OR o.synonym % v.q
+	WHERE o.config_id = $1 AND ($4 = '' OR LOWER(o.type) = $4)
+	ORDER BY o.id, score DESC
+)`
+
+// SearchFuzzy ищет по триграммному сходству pg_trgm; порог — pg_trgm.similarity_threshold (по умолчанию 0.3,
+// как search.FuzzyThreshold).
+func (p *postgresStore) SearchFuzzy(ctx context.Context, configID string, variants []search.Variant, typeFilter string, limit, offset int) ([]store.SearchHit, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	texts := make([]string, len(variants))
+	kinds := make([]string, len(variants))
+	for i, v := range variants {
+		texts[i], kinds[i] = v.Text, v.Kind
+	}
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	var total int
+	if err := p.pool.QueryRow(ctx, fuzzyMatches+` SELECT COUNT(*) FROM matches`, configID, texts, kinds, typeFilter).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := p.pool.Query(ctx, fuzzyMatches+` SELECT * FROM matches ORDER BY score DESC, name LIMIT $5 OFFSET $6`,
+		configID, texts, kinds, typeFilter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var hits []store.SearchHit
+	for rows.Next() {
+		var h store.SearchHit
+		var score float32
+		var propsJSON, tabSecJSON, formsJSON, modsJSON, subsJSON, contentJSON, registerJSON string
+		o := &h.Object
+		err := rows.Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &subsJSON, &contentJSON, &registerJSON, &o.Description,
+			&score, &h.MatchedField, &h.Variant.Text, &h.Variant.Kind)
+		if err != nil {
+			return nil, 0, err
+		}
+		_ = json.Unmarshal([]byte(propsJSON), &o.Props)
+		_ = json.Unmarshal([]byte(tabSecJSON), &o.TabularSections)
+		_ = json.Unmarshal([]byte(formsJSON), &o.Forms)
+		_ = json.Unmarshal([]byte(modsJSON), &o.Modules)
+		_ = json.Unmarshal([]byte(subsJSON), &o.Subsystems)
+		_ = json.Unmarshal([]byte(contentJSON), &o.Content)
+		_ = json.Unmarshal([]byte(registerJSON), &o.Register)
+		h.Score = float64(score)
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+func (p *postgresStore) GetObject(ctx context.Context, configID, id string) (snapshot.Object, bool, error) {
+	id, ok, err := p.resolveID(ctx, configID, id)
+	if err != nil || !ok {
+		return snapshot.Object{}, false, err
+	}
+	var o snapshot.Object
+	var propsJSON, tabSecJSON, formsJSON, modsJSON, subsJSON, contentJSON, registerJSON string
+	err = p.pool.QueryRow(ctx,
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description FROM objects WHERE config_id = $1 AND id = $2`,
+		configID, id).Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &subsJSON, &contentJSON, &registerJSON, &o.Description)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return snapshot.Object{}, false, nil
@@ -92,11 +222,78 @@ func (p *postgresStore) GetObject(ctx context.Context, id string) (snapshot.Obje
 	_ = json.Unmarshal([]byte(tabSecJSON), &o.TabularSections)
 	_ = json.Unmarshal([]byte(formsJSON), &o.Forms)
 	_ = json.Unmarshal([]byte(modsJSON), &o.Modules)
+	_ = json.Unmarshal([]byte(subsJSON), &o.Subsystems)
+	_ = json.Unmarshal([]byte(contentJSON), &o.Content)
+	_ = json.Unmarshal([]byte(registerJSON), &o.Register)
 	return o, true, nil
 }
 
-func (p *postgresStore) FindReferences(ctx context.Context, id, direction, kind string, limit int) (incoming, outgoing []snapshot.Relation, err error) {
-	id = normalizeID(id)
+// resolveID возвращает id, под которым объект хранится (store.ResolveID): все написания id проверяются одним запросом.
+func (p *postgresStore) resolveID(ctx context.Context, configID, id string) (string, bool, error) {
+	rows, err := p.pool.Query(ctx, `SELECT id FROM objects WHERE config_id = $1 AND id = ANY($2)`, configID, store.IDVariants(id))
+	if err != nil {
+		return "", false, err
+	}
+	defer rows.Close()
+	found := make(map[string]bool)
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return "", false, err
+		}
+		found[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", false, err
+	}
+	stored, ok := store.ResolveID(id, func(id string) bool { return found[id] })
+	return stored, ok, nil
+}
+
+// FindByProp ищет по таблице object_props (миграция 00007), которую ведут триггеры на objects.
+func (p *postgresStore) FindByProp(ctx context.Context, configID string, filter store.PropFilter, limit, offset int) ([]store.PropMatch, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	args := []any{configID,
+		strings.ToLower(strings.TrimSpace(filter.Name)), strings.ToLower(strings.TrimSpace(filter.Type)),
+		strings.ToLower(strings.TrimSpace(filter.TabularSection)), strings.ToLower(strings.TrimSpace(filter.ObjectType)),
+		strings.ToLower(strings.TrimSpace(filter.Kind))}
+	const from = ` FROM object_props p JOIN objects o ON o.config_id = p.config_id AND o.id = p.object_id
+		 WHERE p.config_id = $1 AND ($2 = '' OR LOWER(p.name) = $2) AND ($3 = '' OR LOWER(p.type) = $3)
+		   AND ($4 = '' OR (p.section <> '' AND LOWER(p.section) = $4)) AND ($5 = '' OR LOWER(o.type) = $5)
+		   AND ($6 = '' OR p.kind = $6)`
+	var total int
+	if err := p.pool.QueryRow(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := p.pool.Query(ctx,
+		`SELECT o.id, o.type, o.name, o.synonym, p.section, p.name, p.type, p.synonym, p.kind`+from+`
+		 ORDER BY o.name, o.id, p.section <> '', p.section, p.position LIMIT $7 OFFSET $8`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []store.PropMatch
+	for rows.Next() {
+		var m store.PropMatch
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.ObjectName, &m.ObjectSynonym, &m.TabularSection, &m.Prop.Name, &m.Prop.Type, &m.Prop.Synonym, &m.Prop.Kind); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, m)
+	}
+	return list, total, rows.Err()
+}
+
+func (p *postgresStore) FindReferences(ctx context.Context, configID, id, direction, kind string, limit int) (incoming, outgoing []snapshot.Relation, err error) {
+	id, ok, err := p.resolveID(ctx, configID, id)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
 	if limit <= 0 {
 		limit = 50
 	}
@@ -106,25 +303,25 @@ func (p *postgresStore) FindReferences(ctx context.Context, id, direction, kind
 	wantIn := direction == "incoming" || direction == "both" || direction == ""
 	wantOut := direction == "outgoing" || direction == "both" || direction == ""
 	if wantIn {
-		rows, e := p.pool.Query(ctx, `SELECT from_id, to_id, kind FROM relations WHERE to_id = $1 AND ($2 = '' OR kind = $2) LIMIT $3`, id, kind, limit)
+		rows, e := p.pool.Query(ctx, `SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = $1 AND to_id = $2 AND ($3 = '' OR kind = $3) LIMIT $4`, configID, id, kind, limit)
 		if e != nil {
 			return nil, nil, e
 		}
 		for rows.Next() {
 			var r snapshot.Relation
-			_ = rows.Scan(&r.From, &r.To, &r.Kind)
+			_ = rows.Scan(&r.From, &r.To, &r.Kind, &r.Prop)
 			incoming = append(incoming, r)
 		}
 		rows.Close()
 	}
 	if wantOut {
-		rows, e := p.pool.Query(ctx, `SELECT from_id, to_id, kind FROM relations WHERE from_id = $1 AND ($2 = '' OR kind = $2) LIMIT $3`, id, kind, limit)
+		rows, e := p.pool.Query(ctx, `SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = $1 AND from_id = $2 AND ($3 = '' OR kind = $3) LIMIT $4`, configID, id, kind, limit)
 		if e != nil {
 			return nil, nil, e
 		}
 		for rows.Next() {
 			var r snapshot.Relation
-			_ = rows.Scan(&r.From, &r.To, &r.Kind)
+			_ = rows.Scan(&r.From, &r.To, &r.Kind, &r.Prop)
 			outgoing = append(outgoing, r)
 		}
 		rows.Close()
@@ -132,8 +329,230 @@ func (p *postgresStore) FindReferences(ctx context.Context, id, direction, kind
 	return incoming, outgoing, nil
 }
 
-func (p *postgresStore) ListTypes(ctx context.Context) ([]store.TypeCount, error) {
-	rows, err := p.pool.Query(ctx, `SELECT type, COUNT(*)::bigint FROM objects GROUP BY type ORDER BY type`)
+// impactSQL — обход связей рекурсивным CTE. В каждом шаге row_number оставляет одну строку на объект (наименьший путь),
+// поэтому слой не больше числа объектов; path защищает от циклов, DISTINCT ON берёт наименьшую глубину.
+// Пути и id сравниваются побайтно (COLLATE "C"), как строки в памяти и в SQLite, чтобы выбор пути не зависел от локали БД.
+// %[1]s — колонка связи, совпадающая с текущим объектом, %[2]s — колонка следующего объекта.
+const impactSQL = `WITH RECURSIVE walk (id, depth, path, kind, prop) AS (
+	SELECT $2::text, 0, ARRAY[$2::text], ''::text, ''::text
+	UNION ALL
+	SELECT s.id, s.depth, s.path, s.kind, s.prop FROM (
+		SELECT r.%[2]s AS id, w.depth + 1 AS depth, w.path || r.%[2]s AS path, r.kind, r.prop,
+		       row_number() OVER (PARTITION BY r.%[2]s ORDER BY w.path COLLATE "C", r.kind COLLATE "C", r.prop COLLATE "C") AS rn
+		FROM walk w
+		JOIN relations r ON r.config_id = $1 AND r.%[1]s = w.id
+		WHERE w.depth < $3 AND NOT r.%[2]s = ANY(w.path) AND (cardinality($4::text[]) = 0 OR r.kind = ANY($4))
+	) s WHERE s.rn = 1
+)
+SELECT w.id, w.depth, w.path, w.kind, w.prop, COALESCE(o.type, ''), COALESCE(o.name, '')
+FROM (SELECT DISTINCT ON (id) id, depth, path, kind, prop FROM walk WHERE depth > 0 ORDER BY id, depth, path COLLATE "C", kind COLLATE "C", prop COLLATE "C") w
+LEFT JOIN objects o ON o.config_id = $1 AND o.id = w.id
+ORDER BY w.depth, w.id COLLATE "C"
+LIMIT $5`
+
+func (p *postgresStore) ImpactAnalysis(ctx context.Context, configID string, q store.ImpactQuery) ([]store.ImpactNode, bool, error) {
+	q = q.Normalize()
+	sql := fmt.Sprintf(impactSQL, "to_id", "from_id")
+	if q.Direction == "outgoing" {
+		sql = fmt.Sprintf(impactSQL, "from_id", "to_id")
+	}
+	rows, err := p.pool.Query(ctx, sql, configID, q.ObjectID, q.MaxDepth, q.Kinds, q.MaxNodes+1)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	var nodes []store.ImpactNode
+	for rows.Next() {
+		var n store.ImpactNode
+		if err := rows.Scan(&n.ID, &n.Depth, &n.Path, &n.Kind, &n.Prop, &n.Type, &n.Name); err != nil {
+			return nil, false, err
+		}
+		nodes = append(nodes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if len(nodes) > q.MaxNodes {
+		return nodes[:q.MaxNodes], true, nil
+	}
+	return nodes, false, nil
+}
+
+func (p *postgresStore) RelationsAmong(ctx context.Context, configID string, ids, kinds []string) ([]snapshot.Relation, error) {
+	if kinds == nil {
+		kinds = []string{}
+	}
+	rows, err := p.pool.Query(ctx,
+		`SELECT from_id, to_id, kind, prop FROM relations
+		 WHERE config_id = $1 AND from_id = ANY($2) AND to_id = ANY($2) AND (cardinality($3::text[]) = 0 OR kind = ANY($3))
+		 ORDER BY from_id, to_id, kind, prop`,
+		configID, ids, kinds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []snapshot.Relation
+	for rows.Next() {
+		var r snapshot.Relation
+		if err := rows.Scan(&r.From, &r.To, &r.Kind, &r.Prop); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// FindPaths ищет пути обходом в ширину (store.ShortestPaths), по запросу на слой: чтобы перечислить все кратчайшие
+// пути, нужны все связи между соседними слоями, а рекурсивный CTE по путям растёт экспоненциально.
+func (p *postgresStore) FindPaths(ctx context.Context, configID string, q store.PathQuery) ([][]snapshot.Relation, bool, error) {
+	q = q.Normalize()
+	for _, id := range []*string{&q.From, &q.To} {
+		stored, ok, err := p.resolveID(ctx, configID, *id)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		*id = stored
+	}
+	return store.ShortestPaths(q, func(frontier []string) ([]snapshot.Relation, error) {
+		rows, err := p.pool.Query(ctx,
+			`SELECT from_id, to_id, kind, prop FROM relations
+			 WHERE config_id = $1 AND from_id = ANY($2) AND (cardinality($3::text[]) = 0 OR kind = ANY($3))`,
+			configID, frontier, q.Kinds)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		var out []snapshot.Relation
+		for rows.Next() {
+			var r snapshot.Relation
+			if err := rows.Scan(&r.From, &r.To, &r.Kind, &r.Prop); err != nil {
+				return nil, err
+			}
+			out = append(out, r)
+		}
+		return out, rows.Err()
+	})
+}
+
+func (p *postgresStore) Subsystems(ctx context.Context, configID string) ([]snapshot.Object, error) {
+	rows, err := p.pool.Query(ctx,
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+		 FROM objects WHERE config_id = $1 AND LOWER(type) = $2 ORDER BY id`, configID, strings.ToLower(store.TypeSubsystem))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []snapshot.Object
+	for rows.Next() {
+		var o snapshot.Object
+		var propsJSON, tabSecJSON, formsJSON, modsJSON, subsJSON, contentJSON, registerJSON string
+		err := rows.Scan(&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &subsJSON, &contentJSON, &registerJSON, &o.Description)
+		if err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(propsJSON), &o.Props)
+		_ = json.Unmarshal([]byte(tabSecJSON), &o.TabularSections)
+		_ = json.Unmarshal([]byte(formsJSON), &o.Forms)
+		_ = json.Unmarshal([]byte(modsJSON), &o.Modules)
+		_ = json.Unmarshal([]byte(subsJSON), &o.Subsystems)
+		_ = json.Unmarshal([]byte(contentJSON), &o.Content)
+		_ = json.Unmarshal([]byte(registerJSON), &o.Register)
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (p *postgresStore) ObjectModules(ctx context.Context, configID, objectID string) ([]store.ModuleSource, error) {
+	id, ok, err := p.resolveID(ctx, configID, objectID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	rows, err := p.pool.Query(ctx,
+		`SELECT object_id, object_type, module, path, source FROM module_sources
+		 WHERE config_id = $1 AND object_id = $2 ORDER BY position`, configID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []store.ModuleSource
+	for rows.Next() {
+		var m store.ModuleSource
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.Module, &m.Path, &m.Source); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// SearchCode читает модули, подходящие под фильтры, по одному и ищет в них строки в Go (store.CodeSearch):
+// синтаксис регулярных выражений RE2 одинаков для всех backend, в отличие от операторов ~ и ~* Postgres.
+func (p *postgresStore) SearchCode(ctx context.Context, configID string, q store.CodeQuery) ([]store.CodeMatch, bool, error) {
+	search, err := store.NewCodeSearch(q)
+	if err != nil {
+		return nil, false, err
+	}
+	q = search.Query()
+	rows, err := p.pool.Query(ctx,
+		`SELECT object_id, object_type, module, path, source FROM module_sources
+		 WHERE config_id = $1 AND ($2 = '' OR LOWER(object_type) = $2) AND ($3 = '' OR object_id = $3) AND ($4 = '' OR LOWER(module) = $4)
+		 ORDER BY object_id COLLATE "C", module COLLATE "C"`,
+		configID, strings.ToLower(q.ObjectType), q.ObjectID, strings.ToLower(q.Module))
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m store.ModuleSource
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.Module, &m.Path, &m.Source); err != nil {
+			return nil, false, err
+		}
+		if !search.Module(&m) {
+			break
+		}
+	}
+	return search.Matches, search.More, rows.Err()
+}
+
+// FindMethods выбирает методы из module_methods; имя сравнивается по name_lower, точное совпадение идёт по индексу.
+func (p *postgresStore) FindMethods(ctx context.Context, configID string, q store.MethodQuery) ([]store.ModuleMethod, bool, error) {
+	q = q.Normalize()
+	rows, err := p.pool.Query(ctx,
+		`SELECT object_id, object_type, object_name, module, method_json FROM module_methods
+		 WHERE config_id = $1
+		   AND CASE $3 WHEN 'prefix' THEN starts_with(name_lower, $2) WHEN 'contains' THEN strpos(name_lower, $2) > 0 ELSE name_lower = $2 END
+		   AND ($4 = '' OR LOWER(object_type) = $4) AND ($5 = '' OR object_id = $5) AND ($6 = '' OR LOWER(object_name) = $6)
+		   AND ($7 = '' OR LOWER(module) = $7) AND (NOT $8 OR export)
+		 ORDER BY object_id COLLATE "C", module COLLATE "C", line
+		 LIMIT $9`,
+		configID, q.Name, q.Match, q.ObjectType, q.ObjectID, q.ObjectName, q.Module, q.ExportOnly, q.Limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	out := []store.ModuleMethod{}
+	for rows.Next() {
+		var m store.ModuleMethod
+		var data string
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.ObjectName, &m.Module, &data); err != nil {
+			return nil, false, err
+		}
+		if err := json.Unmarshal([]byte(data), &m.Method); err != nil {
+			return nil, false, fmt.Errorf("method %s.%s: %w", m.ObjectID, m.Module, err)
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if len(out) > q.Limit {
+		return out[:q.Limit], true, nil
+	}
+	return out, false, nil
+}
+
+func (p *postgresStore) ListTypes(ctx context.Context, configID string) ([]store.TypeCount, error) {
+	rows, err := p.pool.Query(ctx, `SELECT type, COUNT(*)::bigint FROM objects WHERE config_id = $1 GROUP BY type ORDER BY type`, configID)
 	if err != nil {
 		return nil, err
 	}
@@ -147,14 +566,26 @@ func (p *postgresStore) ListTypes(ctx context.Context) ([]store.TypeCount, error
 	return out, rows.Err()
 }
 
-func (p *postgresStore) Meta(ctx context.Context) (snapshot.Meta, error) {
-	keys := []string{"configName", "configVersion", "exportedAt", "source", "objectCount"}
+func (p *postgresStore) Meta(ctx context.Context, configID string) (snapshot.Meta, error) {
+	return readMeta(ctx, p.pool, configID)
+}
+
+// querier — общее у пула и транзакции, чтобы читать одни и те же данные внутри импорта.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func readMeta(ctx context.Context, q querier, configID string) (snapshot.Meta, error) {
+	rows, err := q.Query(ctx, `SELECT key, value FROM meta WHERE config_id = $1`, configID)
+	if err != nil {
+		return snapshot.Meta{}, err
+	}
+	defer rows.Close()
 	m := snapshot.Meta{}
-	for _, k := range keys {
-		var v string
-		err := p.pool.QueryRow(ctx, `SELECT value FROM meta WHERE key = $1`, k).Scan(&v)
-		if err != nil {
-			continue
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return snapshot.Meta{}, err
 		}
 		switch k {
 		case "configName":
@@ -167,9 +598,40 @@ func (p *postgresStore) Meta(ctx context.Context) (snapshot.Meta, error) {
 			m.Source = v
 		case "objectCount":
 			fmt.Sscanf(v, "%d", &m.ObjectCount)
+		case "indexVersion":
+			fmt.Sscanf(v, "%d", &m.IndexVersion)
 		}
 	}
-	return m, nil
+	return m, rows.Err()
+}
+
+func (p *postgresStore) ListConfigs(ctx context.Context) ([]store.ConfigInfo, error) {
+	rows, err := p.pool.Query(ctx, `SELECT DISTINCT config_id FROM meta ORDER BY config_id`)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]store.ConfigInfo, 0, len(ids))
+	for _, id := range ids {
+		m, err := p.Meta(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, store.ConfigInfo{ID: id, Meta: m})
+	}
+	return out, nil
 }
 
 func (p *postgresStore) Close() error {
@@ -178,20 +640,3 @@ func (p *postgresStore) Close() error {
 	}
 	return nil
 }
-
-func normalizeID(id string) string {
-	id = strings.TrimSpace(id)
-	if idx := strings.Index(id, "."); idx > 0 {
-		prefix := strings.ToLower(id[:idx])
-		name := id[idx+1:]
-		short := map[string]string{
-			"document": "doc", "catalog": "cat", "commonmodule": "commonmodule",
-			"report": "report", "dataprocessor": "dataprocessor",
-		}
-		if s, ok := short[prefix]; ok {
-			return s + "." + name
-		}
-		return prefix + "." + name
-	}
-	return id
-}
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/storetest"
+)
+
+// Общие проверки хранилища идут на базе из MCP_1C_STRUCTURE_TEST_DATABASE_URL; без неё тест пропускается.
+// Run пишет только в конфигурацию storetest.ConfigID.
+func TestStore(t *testing.T) {
+	url := os.Getenv("MCP_1C_STRUCTURE_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("MCP_1C_STRUCTURE_TEST_DATABASE_URL is not set")
+	}
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := New(url, "apply")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
@@ -1,9 +1,12 @@
 -- name: GetMeta :one
-SELECT value FROM meta WHERE key = $1;
+SELECT value FROM meta WHERE config_id = $1 AND key = $2;
 
 -- name: SetMeta :exec
-INSERT INTO meta (key, value) VALUES ($1, $2)
-ON CONFLICT (key) DO UPDATE SET value = $2;
+INSERT INTO meta (config_id, key, value) VALUES ($1, $2, $3)
+ON CONFLICT (config_id, key) DO UPDATE SET value = $3;
 
 -- name: ListMeta :many
-SELECT key, value FROM meta;
+SELECT key, value FROM meta WHERE config_id = $1;
+
+-- name: ListConfigIDs :many
+SELECT DISTINCT config_id FROM meta ORDER BY config_id;
@@ -1,29 +1,46 @@
 -- name: GetObject :one
-SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description
-FROM objects WHERE id = $1;
+SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+FROM objects WHERE config_id = $1 AND id = $2;
 
 -- name: SearchObjects :many
-SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description
+SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
 FROM objects
-WHERE ($1::text = '' OR LOWER(name) LIKE '%' || LOWER($1) || '%' OR LOWER(synonym) LIKE '%' || LOWER($1) || '%')
-  AND ($2::text = '' OR LOWER(type) = LOWER($2))
+WHERE config_id = $1
+  AND ($2::text = '' OR LOWER(name) LIKE '%' || LOWER($2) || '%' OR LOWER(synonym) LIKE '%' || LOWER($2) || '%')
+  AND ($3::text = '' OR LOWER(type) = LOWER($3))
 ORDER BY name
-LIMIT $3 OFFSET $4;
+LIMIT $4 OFFSET $5;
 
 -- name: SearchObjectsCount :one
 SELECT COUNT(*)
 FROM objects
-WHERE ($1::text = '' OR LOWER(name) LIKE '%' || LOWER($1) || '%' OR LOWER(synonym) LIKE '%' || LOWER($1) || '%')
-  AND ($2::text = '' OR LOWER(type) = LOWER($2));
+WHERE config_id = $1
+  AND ($2::text = '' OR LOWER(name) LIKE '%' || LOWER($2) || '%' OR LOWER(synonym) LIKE '%' || LOWER($2) || '%')
+  AND ($3::text = '' OR LOWER(type) = LOWER($3));
 
 -- name: InsertObject :exec
-INSERT INTO objects (id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-ON CONFLICT (id) DO UPDATE SET
-  type = $2, name = $3, synonym = $4, props_json = $5, tabular_sections_json = $6, forms = $7, modules = $8, description = $9;
+INSERT INTO objects (config_id, id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (config_id, id) DO UPDATE SET
+  type = $3, name = $4, synonym = $5, props_json = $6, tabular_sections_json = $7, forms = $8, modules = $9,
+  subsystems = $10, content = $11, register_json = $12, description = $13;
 
 -- name: ObjectExists :one
-SELECT EXISTS(SELECT 1 FROM objects WHERE id = $1);
+SELECT EXISTS(SELECT 1 FROM objects WHERE config_id = $1 AND id = $2);
 
 -- name: ListTypes :many
-SELECT type, COUNT(*)::bigint AS count FROM objects GROUP BY type ORDER BY type;
+SELECT type, COUNT(*)::bigint AS count FROM objects WHERE config_id = $1 GROUP BY type ORDER BY type;
+
+-- name: SearchObjectsFullText :many
+SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description,
+       ts_rank(@weights::float4[], search_tsv, q) AS score
+FROM objects, plainto_tsquery('russian', @query::text) q
+WHERE config_id = @config_id AND search_tsv @@ q
+  AND (@type_filter::text = '' OR LOWER(type) = @type_filter)
+ORDER BY score DESC, name
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: ListSubsystems :many
+SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+FROM objects WHERE config_id = $1 AND LOWER(type) = 'subsystem'
+ORDER BY id;
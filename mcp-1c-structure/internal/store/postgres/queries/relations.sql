@@ -1,12 +1,49 @@
 -- name: FindIncoming :many
-SELECT from_id, to_id, kind FROM relations WHERE to_id = $1
-  AND ($2::text = '' OR kind = $2)
-LIMIT $3;
+SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = $1 AND to_id = $2
+  AND ($3::text = '' OR kind = $3)
+LIMIT $4;
 
 -- name: FindOutgoing :many
-SELECT from_id, to_id, kind FROM relations WHERE from_id = $1
-  AND ($2::text = '' OR kind = $2)
-LIMIT $3;
+SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = $1 AND from_id = $2
+  AND ($3::text = '' OR kind = $3)
+LIMIT $4;
 
 -- name: InsertRelation :exec
-INSERT INTO relations (from_id, to_id, kind) VALUES ($1, $2, $3);
+INSERT INTO relations (config_id, from_id, to_id, kind, prop) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING;
+
+-- name: ImpactIncoming :many
+WITH RECURSIVE walk (id, depth, path, kind, prop) AS (
+  SELECT $2::text, 0, ARRAY[$2::text], ''::text, ''::text
+  UNION ALL
+  SELECT s.id, s.depth, s.path, s.kind, s.prop FROM (
+    SELECT r.from_id AS id, w.depth + 1 AS depth, w.path || r.from_id AS path, r.kind, r.prop,
+           row_number() OVER (PARTITION BY r.from_id ORDER BY w.path, r.kind, r.prop) AS rn
+    FROM walk w
+    JOIN relations r ON r.config_id = $1 AND r.to_id = w.id
+    WHERE w.depth < $3 AND NOT r.from_id = ANY(w.path) AND (cardinality($4::text[]) = 0 OR r.kind = ANY($4::text[]))
+  ) s WHERE s.rn = 1
+)
+SELECT w.id, w.depth, w.path, w.kind, w.prop, COALESCE(o.type, '') AS type, COALESCE(o.name, '') AS name
+FROM (SELECT DISTINCT ON (id) id, depth, path, kind, prop FROM walk WHERE depth > 0 ORDER BY id, depth, path, kind, prop) w
+LEFT JOIN objects o ON o.config_id = $1 AND o.id = w.id
+ORDER BY w.depth, w.id
+LIMIT $5;
+
+-- name: ImpactOutgoing :many
+WITH RECURSIVE walk (id, depth, path, kind, prop) AS (
+  SELECT $2::text, 0, ARRAY[$2::text], ''::text, ''::text
+  UNION ALL
+  SELECT s.id, s.depth, s.path, s.kind, s.prop FROM (
+    SELECT r.to_id AS id, w.depth + 1 AS depth, w.path || r.to_id AS path, r.kind, r.prop,
+           row_number() OVER (PARTITION BY r.to_id ORDER BY w.path, r.kind, r.prop) AS rn
+    FROM walk w
+    JOIN relations r ON r.config_id = $1 AND r.from_id = w.id
+    WHERE w.depth < $3 AND NOT r.to_id = ANY(w.path) AND (cardinality($4::text[]) = 0 OR r.kind = ANY($4::text[]))
+  ) s WHERE s.rn = 1
+)
+SELECT w.id, w.depth, w.path, w.kind, w.prop, COALESCE(o.type, '') AS type, COALESCE(o.name, '') AS name
+FROM (SELECT DISTINCT ON (id) id, depth, path, kind, prop FROM walk WHERE depth > 0 ORDER BY id, depth, path, kind, prop) w
+LEFT JOIN objects o ON o.config_id = $1 AND o.id = w.id
+ORDER BY w.depth, w.id
+LIMIT $5;
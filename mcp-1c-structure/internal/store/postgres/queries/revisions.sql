@@ -0,0 +1,28 @@
+-- name: NextRevision :one
+SELECT COALESCE(MAX(revision), 0) + 1 FROM revisions WHERE config_id = $1;
+
+-- name: InsertRevision :exec
+INSERT INTO revisions (config_id, revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListRevisions :many
+SELECT revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at
+FROM revisions WHERE config_id = $1 ORDER BY revision;
+
+-- name: GetRevision :one
+SELECT revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at
+FROM revisions WHERE config_id = $1 AND revision = $2;
+
+-- name: InsertRevisionObject :exec
+INSERT INTO revision_objects (config_id, revision, id, object_json) VALUES ($1, $2, $3, $4)
+ON CONFLICT (config_id, revision, id) DO UPDATE SET object_json = $4;
+
+-- name: ListRevisionObjects :many
+SELECT object_json FROM revision_objects WHERE config_id = $1 AND revision = $2 ORDER BY id;
+
+-- name: CopyRevisionRelations :exec
+INSERT INTO revision_relations (config_id, revision, from_id, to_id, kind, prop)
+SELECT config_id, $2, from_id, to_id, kind, prop FROM relations WHERE config_id = $1;
+
+-- name: ListRevisionRelations :many
+SELECT from_id, to_id, kind, prop FROM revision_relations WHERE config_id = $1 AND revision = $2;
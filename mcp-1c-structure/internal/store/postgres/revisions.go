@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+func (p *postgresStore) ListRevisions(ctx context.Context, configID string) ([]store.Revision, error) {
+	rows, err := p.pool.Query(ctx,
+		`SELECT revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at
+		 FROM revisions WHERE config_id = $1 ORDER BY revision`, configID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []store.Revision
+	for rows.Next() {
+		var r store.Revision
+		if err := rows.Scan(&r.Number, &r.Meta.ConfigName, &r.Meta.ConfigVersion, &r.Meta.ExportedAt, &r.Meta.Source, &r.Meta.ObjectCount, &r.Meta.IndexVersion, &r.ImportedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (p *postgresStore) LoadRevision(ctx context.Context, configID string, number int) (store.RevisionData, bool, error) {
+	var d store.RevisionData
+	err := p.pool.QueryRow(ctx,
+		`SELECT revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at
+		 FROM revisions WHERE config_id = $1 AND revision = $2`, configID, number).
+		Scan(&d.Number, &d.Meta.ConfigName, &d.Meta.ConfigVersion, &d.Meta.ExportedAt, &d.Meta.Source, &d.Meta.ObjectCount, &d.Meta.IndexVersion, &d.ImportedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.RevisionData{}, false, nil
+		}
+		return store.RevisionData{}, false, err
+	}
+	rows, err := p.pool.Query(ctx, `SELECT object_json FROM revision_objects WHERE config_id = $1 AND revision = $2 ORDER BY id`, configID, number)
+	if err != nil {
+		return store.RevisionData{}, false, err
+	}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return store.RevisionData{}, false, err
+		}
+		var o snapshot.Object
+		if err := json.Unmarshal([]byte(data), &o); err != nil {
+			rows.Close()
+			return store.RevisionData{}, false, fmt.Errorf("revision %d object: %w", number, err)
+		}
+		d.Objects = append(d.Objects, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return store.RevisionData{}, false, err
+	}
+	rows, err = p.pool.Query(ctx, `SELECT from_id, to_id, kind, prop FROM revision_relations WHERE config_id = $1 AND revision = $2`, configID, number)
+	if err != nil {
+		return store.RevisionData{}, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r snapshot.Relation
+		if err := rows.Scan(&r.From, &r.To, &r.Kind, &r.Prop); err != nil {
+			return store.RevisionData{}, false, err
+		}
+		d.Relations = append(d.Relations, r)
+	}
+	return d, true, rows.Err()
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// KindReference — вид связи «реквизит объекта ссылается на другой объект». Такие связи Import выводит
+// из типов реквизитов (PropRefs) и объединяет со связями из relations.json.
+const KindReference = "reference"
+
+// refTypes — префиксы ссылочных типов реквизитов (в нижнем регистре, английское и русское написание)
+// и тип метаданных объекта, на который они ссылаются.
+var refTypes = map[string]string{
+	"catalogref":                    "Catalog",
+	"справочникссылка":              "Catalog",
+	"documentref":                   "Document",
+	"документссылка":                "Document",
+	"enumref":                       "Enum",
+	"перечислениессылка":            "Enum",
+	"chartofaccountsref":            "ChartOfAccounts",
+	"плансчетовссылка":              "ChartOfAccounts",
+	"chartofcharacteristictypesref": "ChartOfCharacteristicTypes",
+	"планвидовхарактеристикссылка": "ChartOfCharacteristicTypes",
+	"chartofcalculationtypesref":   "ChartOfCalculationTypes",
+	"планвидоврасчетассылка":       "ChartOfCalculationTypes",
+	"exchangeplanref":     "ExchangePlan",
+	"планобменассылка":    "ExchangePlan",
+	"businessprocessref":  "BusinessProcess",
+	"бизнеспроцессссылка": "BusinessProcess",
+	"taskref":             "Task",
+	"задачассылка":        "Task",
+}
+
+// PropRef — связь reference, выведенная из типа реквизита. To — нормализованный id цели (cat.Контрагенты),
+// FullID — с полным именем типа (Catalog.Контрагенты): это IDVariants(To) в порядке предпочтения. Связь сохраняется
+// с тем из них, под которым цель лежит в снимке (ResolveID).
+type PropRef struct {
+	snapshot.Relation
+	FullID string
+}
+
+// PropPath — путь реквизита в объекте: имя реквизита или ТабличнаяЧасть.Колонка.
+func PropPath(section, name string) string {
+	if section == "" {
+		return name
+	}
+	return section + "." + name
+}
+
+// TypeRefs разбирает тип реквизита и возвращает ссылки на объекты: для CatalogRef.Контрагенты —
+// cat.Контрагенты и Catalog.Контрагенты. Составной тип перечисляет типы через запятую; прочие типы пропускаются.
+func TypeRefs(propType string) (ids, fullIDs []string) {
+	parts := strings.FieldsFunc(propType, func(r rune) bool { return r == ',' || r == ';' || unicode.IsSpace(r) })
+	for _, part := range parts {
+		prefix, name, ok := strings.Cut(part, ".")
+		if !ok || name == "" {
+			continue
+		}
+		metaType, ok := refTypes[strings.ToLower(strings.ReplaceAll(prefix, "ё", "е"))]
+		if !ok {
+			continue
+		}
+		full := metaType + "." + name
+		ids = append(ids, NormalizeID(full))
+		fullIDs = append(fullIDs, full)
+	}
+	return ids, fullIDs
+}
+
+// PropRefs возвращает связи reference от объекта o к объектам, на которые ссылаются типы его реквизитов
+// и колонок табличных частей: по одной на пару (путь реквизита, цель). Есть ли цели в снимке, проверяет вызывающий.
+func PropRefs(o *snapshot.Object) []PropRef {
+	from := o.ID
+	var out []PropRef
+	add := func(section string, props []snapshot.Prop) {
+		for _, p := range props {
+			out = appendPropRefs(out, from, PropPath(section, p.Name), p.Type)
+		}
+	}
+	add("", o.Props)
+	for _, ts := range o.TabularSections {
+		add(ts.Name, ts.Props)
+	}
+	return out
+}
+
+// PropTypeRefs — то же для одного реквизита, когда реквизиты читаются из object_props, а не из объекта.
+func PropTypeRefs(objectID, section, name, propType string) []PropRef {
+	return appendPropRefs(nil, objectID, PropPath(section, name), propType)
+}
+
+func appendPropRefs(out []PropRef, from, path, propType string) []PropRef {
+	ids, fullIDs := TypeRefs(propType)
+	for i, to := range ids {
+		if containsString(ids[:i], to) {
+			continue
+		}
+		out = append(out, PropRef{Relation: snapshot.Relation{From: from, To: to, Kind: KindReference, Prop: path}, FullID: fullIDs[i]})
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
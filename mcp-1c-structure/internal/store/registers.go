@@ -0,0 +1,57 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// KindRegisterRecord — вид связи «документ записывает движения в регистр» (свойство документа Движения):
+// from — документ-регистратор, to — регистр. Такие связи приходят в relations.json и проверяются при импорте.
+const KindRegisterRecord = "register_record"
+
+// TypeDocument — тип метаданных документа; регистраторами регистров могут быть только документы.
+const TypeDocument = "Document"
+
+// registerTypes — типы метаданных регистров (в нижнем регистре).
+var registerTypes = map[string]bool{
+	"informationregister":  true,
+	"accumulationregister": true,
+	"accountingregister":   true,
+	"calculationregister":  true,
+}
+
+// IsRegister сообщает, что тип метаданных — регистр сведений, накопления, бухгалтерии или расчёта.
+func IsRegister(objectType string) bool {
+	return registerTypes[strings.ToLower(objectType)]
+}
+
+// IsRecorder сообщает, что объект этого типа может быть регистратором, то есть документ.
+func IsRecorder(objectType string) bool {
+	return strings.EqualFold(objectType, TypeDocument)
+}
+
+// Подчинение регистра регистратору (RecorderMode).
+const (
+	RecorderRequired  = "required"  // регистры накопления, бухгалтерии, расчёта и регистр сведений с режимом записи RecorderSubordinate
+	RecorderForbidden = "forbidden" // регистр сведений с режимом записи Independent
+)
+
+// RecorderMode возвращает RecorderRequired или RecorderForbidden; пусто — не регистр или режим записи в снимке не указан.
+func RecorderMode(objectType string, r *snapshot.Register) string {
+	switch strings.ToLower(objectType) {
+	case "accumulationregister", "accountingregister", "calculationregister":
+		return RecorderRequired
+	case "informationregister":
+		if r == nil {
+			return ""
+		}
+		switch {
+		case strings.EqualFold(r.WriteMode, "RecorderSubordinate"):
+			return RecorderRequired
+		case strings.EqualFold(r.WriteMode, "Independent"):
+			return RecorderForbidden
+		}
+	}
+	return ""
+}
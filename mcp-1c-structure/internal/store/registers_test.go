@@ -0,0 +1,86 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+func TestRecorderMode(t *testing.T) {
+	tests := []struct {
+		objectType string
+		register   *snapshot.Register
+		want       string
+	}{
+		{"AccumulationRegister", nil, RecorderRequired},
+		{"accountingregister", nil, RecorderRequired},
+		{"InformationRegister", &snapshot.Register{WriteMode: "RecorderSubordinate"}, RecorderRequired},
+		{"InformationRegister", &snapshot.Register{WriteMode: "independent"}, RecorderForbidden},
+		{"InformationRegister", nil, ""},
+		{"Catalog", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := RecorderMode(tt.objectType, tt.register); got != tt.want {
+			t.Errorf("RecorderMode(%s, %+v) = %q, want %q", tt.objectType, tt.register, got, tt.want)
+		}
+	}
+}
+
+// Неверные связи register_record сохраняются с предупреждением; регистр без регистратора отмечается,
+// только если в снимке есть хотя бы одна связь register_record.
+func TestValidateRegisterRecords(t *testing.T) {
+	objects := []snapshot.Object{
+		{ID: "doc.Реализация", Type: "Document", Name: "Реализация"},
+		{ID: "cat.Склады", Type: "Catalog", Name: "Склады"},
+		{ID: "accumulationregister.Товары", Type: "AccumulationRegister", Name: "Товары"},
+		{ID: "accumulationregister.Взаиморасчеты", Type: "AccumulationRegister", Name: "Взаиморасчеты"},
+		{ID: "informationregister.Курсы", Type: "InformationRegister", Name: "Курсы", Register: &snapshot.Register{WriteMode: "Independent"}},
+	}
+	tests := []struct {
+		name      string
+		relations []snapshot.Relation
+		want      map[string]int // код предупреждения -> число
+	}{
+		{name: "без движений в снимке", want: map[string]int{}},
+		{name: "верная связь", relations: []snapshot.Relation{
+			{From: "Document.Реализация", To: "accumulationregister.Товары", Kind: KindRegisterRecord},
+		}, want: map[string]int{IssueNoRecorder: 1}},
+		{name: "неверные связи", relations: []snapshot.Relation{
+			{From: "doc.Реализация", To: "accumulationregister.Товары", Kind: KindRegisterRecord},
+			{From: "doc.Реализация", To: "accumulationregister.Взаиморасчеты", Kind: KindRegisterRecord},
+			{From: "cat.Склады", To: "accumulationregister.Товары", Kind: KindRegisterRecord},
+			{From: "doc.Реализация", To: "cat.Склады", Kind: KindRegisterRecord},
+			{From: "doc.Реализация", To: "informationregister.Курсы", Kind: KindRegisterRecord},
+		}, want: map[string]int{IssueInvalidRecord: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator()
+			for i := range objects {
+				o := objects[i]
+				v.Object(&o)
+			}
+			for _, r := range tt.relations {
+				if !v.Relation(&r) {
+					t.Errorf("relation %+v dropped", r)
+				}
+			}
+			v.Meta(snapshot.Meta{})
+			report := v.Report()
+			got := map[string]int{}
+			for _, issue := range report.Warnings {
+				if issue.Code == IssueInvalidRecord || issue.Code == IssueNoRecorder {
+					got[issue.Code] = issue.Count
+				}
+			}
+			if len(report.Errors) != 0 || len(got) != len(tt.want) {
+				t.Fatalf("got warnings %v, errors %+v; want %v", got, report.Errors, tt.want)
+			}
+			for code, n := range tt.want {
+				if got[code] != n {
+					t.Errorf("%s: got %d, want %d", code, got[code], n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// ApplyDelta applies a delta to the current snapshot of configID in a single transaction and stores the result as a new revision.
+// Deleted objects take their relations with them; the delta is validated against the objects it leaves in place
+// (store.Validator.Delta), and added relations are kept only if both ends exist after the delta.
+// Relations derived from prop types are rebuilt for the whole configuration, relations derived from code — for the replaced objects.
+// Unchanged objects of the new revision are copied from the previous one. Списки id передаются JSON-массивом и раскрываются json_each.
+func (s *sqliteStore) ApplyDelta(ctx context.Context, configID string, delta snapshot.Delta, opts store.ImportOptions) (store.ImportResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+	defer tx.Rollback()
+
+	var prev int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(revision), 0) FROM revisions WHERE config_id = ?1`, configID).Scan(&prev); err != nil {
+		return store.ImportResult{}, fmt.Errorf("current revision: %w", err)
+	}
+	if prev == 0 {
+		return store.ImportResult{}, fmt.Errorf("configuration %q has %w", configID, store.ErrNoRevisions)
+	}
+	rev := prev + 1
+	prevMeta, err := readMeta(ctx, tx, configID)
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+
+	deleted, replaced := []string{}, []string{}
+	for _, id := range delta.Deleted {
+		deleted = append(deleted, store.IDVariants(id)...)
+	}
+	replaced = append(replaced, deleted...)
+	for i := range delta.Upserted {
+		replaced = append(replaced, store.IDVariants(delta.Upserted[i].ID)...)
+	}
+	deletedJSON, _ := json.Marshal(deleted)
+	replacedJSON, _ := json.Marshal(replaced)
+	v, err := knownObjects(ctx, tx, configID, string(replacedJSON))
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+	added := v.Delta(&delta)
+	report := v.Report()
+	if opts.Strict && report.HasErrors() {
+		return store.ImportResult{Validation: report}, &store.ValidationError{Report: report}
+	}
+	if len(deleted) > 0 {
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM relations WHERE config_id = ?1
+			 AND (from_id IN (SELECT value FROM json_each(?2)) OR to_id IN (SELECT value FROM json_each(?2)))`,
+			configID, string(deletedJSON))
+		if err != nil {
+			return store.ImportResult{}, fmt.Errorf("delete relations of deleted objects: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM objects WHERE config_id = ?1 AND id IN (SELECT value FROM json_each(?2))`, configID, string(deletedJSON)); err != nil {
+			return store.ImportResult{}, fmt.Errorf("delete objects: %w", err)
+		}
+	}
+	// Заменённый объект приносит модули заново: прежние тексты удаляются, даже если у нового их нет.
+	for _, table := range []string{"module_methods", "module_sources"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE config_id = ?1 AND object_id IN (SELECT value FROM json_each(?2))`, configID, string(replacedJSON)); err != nil {
+			return store.ImportResult{}, fmt.Errorf("delete %s: %w", table, err)
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO revision_objects (config_id, revision, id, object_json)
+		 SELECT config_id, ?2, id, object_json FROM revision_objects
+		 WHERE config_id = ?1 AND revision = ?3 AND id NOT IN (SELECT value FROM json_each(?4))`,
+		configID, rev, prev, string(replacedJSON))
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("copy revision objects: %w", err)
+	}
+	ins, err := prepareObjectInserts(ctx, tx)
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+	defer ins.Close()
+	for i := range delta.Upserted {
+		if err := ins.insert(ctx, configID, rev, &delta.Upserted[i]); err != nil {
+			return store.ImportResult{}, err
+		}
+	}
+
+	for _, r := range delta.RemoveRelations {
+		_, err := tx.ExecContext(ctx, `DELETE FROM relations WHERE config_id = ?1 AND from_id = ?2 AND to_id = ?3 AND kind = ?4 AND prop = ?5`, configID, r.From, r.To, r.Kind, r.Prop)
+		if err != nil {
+			return store.ImportResult{}, fmt.Errorf("delete relation %s -> %s: %w", r.From, r.To, err)
+		}
+	}
+	for _, r := range added {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO relations (config_id, from_id, to_id, kind, prop) VALUES (?1, ?2, ?3, ?4, ?5) ON CONFLICT DO NOTHING`,
+			configID, r.From, r.To, r.Kind, r.Prop)
+		if err != nil {
+			return store.ImportResult{}, fmt.Errorf("insert relation %s -> %s: %w", r.From, r.To, err)
+		}
+	}
+
+	// Связи из кода пересчитываются только для заменённых объектов: тексты остальных модулей не менялись.
+	// Модуль, который не менялся, получит связь с добавленным дельтой объектом при следующем полном импорте.
+	codeKinds, _ := json.Marshal(store.CodeKinds)
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM relations WHERE config_id = ?1 AND from_id IN (SELECT value FROM json_each(?2)) AND kind IN (SELECT value FROM json_each(?3))`,
+		configID, string(replacedJSON), string(codeKinds)); err != nil {
+		return store.ImportResult{}, fmt.Errorf("delete code relations: %w", err)
+	}
+	if err := deriveCodeRelations(ctx, tx, configID, replaced); err != nil {
+		return store.ImportResult{}, err
+	}
+	// Связи из типов реквизитов пересчитываются целиком: изменённый объект мог поменять типы реквизитов,
+	// а новый — стать целью ссылки, которую прежде не удалось разрешить.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM relations WHERE config_id = ?1 AND kind = ?2 AND prop <> ''`, configID, store.KindReference); err != nil {
+		return store.ImportResult{}, fmt.Errorf("delete prop relations: %w", err)
+	}
+	if err := derivePropRelations(ctx, tx, configID, nil); err != nil {
+		return store.ImportResult{}, err
+	}
+
+	var objectCount int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM objects WHERE config_id = ?1`, configID).Scan(&objectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	meta := delta.MergeMeta(prevMeta)
+	meta.ObjectCount = objectCount
+	if err := writeMeta(ctx, tx, configID, meta, objectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := insertRevision(ctx, tx, configID, rev, meta, objectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := copyRevisionRelations(ctx, tx, configID, rev); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return store.ImportResult{}, err
+	}
+	return store.ImportResult{Revision: rev, Meta: meta, Validation: report}, nil
+}
+
+// knownObjects передаёт Validator объекты текущего снимка, которые дельта не заменяет и не удаляет (replacedJSON —
+// все написания их id): с ними проверяются связи дельты.
+func knownObjects(ctx context.Context, tx *sql.Tx, configID, replacedJSON string) (*store.Validator, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, type, register_json FROM objects WHERE config_id = ?1 AND id NOT IN (SELECT value FROM json_each(?2))`,
+		configID, replacedJSON)
+	if err != nil {
+		return nil, fmt.Errorf("read objects: %w", err)
+	}
+	defer rows.Close()
+	v := store.NewValidator()
+	for rows.Next() {
+		var id, typ, registerJSON string
+		if err := rows.Scan(&id, &typ, &registerJSON); err != nil {
+			return nil, err
+		}
+		var register *snapshot.Register
+		_ = json.Unmarshal([]byte(registerJSON), &register)
+		v.Known(id, typ, register)
+	}
+	return v, rows.Err()
+}
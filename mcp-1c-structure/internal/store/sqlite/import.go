@@ -0,0 +1,365 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// Import replaces the snapshot of configuration configID with the one read from src in a single transaction:
+// readers see either the previous snapshot or the new one, and a failed import leaves the previous data intact.
+// Objects and relations are streamed through prepared statements one by one; only object ids are kept in memory.
+// The snapshot is checked by store.Validator on the fly: relations with a missing end are dropped and reported,
+// duplicate edges are dropped silently. Relations are then derived from module code (deriveCodeRelations)
+// and reference relations from prop types (derivePropRelations).
+// With opts.Strict the import is rolled back if the report has errors.
+// The imported snapshot is also kept as the next numbered revision of the configuration.
+func (s *sqliteStore) Import(ctx context.Context, configID string, src snapshot.Source, opts store.ImportOptions) (store.ImportResult, error) {
+	started := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"relations", "module_methods", "module_sources", "objects", "meta"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE config_id = ?1`, configID); err != nil {
+			return store.ImportResult{}, fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+	var rev int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(revision), 0) + 1 FROM revisions WHERE config_id = ?1`, configID).Scan(&rev); err != nil {
+		return store.ImportResult{}, fmt.Errorf("next revision: %w", err)
+	}
+	res := store.ImportResult{Revision: rev}
+	res.Stats.Clear = time.Since(started)
+
+	phase := time.Now()
+	ins, err := prepareObjectInserts(ctx, tx)
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+	defer ins.Close()
+	v := store.NewValidator()
+	err = src.Objects(func(o snapshot.Object) error {
+		v.Object(&o)
+		return ins.insert(ctx, configID, rev, &o)
+	})
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("objects: %w", err)
+	}
+	// объект с повторным id заменяет предыдущий, поэтому считаются строки, а не прочитанные объекты
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM objects WHERE config_id = ?1`, configID).Scan(&res.ObjectCount); err != nil {
+		return store.ImportResult{}, fmt.Errorf("count objects: %w", err)
+	}
+	res.Stats.Objects = time.Since(phase)
+
+	phase = time.Now()
+	relStmt, err := tx.PrepareContext(ctx, `INSERT INTO relations (config_id, from_id, to_id, kind, prop) VALUES (?1, ?2, ?3, ?4, ?5) ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return store.ImportResult{}, err
+	}
+	defer relStmt.Close()
+	err = src.Relations(func(r snapshot.Relation) error {
+		res.RelationCount++
+		if !v.Relation(&r) {
+			return nil
+		}
+		if _, err := relStmt.ExecContext(ctx, configID, r.From, r.To, r.Kind, r.Prop); err != nil {
+			return fmt.Errorf("insert relation %s -> %s: %w", r.From, r.To, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("relations: %w", err)
+	}
+	if err := deriveCodeRelations(ctx, tx, configID, nil); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := derivePropRelations(ctx, tx, configID, v); err != nil {
+		return store.ImportResult{}, err
+	}
+	res.Stats.Relations = time.Since(phase)
+
+	phase = time.Now()
+	meta, err := src.Meta()
+	if err != nil {
+		return store.ImportResult{}, fmt.Errorf("meta: %w", err)
+	}
+	v.Meta(meta)
+	res.Validation = v.Report()
+	if opts.Strict && res.Validation.HasErrors() {
+		return res, &store.ValidationError{Report: res.Validation}
+	}
+	meta.ObjectCount = res.ObjectCount
+	if err := insertRevision(ctx, tx, configID, rev, meta, res.ObjectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := writeMeta(ctx, tx, configID, meta, res.ObjectCount); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := copyRevisionRelations(ctx, tx, configID, rev); err != nil {
+		return store.ImportResult{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return store.ImportResult{}, err
+	}
+	res.Stats.Finalize = time.Since(phase)
+	res.Stats.Total = time.Since(started)
+	res.Meta = meta
+	return res, nil
+}
+
+// propRefs — ссылки из типов реквизитов конфигурации ?1, разобранные функцией prop_refs по строкам object_props;
+// target — id, под которым цель лежит в снимке: нормализованный, иначе полный (store.ResolveID), NULL — цели нет.
+const propRefs = `WITH parsed AS (
+	SELECT r.value->>'from' AS from_id, r.value->>'to' AS to_id, r.value->>'fullId' AS full_id, r.value->>'prop' AS prop
+	FROM object_props p, json_each(prop_refs(p.object_id, p.section, p.name, p.type)) r
+	WHERE p.config_id = ?1 AND p.type LIKE '%.%'
+), refs AS (
+	SELECT from_id, to_id, prop, COALESCE(
+		(SELECT o.id FROM objects o WHERE o.config_id = ?1 AND o.id = parsed.to_id),
+		(SELECT o.id FROM objects o WHERE o.config_id = ?1 AND o.id = parsed.full_id)) AS target
+	FROM parsed
+)`
+
+// derivePropRelations добавляет связи reference из типов реквизитов и колонок табличных частей, если цель есть в снимке,
+// с id цели, под которым она хранится;
+// ссылки на отсутствующие объекты попадают в отчёт v (nil — не сообщать). Связь из relations.json без prop,
+// повторяющая выведенную (те же from, to, kind), удаляется: выведенная несёт путь реквизита.
+func derivePropRelations(ctx context.Context, tx *sql.Tx, configID string, v *store.Validator) error {
+	_, err := tx.ExecContext(ctx, propRefs+`
+		INSERT INTO relations (config_id, from_id, to_id, kind, prop)
+		SELECT ?1, from_id, target, ?2, prop FROM refs WHERE target IS NOT NULL
+		ON CONFLICT DO NOTHING`, configID, store.KindReference)
+	if err != nil {
+		return fmt.Errorf("derive prop relations: %w", err)
+	}
+	if v != nil {
+		rows, err := tx.QueryContext(ctx, propRefs+`
+			SELECT from_id, to_id, prop FROM refs WHERE target IS NULL`, configID)
+		if err != nil {
+			return fmt.Errorf("unresolved prop references: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			r := snapshot.Relation{Kind: store.KindReference}
+			if err := rows.Scan(&r.From, &r.To, &r.Prop); err != nil {
+				return err
+			}
+			v.UnresolvedPropRef(&r)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM relations WHERE config_id = ?1 AND prop = '' AND EXISTS (
+			SELECT 1 FROM relations d WHERE d.config_id = relations.config_id AND d.from_id = relations.from_id
+			AND d.to_id = relations.to_id AND d.kind = relations.kind AND d.prop <> '')`, configID)
+	if err != nil {
+		return fmt.Errorf("merge prop relations: %w", err)
+	}
+	return nil
+}
+
+// deriveCodeRelations добавляет связи из кода модулей (store.CodeRelations) объектов objectIDs, nil — всех модулей
+// конфигурации. В памяти копятся только связи: запрос и вставки идут по одному соединению, пишутся после чтения.
+func deriveCodeRelations(ctx context.Context, tx *sql.Tx, configID string, objectIDs []string) error {
+	stored, err := objectIDSet(ctx, tx, configID)
+	if err != nil {
+		return err
+	}
+	resolve := func(id string) (string, bool) {
+		return store.ResolveID(id, func(id string) bool { return stored[id] })
+	}
+	q := `SELECT object_id, object_type, module, path, source FROM module_sources WHERE config_id = ?1`
+	args := []any{configID}
+	if objectIDs != nil {
+		ids, err := json.Marshal(objectIDs)
+		if err != nil {
+			return err
+		}
+		q += ` AND object_id IN (SELECT value FROM json_each(?2))`
+		args = append(args, string(ids))
+	}
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("read module sources: %w", err)
+	}
+	var relations []snapshot.Relation
+	for rows.Next() {
+		var m store.ModuleSource
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.Module, &m.Path, &m.Source); err != nil {
+			rows.Close()
+			return err
+		}
+		relations = append(relations, store.CodeRelations(&m, resolve)...)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(relations) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO relations (config_id, from_id, to_id, kind, prop) VALUES (?1, ?2, ?3, ?4, ?5) ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range relations {
+		if _, err := stmt.ExecContext(ctx, configID, r.From, r.To, r.Kind, r.Prop); err != nil {
+			return fmt.Errorf("insert code relation %s -> %s: %w", r.From, r.To, err)
+		}
+	}
+	return nil
+}
+
+// objectIDSet возвращает id объектов конфигурации.
+func objectIDSet(ctx context.Context, tx *sql.Tx, configID string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM objects WHERE config_id = ?1`, configID)
+	if err != nil {
+		return nil, fmt.Errorf("read object ids: %w", err)
+	}
+	defer rows.Close()
+	set := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		set[id] = true
+	}
+	return set, rows.Err()
+}
+
+// objectInserts — подготовленные запросы записи объекта в текущий снимок и в ревизию, текстов его модулей и их методов.
+type objectInserts struct {
+	object, revision, module, clearMethods, method *sql.Stmt
+}
+
+func prepareObjectInserts(ctx context.Context, tx *sql.Tx) (*objectInserts, error) {
+	objStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO objects (config_id, id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description)
+		 VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13)
+		 ON CONFLICT (config_id, id) DO UPDATE SET type=?3, name=?4, synonym=?5, props_json=?6, tabular_sections_json=?7, forms=?8, modules=?9,
+		   subsystems=?10, content=?11, register_json=?12, description=?13`)
+	if err != nil {
+		return nil, err
+	}
+	revStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO revision_objects (config_id, revision, id, object_json) VALUES (?1, ?2, ?3, ?4)
+		 ON CONFLICT (config_id, revision, id) DO UPDATE SET object_json = ?4`)
+	if err != nil {
+		objStmt.Close()
+		return nil, err
+	}
+	modStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO module_sources (config_id, object_id, module, position, object_type, path, source) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+		 ON CONFLICT (config_id, object_id, module) DO UPDATE SET position = ?4, object_type = ?5, path = ?6, source = ?7`)
+	if err != nil {
+		objStmt.Close()
+		revStmt.Close()
+		return nil, err
+	}
+	ins := &objectInserts{object: objStmt, revision: revStmt, module: modStmt}
+	ins.clearMethods, err = tx.PrepareContext(ctx, `DELETE FROM module_methods WHERE config_id = ?1 AND object_id = ?2 AND module = ?3`)
+	if err != nil {
+		ins.Close()
+		return nil, err
+	}
+	ins.method, err = tx.PrepareContext(ctx,
+		`INSERT INTO module_methods (config_id, object_id, module, line, name, name_lower, object_type, object_name, export, method_json)
+		 VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)`)
+	if err != nil {
+		ins.Close()
+		return nil, err
+	}
+	return ins, nil
+}
+
+func (ins *objectInserts) insert(ctx context.Context, configID string, rev int, o *snapshot.Object) error {
+	cols, err := store.ObjectColumns(o)
+	if err != nil {
+		return err
+	}
+	if _, err := ins.object.ExecContext(ctx, configID, o.ID, o.Type, o.Name, o.Synonym, cols.Props, cols.TabularSections, cols.Forms, cols.Modules, cols.Subsystems, cols.Content, cols.Register, o.Description); err != nil {
+		return fmt.Errorf("insert object %s: %w", o.ID, err)
+	}
+	if _, err := ins.revision.ExecContext(ctx, configID, rev, o.ID, cols.Object); err != nil {
+		return fmt.Errorf("insert revision object %s: %w", o.ID, err)
+	}
+	for i, m := range store.SourcesOf(o) {
+		if _, err := ins.module.ExecContext(ctx, configID, m.ObjectID, m.Module, i+1, m.ObjectType, m.Path, m.Source); err != nil {
+			return fmt.Errorf("insert module %s.%s: %w", o.ID, m.Module, err)
+		}
+		// Модуль заменяется целиком, вместе с методами прежнего текста.
+		if _, err := ins.clearMethods.ExecContext(ctx, configID, m.ObjectID, m.Module); err != nil {
+			return fmt.Errorf("clear methods %s.%s: %w", o.ID, m.Module, err)
+		}
+		for _, mm := range store.ModuleMethods(&m, o.Name) {
+			data, err := store.MethodJSON(&mm)
+			if err != nil {
+				return err
+			}
+			if _, err := ins.method.ExecContext(ctx, configID, mm.ObjectID, mm.Module, mm.Line, mm.Name, strings.ToLower(mm.Name), mm.ObjectType, mm.ObjectName, mm.Export, data); err != nil {
+				return fmt.Errorf("insert method %s.%s.%s: %w", o.ID, m.Module, mm.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (ins *objectInserts) Close() {
+	for _, st := range []*sql.Stmt{ins.object, ins.revision, ins.module, ins.clearMethods, ins.method} {
+		if st != nil {
+			st.Close()
+		}
+	}
+}
+
+func insertRevision(ctx context.Context, tx *sql.Tx, configID string, rev int, meta snapshot.Meta, objectCount int) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO revisions (config_id, revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at)
+		 VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)`,
+		configID, rev, meta.ConfigName, meta.ConfigVersion, meta.ExportedAt, meta.Source, objectCount, meta.IndexVersion, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+	return nil
+}
+
+func writeMeta(ctx context.Context, tx *sql.Tx, configID string, meta snapshot.Meta, objectCount int) error {
+	metaValues := [][2]string{
+		{"configName", meta.ConfigName},
+		{"configVersion", meta.ConfigVersion},
+		{"exportedAt", meta.ExportedAt},
+		{"source", meta.Source},
+		{"indexVersion", fmt.Sprintf("%d", meta.IndexVersion)},
+		{"objectCount", fmt.Sprintf("%d", objectCount)},
+	}
+	for _, kv := range metaValues {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO meta (config_id, key, value) VALUES (?1, ?2, ?3) ON CONFLICT (config_id, key) DO UPDATE SET value = ?3`, configID, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyRevisionRelations(ctx context.Context, tx *sql.Tx, configID string, rev int) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO revision_relations (config_id, revision, from_id, to_id, kind, prop)
+		 SELECT config_id, ?2, from_id, to_id, kind, prop FROM relations WHERE config_id = ?1`,
+		configID, rev)
+	if err != nil {
+		return fmt.Errorf("copy revision relations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrate применяет встроенные миграции, которых ещё нет в файле. Номер последней применённой
+// хранится в PRAGMA user_version; файлы называются NNNN_описание.sql. Файл новее бинарника — ошибка;
+// в режиме config.MigrateCheck схема не меняется, и ошибкой считаются также неприменённые миграции.
+func migrate(ctx context.Context, db *sql.DB, mode string) error {
+	var current int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&current); err != nil {
+		return err
+	}
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return err
+	}
+	versions := make([]int, len(entries))
+	for i, e := range entries {
+		prefix, _, _ := strings.Cut(e.Name(), "_")
+		versions[i], err = strconv.Atoi(prefix)
+		if err != nil {
+			return fmt.Errorf("migration %s: bad version prefix", e.Name())
+		}
+	}
+	latest := versions[len(versions)-1]
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d): upgrade mcp-1c-structure", current, latest)
+	}
+	if current < latest && mode == config.MigrateCheck {
+		return fmt.Errorf("database schema version %d is behind this binary (%d): run with -migrate=apply", current, latest)
+	}
+	for i, e := range entries {
+		version := versions[i]
+		if version <= current {
+			continue
+		}
+		body, err := migrationsFS.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return err
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, version)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		current = version
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+)
+
+// В режиме check схема не меняется: файла нет или миграции не применены — ошибка; после apply проверка проходит.
+func TestMigrateCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "structure.db")
+	dsn := "sqlite://" + path
+	if _, err := New(dsn, config.MigrateCheck); err == nil {
+		t.Fatal("check on a missing file: want error")
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Fatal("check must not create the database file")
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(dsn, config.MigrateCheck); err == nil || !strings.Contains(err.Error(), "behind") {
+		t.Fatalf("check on an empty database: want \"behind\" error, got %v", err)
+	}
+
+	s, err := New(dsn, config.MigrateApply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	s, err = New(dsn, config.MigrateCheck)
+	if err != nil {
+		t.Fatalf("check after apply: %v", err)
+	}
+	s.Close()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`PRAGMA user_version = 9999`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	for _, mode := range []string{config.MigrateCheck, config.MigrateApply} {
+		if _, err := New(dsn, mode); err == nil || !strings.Contains(err.Error(), "newer") {
+			t.Errorf("%s on a newer schema: want \"newer\" error, got %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+-- Схема совпадает с migrations/00001_initial.sql и 00002_relations_unique.sql; pg_trgm в SQLite нет,
+-- регистронезависимый поиск по-русски идёт через функцию ru_lower (см. sqlite.go).
+CREATE TABLE IF NOT EXISTS meta (
+    key   TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS objects (
+    id                    TEXT PRIMARY KEY,
+    type                  TEXT NOT NULL,
+    name                  TEXT NOT NULL,
+    synonym               TEXT NOT NULL DEFAULT '',
+    props_json            TEXT NOT NULL DEFAULT '[]',
+    tabular_sections_json TEXT NOT NULL DEFAULT '[]',
+    forms                 TEXT NOT NULL DEFAULT '[]',
+    modules               TEXT NOT NULL DEFAULT '[]',
+    description           TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_objects_type ON objects(type);
+CREATE INDEX IF NOT EXISTS idx_objects_name ON objects(name);
+
+CREATE TABLE IF NOT EXISTS relations (
+    from_id TEXT NOT NULL,
+    to_id   TEXT NOT NULL,
+    kind    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_relations_from ON relations(from_id);
+CREATE INDEX IF NOT EXISTS idx_relations_to ON relations(to_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_relations_unique ON relations(from_id, to_id, kind);
@@ -0,0 +1,39 @@
+-- Несколько конфигураций в одной базе (как migrations/00003_configs.sql).
+-- SQLite не меняет первичный ключ на месте, поэтому meta и objects пересоздаются.
+CREATE TABLE meta_new (
+    config_id TEXT NOT NULL DEFAULT 'default',
+    key       TEXT NOT NULL,
+    value     TEXT NOT NULL,
+    PRIMARY KEY (config_id, key)
+);
+INSERT INTO meta_new (key, value) SELECT key, value FROM meta;
+DROP TABLE meta;
+ALTER TABLE meta_new RENAME TO meta;
+
+CREATE TABLE objects_new (
+    config_id             TEXT NOT NULL DEFAULT 'default',
+    id                    TEXT NOT NULL,
+    type                  TEXT NOT NULL,
+    name                  TEXT NOT NULL,
+    synonym               TEXT NOT NULL DEFAULT '',
+    props_json            TEXT NOT NULL DEFAULT '[]',
+    tabular_sections_json TEXT NOT NULL DEFAULT '[]',
+    forms                 TEXT NOT NULL DEFAULT '[]',
+    modules               TEXT NOT NULL DEFAULT '[]',
+    description           TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (config_id, id)
+);
+INSERT INTO objects_new (id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description)
+SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, description FROM objects;
+DROP TABLE objects;
+ALTER TABLE objects_new RENAME TO objects;
+CREATE INDEX idx_objects_type ON objects(config_id, type);
+CREATE INDEX idx_objects_name ON objects(config_id, name);
+
+ALTER TABLE relations ADD COLUMN config_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_relations_from;
+DROP INDEX IF EXISTS idx_relations_to;
+DROP INDEX IF EXISTS idx_relations_unique;
+CREATE INDEX idx_relations_from ON relations(config_id, from_id);
+CREATE INDEX idx_relations_to ON relations(config_id, to_id);
+CREATE UNIQUE INDEX idx_relations_unique ON relations(config_id, from_id, to_id, kind);
@@ -0,0 +1,32 @@
+-- История импортов (как migrations/00004_revisions.sql); imported_at — RFC 3339 в UTC.
+CREATE TABLE revisions (
+    config_id      TEXT NOT NULL,
+    revision       INTEGER NOT NULL,
+    config_name    TEXT NOT NULL DEFAULT '',
+    config_version TEXT NOT NULL DEFAULT '',
+    exported_at    TEXT NOT NULL DEFAULT '',
+    source         TEXT NOT NULL DEFAULT '',
+    object_count   INTEGER NOT NULL DEFAULT 0,
+    imported_at    TEXT NOT NULL,
+    PRIMARY KEY (config_id, revision)
+);
+
+CREATE INDEX idx_revisions_version ON revisions(config_id, config_version);
+
+CREATE TABLE revision_objects (
+    config_id   TEXT NOT NULL,
+    revision    INTEGER NOT NULL,
+    id          TEXT NOT NULL,
+    object_json TEXT NOT NULL,
+    PRIMARY KEY (config_id, revision, id)
+);
+
+CREATE TABLE revision_relations (
+    config_id TEXT NOT NULL,
+    revision  INTEGER NOT NULL,
+    from_id   TEXT NOT NULL,
+    to_id     TEXT NOT NULL,
+    kind      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_revision_relations ON revision_relations(config_id, revision);
@@ -0,0 +1,2 @@
+-- Версия формата индекса снимка (meta.indexVersion) в ревизии.
+ALTER TABLE revisions ADD COLUMN index_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,41 @@
+-- Полнотекстовый поиск: индекс FTS5 по основам слов каждого поля объекта (функции search_text, search_props,
+-- search_tabular регистрируются в sqlite.go). Индекс без хранимого текста (content=''): поле совпадения
+-- определяется по самому объекту. object_search даёт объекту постоянный rowid в индексе. Всё ведётся триггерами
+-- на objects, поэтому импорт и дельта пишут только в objects.
+CREATE TABLE object_search (
+    search_id INTEGER PRIMARY KEY,
+    config_id TEXT NOT NULL,
+    id        TEXT NOT NULL,
+    UNIQUE (config_id, id)
+);
+
+-- remove_diacritics 0: иначе й совпадает с и.
+CREATE VIRTUAL TABLE object_search_fts USING fts5(
+    name, synonym, description, props, tabular_sections,
+    content = '', contentless_delete = 1, tokenize = 'unicode61 remove_diacritics 0'
+);
+
+CREATE TRIGGER objects_search_ai AFTER INSERT ON objects BEGIN
+    INSERT INTO object_search (config_id, id) VALUES (new.config_id, new.id);
+    INSERT INTO object_search_fts (rowid, name, synonym, description, props, tabular_sections)
+    VALUES (last_insert_rowid(), search_text(new.name), search_text(new.synonym), search_text(new.description),
+            search_props(new.props_json), search_tabular(new.tabular_sections_json));
+END;
+
+CREATE TRIGGER objects_search_au AFTER UPDATE ON objects BEGIN
+    UPDATE object_search_fts SET
+        name = search_text(new.name), synonym = search_text(new.synonym), description = search_text(new.description),
+        props = search_props(new.props_json), tabular_sections = search_tabular(new.tabular_sections_json)
+    WHERE rowid = (SELECT search_id FROM object_search WHERE config_id = old.config_id AND id = old.id);
+END;
+
+CREATE TRIGGER objects_search_ad AFTER DELETE ON objects BEGIN
+    DELETE FROM object_search_fts WHERE rowid = (SELECT search_id FROM object_search WHERE config_id = old.config_id AND id = old.id);
+    DELETE FROM object_search WHERE config_id = old.config_id AND id = old.id;
+END;
+
+INSERT INTO object_search (config_id, id) SELECT config_id, id FROM objects;
+INSERT INTO object_search_fts (rowid, name, synonym, description, props, tabular_sections)
+SELECT s.search_id, search_text(o.name), search_text(o.synonym), search_text(o.description),
+       search_props(o.props_json), search_tabular(o.tabular_sections_json)
+FROM objects o JOIN object_search s ON s.config_id = o.config_id AND s.id = o.id;
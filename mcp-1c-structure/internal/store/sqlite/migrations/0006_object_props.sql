@@ -0,0 +1,56 @@
+-- Реквизиты объектов и колонки табличных частей построчно — для поиска по имени и типу реквизита
+-- (structure_find_by_prop). Ведётся триггерами на objects из props_json и tabular_sections_json.
+-- Индексы по ru_lower: встроенный LOWER не понимает кириллицу.
+CREATE TABLE object_props (
+    config_id TEXT    NOT NULL,
+    object_id TEXT    NOT NULL,
+    section   TEXT    NOT NULL DEFAULT '', -- '' — реквизит объекта, иначе имя табличной части
+    position  INTEGER NOT NULL,            -- порядковый номер в объекте или табличной части, с 1
+    name      TEXT    NOT NULL,
+    type      TEXT    NOT NULL,
+    synonym   TEXT    NOT NULL
+);
+
+CREATE INDEX idx_object_props_object ON object_props(config_id, object_id);
+CREATE INDEX idx_object_props_name ON object_props(config_id, ru_lower(name));
+CREATE INDEX idx_object_props_type ON object_props(config_id, ru_lower(type));
+
+CREATE TRIGGER objects_props_ai AFTER INSERT ON objects BEGIN
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym)
+    SELECT new.config_id, new.id, '', p.key + 1,
+           COALESCE(p.value->>'name', ''), COALESCE(p.value->>'type', ''), COALESCE(p.value->>'synonym', '')
+    FROM json_each(CASE WHEN json_type(new.props_json) = 'array' THEN new.props_json ELSE '[]' END) p;
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym)
+    SELECT new.config_id, new.id, COALESCE(t.value->>'name', ''), c.key + 1,
+           COALESCE(c.value->>'name', ''), COALESCE(c.value->>'type', ''), COALESCE(c.value->>'synonym', '')
+    FROM json_each(CASE WHEN json_type(new.tabular_sections_json) = 'array' THEN new.tabular_sections_json ELSE '[]' END) t,
+         json_each(CASE WHEN json_type(t.value, '$.props') = 'array' THEN t.value->'props' ELSE '[]' END) c;
+END;
+
+CREATE TRIGGER objects_props_au AFTER UPDATE ON objects BEGIN
+    DELETE FROM object_props WHERE config_id = old.config_id AND object_id = old.id;
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym)
+    SELECT new.config_id, new.id, '', p.key + 1,
+           COALESCE(p.value->>'name', ''), COALESCE(p.value->>'type', ''), COALESCE(p.value->>'synonym', '')
+    FROM json_each(CASE WHEN json_type(new.props_json) = 'array' THEN new.props_json ELSE '[]' END) p;
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym)
+    SELECT new.config_id, new.id, COALESCE(t.value->>'name', ''), c.key + 1,
+           COALESCE(c.value->>'name', ''), COALESCE(c.value->>'type', ''), COALESCE(c.value->>'synonym', '')
+    FROM json_each(CASE WHEN json_type(new.tabular_sections_json) = 'array' THEN new.tabular_sections_json ELSE '[]' END) t,
+         json_each(CASE WHEN json_type(t.value, '$.props') = 'array' THEN t.value->'props' ELSE '[]' END) c;
+END;
+
+CREATE TRIGGER objects_props_ad AFTER DELETE ON objects BEGIN
+    DELETE FROM object_props WHERE config_id = old.config_id AND object_id = old.id;
+END;
+
+INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym)
+SELECT o.config_id, o.id, '', p.key + 1,
+       COALESCE(p.value->>'name', ''), COALESCE(p.value->>'type', ''), COALESCE(p.value->>'synonym', '')
+FROM objects o, json_each(CASE WHEN json_type(o.props_json) = 'array' THEN o.props_json ELSE '[]' END) p;
+INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym)
+SELECT o.config_id, o.id, COALESCE(t.value->>'name', ''), c.key + 1,
+       COALESCE(c.value->>'name', ''), COALESCE(c.value->>'type', ''), COALESCE(c.value->>'synonym', '')
+FROM objects o,
+     json_each(CASE WHEN json_type(o.tabular_sections_json) = 'array' THEN o.tabular_sections_json ELSE '[]' END) t,
+     json_each(CASE WHEN json_type(t.value, '$.props') = 'array' THEN t.value->'props' ELSE '[]' END) c;
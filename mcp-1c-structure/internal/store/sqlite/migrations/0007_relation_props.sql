@@ -0,0 +1,5 @@
+-- Путь реквизита у связей, выведенных из типов реквизитов (как migrations/00008_relation_props.sql).
+ALTER TABLE relations ADD COLUMN prop TEXT NOT NULL DEFAULT '';
+ALTER TABLE revision_relations ADD COLUMN prop TEXT NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_relations_unique;
+CREATE UNIQUE INDEX idx_relations_unique ON relations(config_id, from_id, to_id, kind, prop);
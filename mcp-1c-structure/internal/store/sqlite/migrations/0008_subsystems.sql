@@ -0,0 +1,3 @@
+-- Вложенные подсистемы и состав подсистем (как migrations/00009_subsystems.sql).
+ALTER TABLE objects ADD COLUMN subsystems TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE objects ADD COLUMN content TEXT NOT NULL DEFAULT '[]';
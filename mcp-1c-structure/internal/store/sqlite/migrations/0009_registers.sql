@@ -0,0 +1,43 @@
+-- Свойства регистров и вид поля регистра в object_props (как migrations/00010_registers.sql).
+ALTER TABLE objects ADD COLUMN register_json TEXT NOT NULL DEFAULT 'null';
+ALTER TABLE object_props ADD COLUMN kind TEXT NOT NULL DEFAULT '';
+
+DROP TRIGGER objects_props_ai;
+DROP TRIGGER objects_props_au;
+
+CREATE TRIGGER objects_props_ai AFTER INSERT ON objects BEGIN
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym, kind)
+    SELECT new.config_id, new.id, '', p.key + 1,
+           COALESCE(p.value->>'name', ''), COALESCE(p.value->>'type', ''), COALESCE(p.value->>'synonym', ''), COALESCE(p.value->>'kind', '')
+    FROM json_each(CASE WHEN json_type(new.props_json) = 'array' THEN new.props_json ELSE '[]' END) p;
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym, kind)
+    SELECT new.config_id, new.id, COALESCE(t.value->>'name', ''), c.key + 1,
+           COALESCE(c.value->>'name', ''), COALESCE(c.value->>'type', ''), COALESCE(c.value->>'synonym', ''), COALESCE(c.value->>'kind', '')
+    FROM json_each(CASE WHEN json_type(new.tabular_sections_json) = 'array' THEN new.tabular_sections_json ELSE '[]' END) t,
+         json_each(CASE WHEN json_type(t.value, '$.props') = 'array' THEN t.value->'props' ELSE '[]' END) c;
+END;
+
+CREATE TRIGGER objects_props_au AFTER UPDATE ON objects BEGIN
+    DELETE FROM object_props WHERE config_id = old.config_id AND object_id = old.id;
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym, kind)
+    SELECT new.config_id, new.id, '', p.key + 1,
+           COALESCE(p.value->>'name', ''), COALESCE(p.value->>'type', ''), COALESCE(p.value->>'synonym', ''), COALESCE(p.value->>'kind', '')
+    FROM json_each(CASE WHEN json_type(new.props_json) = 'array' THEN new.props_json ELSE '[]' END) p;
+    INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym, kind)
+    SELECT new.config_id, new.id, COALESCE(t.value->>'name', ''), c.key + 1,
+           COALESCE(c.value->>'name', ''), COALESCE(c.value->>'type', ''), COALESCE(c.value->>'synonym', ''), COALESCE(c.value->>'kind', '')
+    FROM json_each(CASE WHEN json_type(new.tabular_sections_json) = 'array' THEN new.tabular_sections_json ELSE '[]' END) t,
+         json_each(CASE WHEN json_type(t.value, '$.props') = 'array' THEN t.value->'props' ELSE '[]' END) c;
+END;
+
+DELETE FROM object_props;
+INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym, kind)
+SELECT o.config_id, o.id, '', p.key + 1,
+       COALESCE(p.value->>'name', ''), COALESCE(p.value->>'type', ''), COALESCE(p.value->>'synonym', ''), COALESCE(p.value->>'kind', '')
+FROM objects o, json_each(CASE WHEN json_type(o.props_json) = 'array' THEN o.props_json ELSE '[]' END) p;
+INSERT INTO object_props (config_id, object_id, section, position, name, type, synonym, kind)
+SELECT o.config_id, o.id, COALESCE(t.value->>'name', ''), c.key + 1,
+       COALESCE(c.value->>'name', ''), COALESCE(c.value->>'type', ''), COALESCE(c.value->>'synonym', ''), COALESCE(c.value->>'kind', '')
+FROM objects o,
+     json_each(CASE WHEN json_type(o.tabular_sections_json) = 'array' THEN o.tabular_sections_json ELSE '[]' END) t,
+     json_each(CASE WHEN json_type(t.value, '$.props') = 'array' THEN t.value->'props' ELSE '[]' END) c;
@@ -0,0 +1,11 @@
+-- Тексты модулей объектов (как migrations/00011_module_sources.sql): отдельно от objects и только для текущего снимка.
+CREATE TABLE module_sources (
+    config_id   TEXT    NOT NULL,
+    object_id   TEXT    NOT NULL,
+    module      TEXT    NOT NULL,
+    position    INTEGER NOT NULL, -- порядковый номер модуля в объекте, с 1
+    object_type TEXT    NOT NULL,
+    path        TEXT    NOT NULL DEFAULT '',
+    source      TEXT    NOT NULL,
+    PRIMARY KEY (config_id, object_id, module)
+);
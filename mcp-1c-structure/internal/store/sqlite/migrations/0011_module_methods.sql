@@ -0,0 +1,16 @@
+-- Процедуры и функции модулей (как migrations/00012_module_methods.sql): пишутся при импорте вместе с module_sources.
+CREATE TABLE module_methods (
+    config_id   TEXT    NOT NULL,
+    object_id   TEXT    NOT NULL,
+    module      TEXT    NOT NULL,
+    line        INTEGER NOT NULL, -- строка объявления, с 1
+    name        TEXT    NOT NULL,
+    name_lower  TEXT    NOT NULL,
+    object_type TEXT    NOT NULL,
+    object_name TEXT    NOT NULL,
+    export      INTEGER NOT NULL,
+    method_json TEXT    NOT NULL, -- bsl.Method целиком
+    PRIMARY KEY (config_id, object_id, module, line)
+);
+
+CREATE INDEX idx_module_methods_name ON module_methods (config_id, name_lower);
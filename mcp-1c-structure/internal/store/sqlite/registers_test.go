@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// Свойства регистра и виды полей сохраняются и отдаются как в снимке; по виду поля фильтрует FindByProp.
+func TestRegisterRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(t)
+	reg := snapshot.Object{ID: "informationregister.Курсы", Type: "InformationRegister", Name: "Курсы",
+		Props: []snapshot.Prop{
+			{Name: "Валюта", Type: "CatalogRef.Валюты", Kind: snapshot.PropDimension},
+			{Name: "Курс", Type: "Number", Kind: snapshot.PropResource},
+		},
+		Register: &snapshot.Register{Periodicity: "Day", WriteMode: "Independent"}}
+	if _, err := s.Import(ctx, "default", snapshot.FromSlices(snapshot.Meta{}, []snapshot.Object{reg}, nil), store.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := s.GetObject(ctx, "default", "InformationRegister.Курсы")
+	if err != nil || !ok {
+		t.Fatalf("GetObject: %v, %v", ok, err)
+	}
+	if !reflect.DeepEqual(got.Register, reg.Register) || !reflect.DeepEqual(got.Props, reg.Props) {
+		t.Errorf("got register %+v, props %+v", got.Register, got.Props)
+	}
+	list, total, err := s.FindByProp(ctx, "default", store.PropFilter{Kind: "resource"}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || list[0].Prop.Name != "Курс" {
+		t.Errorf("FindByProp kind=resource: got %+v of %d", list, total)
+	}
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+func (s *sqliteStore) ListRevisions(ctx context.Context, configID string) ([]store.Revision, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at
+		 FROM revisions WHERE config_id = ?1 ORDER BY revision`, configID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []store.Revision
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqliteStore) LoadRevision(ctx context.Context, configID string, number int) (store.RevisionData, bool, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT revision, config_name, config_version, exported_at, source, object_count, index_version, imported_at
+		 FROM revisions WHERE config_id = ?1 AND revision = ?2`, configID, number)
+	r, err := scanRevision(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.RevisionData{}, false, nil
+		}
+		return store.RevisionData{}, false, err
+	}
+	d := store.RevisionData{Revision: r}
+	rows, err := s.db.QueryContext(ctx, `SELECT object_json FROM revision_objects WHERE config_id = ?1 AND revision = ?2 ORDER BY id`, configID, number)
+	if err != nil {
+		return store.RevisionData{}, false, err
+	}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return store.RevisionData{}, false, err
+		}
+		var o snapshot.Object
+		if err := json.Unmarshal([]byte(data), &o); err != nil {
+			rows.Close()
+			return store.RevisionData{}, false, fmt.Errorf("revision %d object: %w", number, err)
+		}
+		d.Objects = append(d.Objects, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return store.RevisionData{}, false, err
+	}
+	d.Relations, err = s.queryRelations(ctx, `SELECT from_id, to_id, kind, prop FROM revision_relations WHERE config_id = ?1 AND revision = ?2`, configID, number)
+	if err != nil {
+		return store.RevisionData{}, false, err
+	}
+	return d, true, nil
+}
+
+func scanRevision(row rowScanner) (store.Revision, error) {
+	var r store.Revision
+	var importedAt string
+	if err := row.Scan(&r.Number, &r.Meta.ConfigName, &r.Meta.ConfigVersion, &r.Meta.ExportedAt, &r.Meta.Source, &r.Meta.ObjectCount, &r.Meta.IndexVersion, &importedAt); err != nil {
+		return store.Revision{}, err
+	}
+	r.ImportedAt, _ = time.Parse(time.RFC3339, importedAt)
+	return r, nil
+}
@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+)
+
+// bm25 на маленьком снимке порядка 1e-6: score относится к лучшему совпадению, чтобы не округляться до нуля.
+func TestSearchFullTextScore(t *testing.T) {
+	s := importSnapshot(t)
+	hits, total, err := s.SearchFullText(context.Background(), "default", "контрагенты", "", 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total == 0 || len(hits) != total {
+		t.Fatalf("want all hits on one page, got %d of %d", len(hits), total)
+	}
+	if hits[0].Score != 1 {
+		t.Errorf("best hit %s: want score 1, got %g", hits[0].Object.ID, hits[0].Score)
+	}
+	for i, h := range hits {
+		if h.Score <= 0 || h.Score > 1 || (i > 0 && h.Score > hits[i-1].Score) {
+			t.Errorf("%s: score %g out of (0, 1] or out of order", h.Object.ID, h.Score)
+		}
+	}
+}
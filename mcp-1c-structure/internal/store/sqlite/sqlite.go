@@ -0,0 +1,685 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/search"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	sqlitedrv "modernc.org/sqlite"
+)
+
+// Встроенный LOWER в SQLite понимает только ASCII; ru_lower даёт то же, что LOWER в Postgres для кириллицы.
+func init() {
+	sqlitedrv.MustRegisterDeterministicScalarFunction("ru_lower", 1, func(_ *sqlitedrv.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return strings.ToLower(v), nil
+		case []byte:
+			return strings.ToLower(string(v)), nil
+		default:
+			return v, nil
+		}
+	})
+	// Основы слов для полнотекстового индекса object_search_fts (миграция 0005): триггеры на objects вызывают эти функции.
+	sqlitedrv.MustRegisterDeterministicScalarFunction("search_text", 1, func(_ *sqlitedrv.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return search.Text(search.Terms(textArg(args[0]))), nil
+	})
+	sqlitedrv.MustRegisterDeterministicScalarFunction("search_props", 1, func(_ *sqlitedrv.FunctionContext, args []driver.Value) (driver.Value, error) {
+		var props []snapshot.Prop
+		_ = json.Unmarshal([]byte(textArg(args[0])), &props)
+		return search.Text(search.PropTerms(props)), nil
+	})
+	sqlitedrv.MustRegisterDeterministicScalarFunction("search_tabular", 1, func(_ *sqlitedrv.FunctionContext, args []driver.Value) (driver.Value, error) {
+		var sections []snapshot.TabularSection
+		_ = json.Unmarshal([]byte(textArg(args[0])), &sections)
+		return search.Text(search.TabularTerms(sections)), nil
+	})
+	// prop_refs(object_id, section, name, type) — связи reference из типа реквизита (store.PropTypeRefs) JSON-массивом
+	// объектов from, to, fullId, prop (store.PropRef): по нему Import и ApplyDelta выводят связи из object_props.
+	sqlitedrv.MustRegisterDeterministicScalarFunction("prop_refs", 4, func(_ *sqlitedrv.FunctionContext, args []driver.Value) (driver.Value, error) {
+		refs := store.PropTypeRefs(textArg(args[0]), textArg(args[1]), textArg(args[2]), textArg(args[3]))
+		out := make([]map[string]string, len(refs))
+		for i, r := range refs {
+			out[i] = map[string]string{"from": r.From, "to": r.To, "fullId": r.FullID, "prop": r.Prop}
+		}
+		data, err := json.Marshal(out)
+		return string(data), err
+	})
+}
+
+func textArg(v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// New открывает файл базы и применяет или проверяет встроенные миграции (migrateMode — config.MigrateApply или config.MigrateCheck).
+// В режиме проверки отсутствующий файл не создаётся.
+func New(dsn, migrateMode string) (store.Store, error) {
+	path, err := Path(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if migrateMode == config.MigrateCheck {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("schema: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// Один writer на файл: так не ловим SQLITE_BUSY между соединениями пула.
+	db.SetMaxOpenConns(1)
+	if err := migrate(context.Background(), db, migrateMode); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+// Path извлекает путь к файлу из DSN sqlite://...
+func Path(dsn string) (string, error) {
+	const scheme = "sqlite://"
+	if len(dsn) < len(scheme) || !strings.EqualFold(dsn[:len(scheme)], scheme) {
+		return "", fmt.Errorf("sqlite DSN must start with %s", scheme)
+	}
+	path := dsn[len(scheme):]
+	if path == "" {
+		return "", errors.New("sqlite DSN has empty path")
+	}
+	return path, nil
+}
+
+func (s *sqliteStore) Search(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]snapshot.Object, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	query = strings.TrimSpace(strings.ToLower(query))
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	likeQ := "%" + query + "%"
+	var total int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM objects WHERE config_id = ?1 AND (?2 = '' OR ru_lower(name) LIKE ?3 OR ru_lower(synonym) LIKE ?3) AND (?4 = '' OR ru_lower(type) = ?4)`,
+		configID, query, likeQ, typeFilter).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+		 FROM objects WHERE config_id = ?1 AND (?2 = '' OR ru_lower(name) LIKE ?3 OR ru_lower(synonym) LIKE ?3) AND (?4 = '' OR ru_lower(type) = ?4)
+		 ORDER BY name LIMIT ?5 OFFSET ?6`,
+		configID, query, likeQ, typeFilter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []snapshot.Object
+	for rows.Next() {
+		o, err := scanObject(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, o)
+	}
+	return list, total, rows.Err()
+}
+
+// SearchFullText ищет по индексу FTS5 над основами слов (object_search_fts); релевантность — bm25 с весами полей search.Weights,
+// отнесённый к лучшему совпадению запроса.
+func (s *sqliteStore) SearchFullText(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]store.SearchHit, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	terms := search.QueryTerms(query)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+	// Основы состоят из букв и цифр, но кавычки не дают FTS5 принять слово запроса за оператор (AND, NOT, NEAR).
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+	}
+	match := strings.Join(quoted, " ")
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	const from = ` FROM object_search_fts
+		 JOIN object_search s ON s.search_id = object_search_fts.rowid
+		 JOIN objects o ON o.config_id = s.config_id AND o.id = s.id
+		 WHERE object_search_fts MATCH ?2 AND s.config_id = ?1 AND (?3 = '' OR ru_lower(o.type) = ?3)`
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, configID, match, typeFilter).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	w := search.Weights
+	rank := fmt.Sprintf(`-bm25(object_search_fts, %g, %g, %g, %g, %g) AS score`, w[0], w[1], w[2], w[3], w[4])
+	// bm25 не ограничен сверху, а на небольшом снимке порядка 1e-6 (FTS5 не даёт IDF опуститься до нуля):
+	// score делится на bm25 лучшего совпадения запроса и лежит в (0, 1]. bm25 нельзя брать в агрегате,
+	// поэтому лучшее — отдельным запросом.
+	var top float64
+	if err := s.db.QueryRowContext(ctx, `SELECT `+rank+from+` ORDER BY score DESC LIMIT 1`, configID, match, typeFilter).Scan(&top); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT o.id, o.type, o.name, o.synonym, o.props_json, o.tabular_sections_json, o.forms, o.modules, o.subsystems, o.content, o.register_json, o.description,
+		 `+rank+from+`
+		 ORDER BY score DESC, o.name LIMIT ?4 OFFSET ?5`,
+		configID, match, typeFilter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var hits []store.SearchHit
+	for rows.Next() {
+		var h store.SearchHit
+		o, err := scanObject(rows, &h.Score)
+		if err != nil {
+			return nil, 0, err
+		}
+		if top > 0 {
+			h.Score /= top
+		}
+		h.Object = o
+		h.MatchedField = search.MatchedField(&o, terms)
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+// SearchFuzzy читает имена и синонимы объектов конфигурации и сравнивает их триграммы с вариантами запроса в Go
+// (в SQLite нет pg_trgm); полностью загружаются только объекты выбранной страницы.
+func (s *sqliteStore) SearchFuzzy(ctx context.Context, configID string, variants []search.Variant, typeFilter string, limit, offset int) ([]store.SearchHit, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	fuzzy := search.NewFuzzy(variants)
+	typeFilter = strings.TrimSpace(strings.ToLower(typeFilter))
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, synonym FROM objects WHERE config_id = ?1 AND (?2 = '' OR ru_lower(type) = ?2) ORDER BY name`, configID, typeFilter)
+	if err != nil {
+		return nil, 0, err
+	}
+	var hits []store.SearchHit
+	for rows.Next() {
+		var id, name, synonym string
+		if err := rows.Scan(&id, &name, &synonym); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		if score, field, v, ok := fuzzy.Match(name, synonym); ok {
+			hits = append(hits, store.SearchHit{Object: snapshot.Object{ID: id}, Score: score, MatchedField: field, Variant: v})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	total := len(hits)
+	if offset >= total {
+		return nil, total, nil
+	}
+	hits = hits[offset:min(offset+limit, total)]
+	for i := range hits {
+		row := s.db.QueryRowContext(ctx,
+			`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description FROM objects WHERE config_id = ?1 AND id = ?2`,
+			configID, hits[i].Object.ID)
+		if hits[i].Object, err = scanObject(row); err != nil {
+			return nil, 0, err
+		}
+	}
+	return hits, total, nil
+}
+
+func (s *sqliteStore) GetObject(ctx context.Context, configID, id string) (snapshot.Object, bool, error) {
+	id, ok, err := s.resolveID(ctx, configID, id)
+	if err != nil || !ok {
+		return snapshot.Object{}, false, err
+	}
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description FROM objects WHERE config_id = ?1 AND id = ?2`, configID, id)
+	o, err := scanObject(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return snapshot.Object{}, false, nil
+		}
+		return snapshot.Object{}, false, err
+	}
+	return o, true, nil
+}
+
+// resolveID возвращает id, под которым объект хранится (store.ResolveID): все написания id проверяются одним запросом.
+func (s *sqliteStore) resolveID(ctx context.Context, configID, id string) (string, bool, error) {
+	variants, _ := json.Marshal(store.IDVariants(id))
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM objects WHERE config_id = ?1 AND id IN (SELECT value FROM json_each(?2))`, configID, string(variants))
+	if err != nil {
+		return "", false, err
+	}
+	defer rows.Close()
+	found := make(map[string]bool)
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return "", false, err
+		}
+		found[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", false, err
+	}
+	stored, ok := store.ResolveID(id, func(id string) bool { return found[id] })
+	return stored, ok, nil
+}
+
+// FindByProp ищет по таблице object_props (миграция 0006), которую ведут триггеры на objects.
+func (s *sqliteStore) FindByProp(ctx context.Context, configID string, filter store.PropFilter, limit, offset int) ([]store.PropMatch, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	args := []any{configID,
+		strings.ToLower(strings.TrimSpace(filter.Name)), strings.ToLower(strings.TrimSpace(filter.Type)),
+		strings.ToLower(strings.TrimSpace(filter.TabularSection)), strings.ToLower(strings.TrimSpace(filter.ObjectType)),
+		strings.ToLower(strings.TrimSpace(filter.Kind))}
+	const from = ` FROM object_props p JOIN objects o ON o.config_id = p.config_id AND o.id = p.object_id
+		 WHERE p.config_id = ?1 AND (?2 = '' OR ru_lower(p.name) = ?2) AND (?3 = '' OR ru_lower(p.type) = ?3)
+		   AND (?4 = '' OR (p.section <> '' AND ru_lower(p.section) = ?4)) AND (?5 = '' OR ru_lower(o.type) = ?5)
+		   AND (?6 = '' OR p.kind = ?6)`
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT o.id, o.type, o.name, o.synonym, p.section, p.name, p.type, p.synonym, p.kind`+from+`
+		 ORDER BY o.name, o.id, p.section <> '', p.section, p.position LIMIT ?7 OFFSET ?8`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []store.PropMatch
+	for rows.Next() {
+		var m store.PropMatch
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.ObjectName, &m.ObjectSynonym, &m.TabularSection, &m.Prop.Name, &m.Prop.Type, &m.Prop.Synonym, &m.Prop.Kind); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, m)
+	}
+	return list, total, rows.Err()
+}
+
+func (s *sqliteStore) FindReferences(ctx context.Context, configID, id, direction, kind string, limit int) (incoming, outgoing []snapshot.Relation, err error) {
+	id, ok, err := s.resolveID(ctx, configID, id)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	wantIn := direction == "incoming" || direction == "both" || direction == ""
+	wantOut := direction == "outgoing" || direction == "both" || direction == ""
+	if wantIn {
+		incoming, err = s.queryRelations(ctx, `SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = ?1 AND to_id = ?2 AND (?3 = '' OR kind = ?3) LIMIT ?4`, configID, id, kind, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if wantOut {
+		outgoing, err = s.queryRelations(ctx, `SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = ?1 AND from_id = ?2 AND (?3 = '' OR kind = ?3) LIMIT ?4`, configID, id, kind, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return incoming, outgoing, nil
+}
+
+// ImpactAnalysis обходит связи слоями (store.WalkImpact): одна выборка связей на слой, id слоя — JSON-массивом.
+// Рекурсивный CTE, как в Postgres, здесь не подходит: SQLite не разрешает оконные функции в рекурсивной части.
+func (s *sqliteStore) ImpactAnalysis(ctx context.Context, configID string, q store.ImpactQuery) ([]store.ImpactNode, bool, error) {
+	q = q.Normalize()
+	column := "to_id"
+	if q.Direction == "outgoing" {
+		column = "from_id"
+	}
+	nodes, truncated, err := store.WalkImpact(q, s.layerEdges(ctx, configID, column, q.Kinds))
+	if err != nil || len(nodes) == 0 {
+		return nodes, truncated, err
+	}
+	ids := make([]string, len(nodes))
+	for i := range nodes {
+		ids[i] = nodes[i].ID
+	}
+	idsJSON, _ := json.Marshal(ids)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, type, name FROM objects WHERE config_id = ?1 AND id IN (SELECT value FROM json_each(?2))`, configID, string(idsJSON))
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	names := make(map[string][2]string, len(nodes))
+	for rows.Next() {
+		var id, typ, name string
+		if err := rows.Scan(&id, &typ, &name); err != nil {
+			return nil, false, err
+		}
+		names[id] = [2]string{typ, name}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	for i := range nodes {
+		nodes[i].Type, nodes[i].Name = names[nodes[i].ID][0], names[nodes[i].ID][1]
+	}
+	return nodes, truncated, nil
+}
+
+func (s *sqliteStore) FindPaths(ctx context.Context, configID string, q store.PathQuery) ([][]snapshot.Relation, bool, error) {
+	q = q.Normalize()
+	for _, id := range []*string{&q.From, &q.To} {
+		stored, ok, err := s.resolveID(ctx, configID, *id)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		*id = stored
+	}
+	return store.ShortestPaths(q, s.layerEdges(ctx, configID, "from_id", q.Kinds))
+}
+
+func (s *sqliteStore) RelationsAmong(ctx context.Context, configID string, ids, kinds []string) ([]snapshot.Relation, error) {
+	if kinds == nil {
+		kinds = []string{}
+	}
+	idsJSON, _ := json.Marshal(ids)
+	kindsJSON, _ := json.Marshal(kinds)
+	return s.queryRelations(ctx,
+		`SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = ?1
+		 AND from_id IN (SELECT value FROM json_each(?2)) AND to_id IN (SELECT value FROM json_each(?2))
+		 AND (json_array_length(?3) = 0 OR kind IN (SELECT value FROM json_each(?3)))
+		 ORDER BY from_id, to_id, kind, prop`,
+		configID, string(idsJSON), string(kindsJSON))
+}
+
+// layerEdges выбирает связи слоя обхода одним запросом: column (from_id или to_id) — из id слоя, kinds — фильтр видов.
+func (s *sqliteStore) layerEdges(ctx context.Context, configID, column string, kinds []string) store.LayerEdges {
+	kindsJSON, _ := json.Marshal(kinds)
+	return func(frontier []string) ([]snapshot.Relation, error) {
+		ids, _ := json.Marshal(frontier)
+		return s.queryRelations(ctx,
+			`SELECT from_id, to_id, kind, prop FROM relations WHERE config_id = ?1 AND `+column+` IN (SELECT value FROM json_each(?2))
+			 AND (json_array_length(?3) = 0 OR kind IN (SELECT value FROM json_each(?3)))`,
+			configID, string(ids), string(kindsJSON))
+	}
+}
+
+func (s *sqliteStore) queryRelations(ctx context.Context, q string, args ...any) ([]snapshot.Relation, error) {
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []snapshot.Relation
+	for rows.Next() {
+		var r snapshot.Relation
+		if err := rows.Scan(&r.From, &r.To, &r.Kind, &r.Prop); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqliteStore) Subsystems(ctx context.Context, configID string) ([]snapshot.Object, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, type, name, synonym, props_json, tabular_sections_json, forms, modules, subsystems, content, register_json, description
+		 FROM objects WHERE config_id = ?1 AND ru_lower(type) = ?2 ORDER BY id`, configID, strings.ToLower(store.TypeSubsystem))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []snapshot.Object
+	for rows.Next() {
+		o, err := scanObject(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqliteStore) ObjectModules(ctx context.Context, configID, objectID string) ([]store.ModuleSource, error) {
+	id, ok, err := s.resolveID(ctx, configID, objectID)
+	if err != nil || !ok {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT object_id, object_type, module, path, source FROM module_sources
+		 WHERE config_id = ?1 AND object_id = ?2 ORDER BY position`, configID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []store.ModuleSource
+	for rows.Next() {
+		var m store.ModuleSource
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.Module, &m.Path, &m.Source); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// SearchCode читает модули, подходящие под фильтры, по одному и ищет в них строки в Go (store.CodeSearch):
+// регулярные выражения и кириллица без учёта регистра одинаково работают во всех backend.
+func (s *sqliteStore) SearchCode(ctx context.Context, configID string, q store.CodeQuery) ([]store.CodeMatch, bool, error) {
+	search, err := store.NewCodeSearch(q)
+	if err != nil {
+		return nil, false, err
+	}
+	q = search.Query()
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT object_id, object_type, module, path, source FROM module_sources
+		 WHERE config_id = ?1 AND (?2 = '' OR ru_lower(object_type) = ?2) AND (?3 = '' OR object_id = ?3) AND (?4 = '' OR ru_lower(module) = ?4)
+		 ORDER BY object_id, module`,
+		configID, strings.ToLower(q.ObjectType), q.ObjectID, strings.ToLower(q.Module))
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m store.ModuleSource
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.Module, &m.Path, &m.Source); err != nil {
+			return nil, false, err
+		}
+		if !search.Module(&m) {
+			break
+		}
+	}
+	return search.Matches, search.More, rows.Err()
+}
+
+// FindMethods выбирает методы из module_methods; имя сравнивается по name_lower, точное совпадение идёт по индексу.
+func (s *sqliteStore) FindMethods(ctx context.Context, configID string, q store.MethodQuery) ([]store.ModuleMethod, bool, error) {
+	q = q.Normalize()
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT object_id, object_type, object_name, module, method_json FROM module_methods
+		 WHERE config_id = ?1
+		   AND CASE ?3 WHEN 'prefix' THEN substr(name_lower, 1, length(?2)) = ?2 WHEN 'contains' THEN instr(name_lower, ?2) > 0 ELSE name_lower = ?2 END
+		   AND (?4 = '' OR ru_lower(object_type) = ?4) AND (?5 = '' OR object_id = ?5) AND (?6 = '' OR ru_lower(object_name) = ?6)
+		   AND (?7 = '' OR ru_lower(module) = ?7) AND (NOT ?8 OR export)
+		 ORDER BY object_id, module, line
+		 LIMIT ?9`,
+		configID, q.Name, q.Match, q.ObjectType, q.ObjectID, q.ObjectName, q.Module, q.ExportOnly, q.Limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	out := []store.ModuleMethod{}
+	for rows.Next() {
+		var m store.ModuleMethod
+		var data string
+		if err := rows.Scan(&m.ObjectID, &m.ObjectType, &m.ObjectName, &m.Module, &data); err != nil {
+			return nil, false, err
+		}
+		if err := json.Unmarshal([]byte(data), &m.Method); err != nil {
+			return nil, false, fmt.Errorf("method %s.%s: %w", m.ObjectID, m.Module, err)
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if len(out) > q.Limit {
+		return out[:q.Limit], true, nil
+	}
+	return out, false, nil
+}
+
+func (s *sqliteStore) ListTypes(ctx context.Context, configID string) ([]store.TypeCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT type, COUNT(*) FROM objects WHERE config_id = ?1 GROUP BY type ORDER BY type`, configID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []store.TypeCount
+	for rows.Next() {
+		var t store.TypeCount
+		if err := rows.Scan(&t.Type, &t.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqliteStore) Meta(ctx context.Context, configID string) (snapshot.Meta, error) {
+	return readMeta(ctx, s.db, configID)
+}
+
+// querier — общее у *sql.DB и *sql.Tx, чтобы читать одни и те же данные внутри импорта.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func readMeta(ctx context.Context, q querier, configID string) (snapshot.Meta, error) {
+	rows, err := q.QueryContext(ctx, `SELECT key, value FROM meta WHERE config_id = ?1`, configID)
+	if err != nil {
+		return snapshot.Meta{}, err
+	}
+	defer rows.Close()
+	m := snapshot.Meta{}
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return snapshot.Meta{}, err
+		}
+		switch k {
+		case "configName":
+			m.ConfigName = v
+		case "configVersion":
+			m.ConfigVersion = v
+		case "exportedAt":
+			m.ExportedAt = v
+		case "source":
+			m.Source = v
+		case "objectCount":
+			fmt.Sscanf(v, "%d", &m.ObjectCount)
+		case "indexVersion":
+			fmt.Sscanf(v, "%d", &m.IndexVersion)
+		}
+	}
+	return m, rows.Err()
+}
+
+func (s *sqliteStore) ListConfigs(ctx context.Context) ([]store.ConfigInfo, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT config_id FROM meta ORDER BY config_id`)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]store.ConfigInfo, 0, len(ids))
+	for _, id := range ids {
+		m, err := s.Meta(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, store.ConfigInfo{ID: id, Meta: m})
+	}
+	return out, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanObject читает столбцы объекта; extra — приёмники для столбцов, следующих за description.
+func scanObject(row rowScanner, extra ...any) (snapshot.Object, error) {
+	var o snapshot.Object
+	var propsJSON, tabSecJSON, formsJSON, modsJSON, subsJSON, contentJSON, registerJSON string
+	dest := append([]any{&o.ID, &o.Type, &o.Name, &o.Synonym, &propsJSON, &tabSecJSON, &formsJSON, &modsJSON, &subsJSON, &contentJSON, &registerJSON, &o.Description}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return snapshot.Object{}, err
+	}
+	_ = json.Unmarshal([]byte(propsJSON), &o.Props)
+	_ = json.Unmarshal([]byte(tabSecJSON), &o.TabularSections)
+	_ = json.Unmarshal([]byte(formsJSON), &o.Forms)
+	_ = json.Unmarshal([]byte(modsJSON), &o.Modules)
+	_ = json.Unmarshal([]byte(subsJSON), &o.Subsystems)
+	_ = json.Unmarshal([]byte(contentJSON), &o.Content)
+	_ = json.Unmarshal([]byte(registerJSON), &o.Register)
+	return o, nil
+}
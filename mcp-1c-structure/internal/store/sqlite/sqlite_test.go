@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+// newStore создаёт хранилище в новом файле SQLite.
+func newStore(t *testing.T) store.Store {
+	t.Helper()
+	s, err := New("sqlite://"+filepath.Join(t.TempDir(), "structure.db"), "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// importSnapshot импортирует снимок из корня репозитория в новый файл SQLite.
+func importSnapshot(t *testing.T) store.Store {
+	s := newStore(t)
+	src, err := snapshot.OpenDir("../../../snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Import(context.Background(), "default", src, store.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// SQLite отдаёт снимок так же, как хранилище в памяти: те же типы, объекты и связи.
+func TestMatchesMemory(t *testing.T) {
+	ctx := context.Background()
+	s := importSnapshot(t)
+	m, err := memory.New("default", "../../../snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := s.ListTypes(ctx, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := m.ListTypes(ctx, "default"); !reflect.DeepEqual(types, want) {
+		t.Errorf("ListTypes: got %+v, want %+v", types, want)
+	}
+	objects, total, err := m.Search(ctx, "default", "", "", 100, 0)
+	if err != nil || total == 0 {
+		t.Fatalf("memory Search: %d, %v", total, err)
+	}
+	for _, want := range objects {
+		got, ok, err := s.GetObject(ctx, "default", want.ID)
+		if err != nil || !ok {
+			t.Fatalf("GetObject %s: %v, %v", want.ID, ok, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GetObject %s:\n got %+v\nwant %+v", want.ID, got, want)
+		}
+		in, out, err := s.FindReferences(ctx, "default", want.ID, "both", "", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantIn, wantOut, _ := m.FindReferences(ctx, "default", want.ID, "both", "", 100)
+		if !sameRelations(in, wantIn) || !sameRelations(out, wantOut) {
+			t.Errorf("FindReferences %s:\n got %+v / %+v\nwant %+v / %+v", want.ID, in, out, wantIn, wantOut)
+		}
+	}
+	if _, ok, err := s.GetObject(ctx, "default", "cat.НетТакого"); ok || err != nil {
+		t.Errorf("GetObject of a missing object: %v, %v", ok, err)
+	}
+}
+
+// sameRelations сравнивает связи без учёта порядка.
+func sameRelations(a, b []snapshot.Relation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[snapshot.Relation]int{}
+	for _, r := range a {
+		seen[r]++
+	}
+	for _, r := range b {
+		if seen[r]--; seen[r] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, newStore)
+}
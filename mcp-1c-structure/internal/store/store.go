@@ -2,7 +2,13 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/ser/mcp-1c-structure/internal/search"
 	"github.com/ser/mcp-1c-structure/internal/snapshot"
 )
 
@@ -11,12 +17,238 @@ type TypeCount struct {
 	Count int64
 }
 
+// SearchHit — результат полнотекстового поиска: объект, релевантность и поле, в котором нашлось совпадение
+// (search.FieldName, FieldSynonym, …). Оценки сравнимы только внутри одного ответа: у backend разные формулы.
+type SearchHit struct {
+	Object       snapshot.Object
+	Score        float64
+	MatchedField string
+	Variant      search.Variant // нечёткий поиск: вариант запроса, давший совпадение
+}
+
+// PropFilter — условия structure_find_by_prop; пустое поле не ограничивает выборку. Имена и типы сравниваются
+// без учёта регистра целиком: Type "CatalogRef.Контрагенты" не совпадает с составным типом, где он лишь одна из частей.
+type PropFilter struct {
+	Name           string // имя реквизита или колонки
+	Type           string // тип реквизита, например CatalogRef.Контрагенты
+	TabularSection string // имя табличной части: искать только среди её колонок
+	ObjectType     string // тип объекта, например Document
+	Kind           string // вид поля регистра: dimension, resource или attribute
+}
+
+// PropMatch — реквизит (или колонка табличной части), подошедший под PropFilter, и его объект.
+type PropMatch struct {
+	ObjectID       string        `json:"objectId"`
+	ObjectType     string        `json:"objectType"`
+	ObjectName     string        `json:"objectName"`
+	ObjectSynonym  string        `json:"objectSynonym"`
+	TabularSection string        `json:"tabularSection"` // пусто для реквизита самого объекта
+	Prop           snapshot.Prop `json:"prop"`
+}
+
+// DefaultConfigID — идентификатор конфигурации, если он не указан явно.
+const DefaultConfigID = "default"
+
+// ConfigInfo — загруженная конфигурация и метаданные её снимка.
+type ConfigInfo struct {
+	ID   string
+	Meta snapshot.Meta
+}
+
+// Revision — сохранённый импорт снимка конфигурации. Номера идут подряд с 1 в пределах конфигурации.
+type Revision struct {
+	Number     int           `json:"revision"`
+	Meta       snapshot.Meta `json:"meta"`
+	ImportedAt time.Time     `json:"importedAt"`
+}
+
+// RevisionData — содержимое ревизии в том виде, в каком оно было импортировано.
+type RevisionData struct {
+	Revision
+	Objects   []snapshot.Object
+	Relations []snapshot.Relation
+}
+
+// ImportResult — итог импорта снимка.
+type ImportResult struct {
+	Revision      int
+	Meta          snapshot.Meta // meta импортированного снимка (objectCount — фактическое число объектов)
+	ObjectCount   int           // объектов в импортированном снимке; повторный id считается один раз
+	RelationCount int           // связей прочитано из источника, включая отброшенные проверкой целостности
+	Stats         ImportStats
+	Validation    ValidationReport
+}
+
+// ImportStats — длительность этапов импорта.
+type ImportStats struct {
+	Clear     time.Duration // удаление прежнего снимка конфигурации
+	Objects   time.Duration // чтение и запись объектов (в Postgres — COPY в staging-таблицу и перенос в objects)
+	Relations time.Duration // чтение, проверка и запись связей
+	Finalize  time.Duration // meta, ревизия и фиксация транзакции
+	Total     time.Duration
+}
+
+// MarshalJSON отдаёт этапы в миллисекундах: {"clearMs", "objectsMs", "relationsMs", "finalizeMs", "totalMs"}.
+func (s ImportStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]int64{
+		"clearMs":     s.Clear.Milliseconds(),
+		"objectsMs":   s.Objects.Milliseconds(),
+		"relationsMs": s.Relations.Milliseconds(),
+		"finalizeMs":  s.Finalize.Milliseconds(),
+		"totalMs":     s.Total.Milliseconds(),
+	})
+}
+
+// ImportBatchSize — сколько строк или запросов Import копит в памяти перед отправкой в БД одним пакетом (COPY или pgx.Batch).
+const ImportBatchSize = 500
+
+// ErrNoRevisions возвращает ApplyDelta, если у конфигурации ещё нет ни одной ревизии: дельту не к чему применить.
+// Backend оборачивают её с id конфигурации («configuration "erp" has no revisions: …»), проверять — errors.Is.
+var ErrNoRevisions = errors.New("no revisions: run a full import before applying a delta")
+
+// Store хранит снимки нескольких конфигураций; все методы, кроме ListConfigs, работают в пределах configID.
+// Каждый Import сохраняется отдельной ревизией, текущий снимок — последняя из них.
 type Store interface {
-	Search(ctx context.Context, query, typeFilter string, limit, offset int) ([]snapshot.Object, int, error)
-	GetObject(ctx context.Context, id string) (snapshot.Object, bool, error)
-	FindReferences(ctx context.Context, id, direction, kind string, limit int) (incoming, outgoing []snapshot.Relation, err error)
-	ListTypes(ctx context.Context) ([]TypeCount, error)
-	Meta(ctx context.Context) (snapshot.Meta, error)
-	Import(ctx context.Context, meta snapshot.Meta, objects []snapshot.Object, relations []snapshot.Relation) error
+	Search(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]snapshot.Object, int, error)
+	// SearchFullText ищет по основам слов (русская морфология) в имени, синониме, описании, реквизитах и колонках
+	// табличных частей; результаты упорядочены по убыванию релевантности. Все слова запроса должны найтись.
+	SearchFullText(ctx context.Context, configID, query, typeFilter string, limit, offset int) ([]SearchHit, int, error)
+	// SearchFuzzy ищет объекты, имя или синоним которых похожи по триграммам (не ниже search.FuzzyThreshold)
+	// хотя бы на один вариант запроса (search.Variants); результаты упорядочены по убыванию сходства.
+	SearchFuzzy(ctx context.Context, configID string, variants []search.Variant, typeFilter string, limit, offset int) ([]SearchHit, int, error)
+	GetObject(ctx context.Context, configID, id string) (snapshot.Object, bool, error)
+	// FindByProp возвращает реквизиты объектов и колонки табличных частей, подходящие под filter, упорядоченные
+	// по имени объекта, затем реквизиты объекта перед колонками табличных частей, в порядке описания.
+	FindByProp(ctx context.Context, configID string, filter PropFilter, limit, offset int) ([]PropMatch, int, error)
+	FindReferences(ctx context.Context, configID, id, direction, kind string, limit int) (incoming, outgoing []snapshot.Relation, err error)
+	// ImpactAnalysis обходит связи от объекта в ширину (WalkImpact) и возвращает достигнутые объекты по возрастанию
+	// глубины, затем id; truncated — объектов больше q.MaxNodes и список обрезан.
+	ImpactAnalysis(ctx context.Context, configID string, q ImpactQuery) (nodes []ImpactNode, truncated bool, err error)
+	// RelationsAmong возвращает связи, оба конца которых среди ids (подграф, порождённый объектами), с фильтром видов kinds.
+	RelationsAmong(ctx context.Context, configID string, ids, kinds []string) ([]snapshot.Relation, error)
+	// FindPaths возвращает кратчайшие пути по связям от q.From к q.To (ShortestPaths); пустой результат — пути нет.
+	FindPaths(ctx context.Context, configID string, q PathQuery) (paths [][]snapshot.Relation, truncated bool, err error)
+	// Subsystems возвращает все подсистемы конфигурации (объекты типа TypeSubsystem) по возрастанию id;
+	// дерево и состав строит NewSubsystemIndex.
+	Subsystems(ctx context.Context, configID string) ([]snapshot.Object, error)
+	// ObjectModules возвращает модули объекта, для которых в снимке есть текст, в порядке описания.
+	ObjectModules(ctx context.Context, configID, objectID string) ([]ModuleSource, error)
+	// SearchCode ищет строки в текстах модулей текущего снимка (CodeSearch): совпадения по возрастанию id объекта,
+	// имени модуля и номера строки; truncated — совпадений больше q.Limit.
+	SearchCode(ctx context.Context, configID string, q CodeQuery) (matches []CodeMatch, truncated bool, err error)
+	// FindMethods ищет процедуры и функции модулей текущего снимка по имени (MethodQuery): по возрастанию id объекта,
+	// имени модуля и строки объявления; truncated — методов больше q.Limit.
+	FindMethods(ctx context.Context, configID string, q MethodQuery) (methods []ModuleMethod, truncated bool, err error)
+	ListTypes(ctx context.Context, configID string) ([]TypeCount, error)
+	Meta(ctx context.Context, configID string) (snapshot.Meta, error)
+	ListConfigs(ctx context.Context) ([]ConfigInfo, error)
+	ListRevisions(ctx context.Context, configID string) ([]Revision, error)
+	LoadRevision(ctx context.Context, configID string, number int) (RevisionData, bool, error)
+	// Import читает снимок из src потоком и заменяет им снимок конфигурации одной транзакцией.
+	// Снимок проверяется по ходу чтения (Validator), отчёт возвращается в ImportResult.Validation;
+	// при opts.Strict и ошибках в отчёте импорт откатывается с *ValidationError.
+	Import(ctx context.Context, configID string, src snapshot.Source, opts ImportOptions) (ImportResult, error)
+	// ApplyDelta применяет изменения к текущему снимку конфигурации одной транзакцией и сохраняет результат новой ревизией.
+	// Нужна хотя бы одна полная ревизия, к которой применяется дельта. Дельта проверяется вместе с текущим снимком
+	// (Validator.Delta), отчёт возвращается в ImportResult.Validation; при opts.Strict и ошибках — *ValidationError.
+	ApplyDelta(ctx context.Context, configID string, delta snapshot.Delta, opts ImportOptions) (ImportResult, error)
 	Close() error
 }
+
+// NormalizeID приводит идентификатор объекта к короткому виду: "Document.X" -> "doc.X", "CommonModule.X" -> "commonmodule.X".
+// Объект хранится под id из снимка; нормализованный вид — одно из написаний, по которым его можно найти (IDVariants).
+func NormalizeID(id string) string {
+	id = strings.TrimSpace(id)
+	if idx := strings.Index(id, "."); idx > 0 {
+		prefix := strings.ToLower(id[:idx])
+		name := id[idx+1:]
+		short := map[string]string{
+			"document": "doc", "catalog": "cat", "commonmodule": "commonmodule",
+			"report": "report", "dataprocessor": "dataprocessor",
+		}
+		if s, ok := short[prefix]; ok {
+			return s + "." + name
+		}
+		return prefix + "." + name
+	}
+	return id
+}
+
+// metadataTypes — полное имя типа метаданных по префиксу нормализованного id (обратное к NormalizeID).
+var metadataTypes = func() map[string]string {
+	m := map[string]string{"doc": "Document", "cat": "Catalog"}
+	for _, t := range []string{
+		TypeSubsystem, "CommonModule", "Constant", "Catalog", "Document", "DocumentJournal", "Enum", "Report", "DataProcessor",
+		"ChartOfCharacteristicTypes", "ChartOfAccounts", "ChartOfCalculationTypes", "InformationRegister", "AccumulationRegister",
+		"AccountingRegister", "CalculationRegister", "BusinessProcess", "Task", "ExchangePlan", "CommonForm", "Role",
+	} {
+		m[strings.ToLower(t)] = t
+	}
+	return m
+}()
+
+// FullID возвращает id с полным именем типа метаданных: "doc.X" -> "Document.X"; id неизвестного типа — нормализованным.
+func FullID(id string) string {
+	id = NormalizeID(id)
+	if prefix, name, ok := strings.Cut(id, "."); ok {
+		if t, ok := metadataTypes[prefix]; ok {
+			return t + "." + name
+		}
+	}
+	return id
+}
+
+// IDVariants возвращает написания идентификатора, под которыми объект может лежать в хранилище, в порядке предпочтения:
+// как передан, нормализованный (doc.X) и с полным типом (Document.X). По ним объект ищут все backend (ResolveID).
+func IDVariants(id string) []string {
+	out := []string{id}
+	for _, v := range []string{NormalizeID(id), FullID(id)} {
+		if !containsString(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ResolveID возвращает id, под которым объект хранится: первый из IDVariants(id), для которого exists — true.
+// Единое правило поиска объекта по id для всех backend; связи хранятся с концами, приведёнными этим правилом.
+func ResolveID(id string, exists func(id string) bool) (string, bool) {
+	for _, v := range IDVariants(id) {
+		if exists(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// ObjectJSON — JSON-колонки объекта в БД и объект целиком для revision_objects.
+type ObjectJSON struct {
+	Props           string
+	TabularSections string
+	Forms           string
+	Modules         string
+	Subsystems      string
+	Content         string
+	Register        string
+	Object          string
+}
+
+// ObjectColumns сериализует объект для записи в БД; ошибка сериализации возвращается, а не теряется.
+// Тексты модулей в колонки не попадают: их хранит отдельная таблица (SourcesOf).
+func ObjectColumns(o *snapshot.Object) (ObjectJSON, error) {
+	stripped := WithoutSources(*o)
+	o = &stripped
+	var out ObjectJSON
+	for _, f := range []struct {
+		dst *string
+		v   any
+	}{{&out.Props, o.Props}, {&out.TabularSections, o.TabularSections}, {&out.Forms, o.Forms}, {&out.Modules, o.Modules},
+		{&out.Subsystems, o.Subsystems}, {&out.Content, o.Content}, {&out.Register, o.Register}, {&out.Object, o}} {
+		data, err := json.Marshal(f.v)
+		if err != nil {
+			return ObjectJSON{}, fmt.Errorf("marshal object %s: %w", o.ID, err)
+		}
+		*f.dst = string(data)
+	}
+	return out, nil
+}
@@ -0,0 +1,74 @@
+// Package storetest — проверки, общие для всех backend хранилища. Тест backend вызывает Run с конструктором
+// пустого хранилища, и каждый backend проверяется одной таблицей случаев.
+package storetest
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// ConfigID — конфигурация, в которую Run импортирует снимок примера.
+const ConfigID = "storetest"
+
+// Open создаёт пустое хранилище; закрыть его — забота Open (t.Cleanup).
+type Open func(t *testing.T) store.Store
+
+// cases — проверки Run; каждая получает хранилище со снимком примера в ConfigID.
+var cases = []struct {
+	name string
+	run  func(t *testing.T, s store.Store)
+}{
+	{"FindPathsResolvesEnds", findPathsResolvesEnds},
+}
+
+// Run прогоняет общие проверки: для каждой — новое хранилище из open со снимком примера.
+func Run(t *testing.T, open Open) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := open(t)
+			importSample(t, s)
+			c.run(t, s)
+		})
+	}
+}
+
+// importSample импортирует снимок примера из каталога snapshot в корне репозитория в конфигурацию ConfigID.
+func importSample(t *testing.T, s store.Store) {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	src, err := snapshot.OpenDir(filepath.Join(filepath.Dir(file), "..", "..", "..", "snapshot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Import(context.Background(), ConfigID, src, store.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Концы пути пишутся в любом виде: связи хранятся с id, под которыми объекты записаны в objects.json.
+func findPathsResolvesEnds(t *testing.T, s store.Store) {
+	tests := []struct{ from, to string }{
+		{"doc.РеализацияТоваров", "CommonModule.ОбщийМодульКлиент"},
+		{"Document.РеализацияТоваров", "commonmodule.ОбщийМодульКлиент"},
+	}
+	for _, tt := range tests {
+		paths, _, err := s.FindPaths(context.Background(), ConfigID, store.PathQuery{From: tt.from, To: tt.to})
+		if err != nil {
+			t.Fatalf("%s -> %s: %v", tt.from, tt.to, err)
+		}
+		if len(paths) == 0 || len(paths[0]) != 1 {
+			t.Fatalf("%s -> %s: want a path of length 1, got %v", tt.from, tt.to, paths)
+		}
+		if r := paths[0][0]; r.From != "doc.РеализацияТоваров" || r.To != "CommonModule.ОбщийМодульКлиент" || r.Kind != "call" {
+			t.Errorf("%s -> %s: unexpected step %+v", tt.from, tt.to, r)
+		}
+	}
+	if paths, _, err := s.FindPaths(context.Background(), ConfigID, store.PathQuery{From: "doc.НетТакого", To: "cat.Контрагенты"}); err != nil || paths != nil {
+		t.Errorf("unknown end: want no paths, got %v, %v", paths, err)
+	}
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// TypeSubsystem — тип метаданных подсистемы. Вложенность и состав подсистемы описываются полями
+// Object.Subsystems и Object.Content; у прочих объектов они пустые.
+const TypeSubsystem = "Subsystem"
+
+// IsSubsystem сообщает, что объект — подсистема (тип сравнивается без учёта регистра).
+func IsSubsystem(o *snapshot.Object) bool {
+	return strings.EqualFold(o.Type, TypeSubsystem)
+}
+
+// SubsystemNode — подсистема в дереве подсистем.
+type SubsystemNode struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Synonym     string          `json:"synonym"`
+	ObjectCount int             `json:"objectCount"` // объектов непосредственно в подсистеме, без вложенных
+	Children    []SubsystemNode `json:"children"`
+}
+
+// SubsystemMember — объект в составе подсистемы; Subsystem — подсистема, в которую он входит непосредственно
+// (при рекурсивном обходе — вложенная).
+type SubsystemMember struct {
+	ObjectID  string `json:"objectId"`
+	Subsystem string `json:"subsystem"`
+}
+
+// SubsystemRef — подсистема, в состав которой входит объект, и путь к ней: id подсистем от корня до неё включительно.
+type SubsystemRef struct {
+	ID   string   `json:"id"`
+	Path []string `json:"path"`
+}
+
+// SubsystemIndex — подсистемы конфигурации по id, под которыми они хранятся: вложенность в обе стороны и состав.
+// id в запросах к индексу пишутся в любом виде (ResolveID). Ссылки на отсутствующие подсистемы в дереве
+// пропускаются, циклы вложенности обрываются.
+type SubsystemIndex struct {
+	byID     map[string]*snapshot.Object
+	ids      []string            // по возрастанию
+	children map[string][]string // вложенные подсистемы в порядке описания
+	parents  map[string][]string // по возрастанию id
+	content  map[string][]string // id объектов, как записаны в составе, в порядке описания, без повторов (NormalizeID)
+	resolve  func(id string) (string, bool)
+	stored   map[string]string // id из состава -> id, под которым объект хранится (пусто — объекта нет)
+}
+
+// NewSubsystemIndex строит индекс по подсистемам (Store.Subsystems); объекты других типов пропускаются.
+// resolve возвращает id, под которым объект из состава хранится (например, через Store.GetObject); nil — id
+// состава отдаются как записаны.
+func NewSubsystemIndex(subs []snapshot.Object, resolve func(id string) (string, bool)) *SubsystemIndex {
+	x := &SubsystemIndex{
+		byID:     make(map[string]*snapshot.Object),
+		children: make(map[string][]string),
+		parents:  make(map[string][]string),
+		content:  make(map[string][]string),
+		resolve:  resolve,
+		stored:   make(map[string]string),
+	}
+	for i := range subs {
+		if !IsSubsystem(&subs[i]) {
+			continue
+		}
+		id := subs[i].ID
+		if _, ok := x.byID[id]; !ok {
+			x.ids = append(x.ids, id)
+		}
+		x.byID[id] = &subs[i]
+	}
+	sort.Strings(x.ids)
+	for _, id := range x.ids {
+		o := x.byID[id]
+		for _, c := range o.Subsystems {
+			c, ok := x.find(c)
+			if !ok || c == id || containsString(x.children[id], c) {
+				continue
+			}
+			x.children[id] = append(x.children[id], c)
+			x.parents[c] = append(x.parents[c], id)
+		}
+		seen := make(map[string]bool)
+		for _, obj := range o.Content {
+			if norm := NormalizeID(obj); norm != "" && !seen[norm] {
+				seen[norm] = true
+				x.content[id] = append(x.content[id], obj)
+			}
+		}
+	}
+	return x
+}
+
+// find возвращает id, под которым подсистема хранится.
+func (x *SubsystemIndex) find(id string) (string, bool) {
+	return ResolveID(id, func(id string) bool {
+		_, ok := x.byID[id]
+		return ok
+	})
+}
+
+// member возвращает id объекта из состава, под которым он хранится, или id как записан, если объекта нет.
+func (x *SubsystemIndex) member(id string) string {
+	if x.resolve == nil {
+		return id
+	}
+	stored, ok := x.stored[id]
+	if !ok {
+		stored, _ = x.resolve(id)
+		x.stored[id] = stored
+	}
+	if stored == "" {
+		return id
+	}
+	return stored
+}
+
+// Has сообщает, есть ли подсистема с таким id.
+func (x *SubsystemIndex) Has(id string) bool {
+	_, ok := x.find(id)
+	return ok
+}
+
+// Tree возвращает дерево подсистем: при пустом id — корневые подсистемы (без родителя) со всеми вложенными,
+// иначе — одну подсистему с вложенными. Подсистемы, недостижимые от корней из-за цикла, становятся корнями.
+func (x *SubsystemIndex) Tree(id string) []SubsystemNode {
+	if id != "" {
+		id, ok := x.find(id)
+		if !ok {
+			return nil
+		}
+		return []SubsystemNode{x.node(id, map[string]bool{}, nil)}
+	}
+	visited := make(map[string]bool)
+	var roots []SubsystemNode
+	for _, root := range x.ids {
+		if len(x.parents[root]) == 0 {
+			roots = append(roots, x.node(root, map[string]bool{}, visited))
+		}
+	}
+	for _, root := range x.ids {
+		if !visited[root] {
+			roots = append(roots, x.node(root, map[string]bool{}, visited))
+		}
+	}
+	return roots
+}
+
+// node строит поддерево; onPath обрывает циклы, visited (если не nil) собирает все вошедшие подсистемы.
+func (x *SubsystemIndex) node(id string, onPath, visited map[string]bool) SubsystemNode {
+	o := x.byID[id]
+	n := SubsystemNode{ID: id, Name: o.Name, Synonym: o.Synonym, ObjectCount: len(x.content[id]), Children: []SubsystemNode{}}
+	if visited != nil {
+		visited[id] = true
+	}
+	onPath[id] = true
+	for _, c := range x.children[id] {
+		if !onPath[c] {
+			n.Children = append(n.Children, x.node(c, onPath, visited))
+		}
+	}
+	delete(onPath, id)
+	return n
+}
+
+// Objects возвращает состав подсистемы; recursive — вместе с составом вложенных подсистем (обход в глубину
+// в порядке описания, объект, входящий в несколько подсистем, — один раз, с первой из них).
+func (x *SubsystemIndex) Objects(id string, recursive bool) []SubsystemMember {
+	var out []SubsystemMember
+	seenObjects, seenSubs := make(map[string]bool), make(map[string]bool)
+	var walk func(sub string)
+	walk = func(sub string) {
+		seenSubs[sub] = true
+		for _, obj := range x.content[sub] {
+			obj = x.member(obj)
+			if !seenObjects[obj] {
+				seenObjects[obj] = true
+				out = append(out, SubsystemMember{ObjectID: obj, Subsystem: sub})
+			}
+		}
+		if !recursive {
+			return
+		}
+		for _, c := range x.children[sub] {
+			if !seenSubs[c] {
+				walk(c)
+			}
+		}
+	}
+	if id, ok := x.find(id); ok {
+		walk(id)
+	}
+	return out
+}
+
+// Containing возвращает подсистемы, в состав которых объект входит непосредственно, по возрастанию id,
+// с путём от корня (через первого по id родителя). Для подсистемы — подсистемы, в которые она вложена.
+// objectID — id, под которым объект хранится.
+func (x *SubsystemIndex) Containing(objectID string) []SubsystemRef {
+	norm := NormalizeID(objectID)
+	var out []SubsystemRef
+	for _, id := range x.ids {
+		found := containsString(x.children[id], objectID)
+		for _, obj := range x.content[id] {
+			if !found && NormalizeID(obj) == norm && x.member(obj) == objectID {
+				found = true
+			}
+		}
+		if found {
+			out = append(out, SubsystemRef{ID: id, Path: x.Path(id)})
+		}
+	}
+	return out
+}
+
+// Path возвращает id подсистем от корня до id включительно.
+func (x *SubsystemIndex) Path(id string) []string {
+	if stored, ok := x.find(id); ok {
+		id = stored
+	}
+	path := []string{id}
+	seen := map[string]bool{id: true}
+	for {
+		parents := x.parents[path[0]]
+		if len(parents) == 0 || seen[parents[0]] {
+			return path
+		}
+		seen[parents[0]] = true
+		path = append([]string{parents[0]}, path...)
+	}
+}
+
+// Get возвращает подсистему по id.
+func (x *SubsystemIndex) Get(id string) (snapshot.Object, bool) {
+	id, ok := x.find(id)
+	if !ok {
+		return snapshot.Object{}, false
+	}
+	return *x.byID[id], true
+}
@@ -0,0 +1,300 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+// Коды проблем в ValidationReport.
+const (
+	IssueEmptyObjectID       = "empty_object_id"
+	IssueDuplicateObjectID   = "duplicate_object_id"
+	IssueAmbiguousObjectID   = "ambiguous_object_id"
+	IssueEmptyObjectType     = "empty_object_type"
+	IssueDanglingRelation    = "dangling_relation"
+	IssueEmptyObjectName     = "empty_object_name"
+	IssueEmptyRelationKind   = "empty_relation_kind"
+	IssueObjectCountMismatch = "object_count_mismatch"
+	IssueUnsupportedIndex    = "unsupported_index_version"
+	IssueUnresolvedPropRef   = "unresolved_prop_reference"
+	IssueUnresolvedSubsystem = "unresolved_subsystem_item"
+	IssueUnknownPropKind     = "unknown_prop_kind"
+	IssueInvalidRecord       = "invalid_register_record"
+	IssueNoRecorder          = "register_without_recorder"
+	IssueModuleNotLoaded     = "module_source_not_loaded"
+)
+
+// maxIssueExamples — сколько примеров хранится на один код проблемы.
+const maxIssueExamples = 5
+
+// ValidationIssue — проблема одного вида: сколько раз встретилась и первые примеры.
+type ValidationIssue struct {
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+}
+
+// ValidationReport — итог проверки снимка при импорте. Errors — нарушения формата (связи с отсутствующим концом,
+// повторные id, объекты без id или типа), из-за них в строгом режиме импорт отклоняется; Warnings — подозрительные данные.
+type ValidationReport struct {
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []ValidationIssue `json:"warnings"`
+}
+
+func (r ValidationReport) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// Counts возвращает число найденных ошибок и предупреждений (с повторами, а не видов проблем).
+func (r ValidationReport) Counts() (errors, warnings int) {
+	for _, issue := range r.Errors {
+		errors += issue.Count
+	}
+	for _, issue := range r.Warnings {
+		warnings += issue.Count
+	}
+	return errors, warnings
+}
+
+// ValidationError возвращается Import в строгом режиме, если в снимке есть ошибки; импорт при этом откатывается.
+type ValidationError struct {
+	Report ValidationReport
+}
+
+func (e *ValidationError) Error() string {
+	n, _ := e.Report.Counts()
+	return fmt.Sprintf("snapshot validation failed: %d errors, first: %s", n, e.Report.Errors[0].Message)
+}
+
+// ImportOptions — параметры Import.
+type ImportOptions struct {
+	Strict bool // отклонить импорт, если проверка нашла ошибки
+}
+
+// Validator проверяет снимок по мере чтения: Import передаёт ему каждый объект, каждую связь и meta.
+// Хранит только id и типы объектов и агрегаты проблем, поэтому память не зависит от размера объектов.
+type Validator struct {
+	ids      map[string]bool   // id объектов, как они хранятся
+	seen     map[string]string // нормализованный id -> первый объект с ним, для поиска id, различающихся написанием
+	objects  int
+	subRefs  []snapshot.Relation // вложенные подсистемы и состав подсистем: проверяются в Meta, когда прочитаны все объекты
+	types    map[string]string   // id -> тип объекта, для проверки связей register_record
+	recorder map[string]string   // регистры с известным подчинением регистратору (RecorderMode)
+	required []string            // регистры, которым нужен регистратор, в порядке снимка
+	recorded map[string]bool     // регистры, в которые пишет хотя бы один документ
+	records  int                 // связей register_record
+	errors   map[string]*ValidationIssue
+	warnings map[string]*ValidationIssue
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		ids:      make(map[string]bool),
+		seen:     make(map[string]string),
+		types:    make(map[string]string),
+		recorder: make(map[string]string),
+		recorded: make(map[string]bool),
+		errors:   make(map[string]*ValidationIssue),
+		warnings: make(map[string]*ValidationIssue),
+	}
+}
+
+// Object проверяет объект и запоминает его id для проверки связей.
+func (v *Validator) Object(o *snapshot.Object) {
+	v.objects++
+	if o.ID == "" {
+		add(v.errors, IssueEmptyObjectID, "object without id", fmt.Sprintf("#%d name=%q", v.objects, o.Name))
+		return
+	}
+	norm := NormalizeID(o.ID)
+	if v.ids[o.ID] {
+		add(v.errors, IssueDuplicateObjectID, "duplicate object id, the last one wins", o.ID)
+	} else if first, ok := v.seen[norm]; ok {
+		add(v.warnings, IssueAmbiguousObjectID, "object ids differ only in spelling, a lookup finds the exact spelling first", fmt.Sprintf("%s / %s", first, o.ID))
+	} else {
+		v.seen[norm] = o.ID
+	}
+	v.ids[o.ID] = true
+	v.types[o.ID] = o.Type
+	if mode := RecorderMode(o.Type, o.Register); mode != "" {
+		if mode == RecorderRequired && v.recorder[o.ID] == "" {
+			v.required = append(v.required, o.ID)
+		}
+		v.recorder[o.ID] = mode
+	}
+	if o.Type == "" {
+		add(v.errors, IssueEmptyObjectType, "object without type", o.ID)
+	}
+	if o.Name == "" {
+		add(v.warnings, IssueEmptyObjectName, "object without name", o.ID)
+	}
+	for _, p := range o.Props {
+		switch p.Kind {
+		case "", snapshot.PropDimension, snapshot.PropResource, snapshot.PropAttribute:
+		default:
+			add(v.warnings, IssueUnknownPropKind, "prop kind is not dimension, resource or attribute", fmt.Sprintf("%s.%s: %s", o.ID, p.Name, p.Kind))
+		}
+	}
+	for _, m := range o.Modules {
+		if m.Path != "" && m.Source == "" {
+			add(v.warnings, IssueModuleNotLoaded, "module path given but the source was not read (file missing, outside the snapshot directory or import not from a directory)", fmt.Sprintf("%s.%s: %s", o.ID, m.Name, m.Path))
+		}
+	}
+	if IsSubsystem(o) {
+		for _, c := range o.Subsystems {
+			v.subRefs = append(v.subRefs, snapshot.Relation{From: o.ID, To: c, Kind: "subsystem"})
+		}
+		for _, c := range o.Content {
+			v.subRefs = append(v.subRefs, snapshot.Relation{From: o.ID, To: c, Kind: "content"})
+		}
+	}
+}
+
+// Known запоминает объект текущего снимка, к которому применяется дельта: его id и тип нужны для проверки
+// связей дельты, а сам объект уже проверен при импорте и в число прочитанных не входит.
+func (v *Validator) Known(id, objectType string, register *snapshot.Register) {
+	if _, ok := v.seen[NormalizeID(id)]; !ok {
+		v.seen[NormalizeID(id)] = id
+	}
+	v.ids[id] = true
+	v.types[id] = objectType
+	if mode := RecorderMode(objectType, register); mode != "" {
+		v.recorder[id] = mode
+	}
+}
+
+// Delta проверяет дельту, когда Known передан каждый объект текущего снимка, который дельта не заменяет
+// и не удаляет: объекты из upserted, связи из addRelations и meta. Возвращает связи, которые стоит добавить,
+// с концами, приведёнными к хранимым id. Число объектов и регистры без движений проверяет только полный импорт:
+// для них нужен весь снимок.
+func (v *Validator) Delta(delta *snapshot.Delta) []snapshot.Relation {
+	for i := range delta.Upserted {
+		v.Object(&delta.Upserted[i])
+	}
+	added := make([]snapshot.Relation, 0, len(delta.AddRelations))
+	for _, r := range delta.AddRelations {
+		if v.Relation(&r) {
+			added = append(added, r)
+		}
+	}
+	v.subsystemRefs()
+	v.indexVersion(delta.Meta)
+	return added
+}
+
+// Relation проверяет связь и сообщает, сохранять ли её: связь с отсутствующим концом отбрасывается.
+// Концы сохраняемой связи приводятся к id, под которыми объекты хранятся (ResolveID).
+func (v *Validator) Relation(r *snapshot.Relation) bool {
+	from, fromOK := v.Resolve(r.From)
+	to, toOK := v.Resolve(r.To)
+	if !fromOK || !toOK {
+		add(v.errors, IssueDanglingRelation, "relation end not found among objects, relation dropped", fmt.Sprintf("%s -> %s (%s)", r.From, r.To, r.Kind))
+		return false
+	}
+	r.From, r.To = from, to
+	if r.Kind == "" {
+		add(v.warnings, IssueEmptyRelationKind, "relation without kind", fmt.Sprintf("%s -> %s", r.From, r.To))
+	}
+	if r.Kind == KindRegisterRecord {
+		v.registerRecord(r)
+	}
+	return true
+}
+
+// registerRecord проверяет связь register_record: регистратор — документ, цель — регистр, подчинённый регистратору.
+// Неверная связь сохраняется, но попадает в предупреждения.
+func (v *Validator) registerRecord(r *snapshot.Relation) {
+	v.records++
+	from, to := r.From, r.To
+	const message = "register record must go from a document to a register subordinate to a recorder, relation kept"
+	switch {
+	case !IsRecorder(v.types[from]):
+		add(v.warnings, IssueInvalidRecord, message, fmt.Sprintf("%s -> %s: from %s", r.From, r.To, v.types[from]))
+	case !IsRegister(v.types[to]):
+		add(v.warnings, IssueInvalidRecord, message, fmt.Sprintf("%s -> %s: to %s", r.From, r.To, v.types[to]))
+	case v.recorder[to] == RecorderForbidden:
+		add(v.warnings, IssueInvalidRecord, message, fmt.Sprintf("%s -> %s: writeMode Independent", r.From, r.To))
+	default:
+		v.recorded[to] = true
+	}
+}
+
+// Resolve возвращает id, под которым хранится прочитанный объект с таким id в любом написании (ResolveID).
+func (v *Validator) Resolve(id string) (string, bool) {
+	return ResolveID(id, func(id string) bool { return v.ids[id] })
+}
+
+// HasObject сообщает, встречался ли объект с таким id в любом написании.
+func (v *Validator) HasObject(id string) bool {
+	_, ok := v.Resolve(id)
+	return ok
+}
+
+// UnresolvedPropRef отмечает ссылку реквизита на объект, которого нет в снимке: связь из неё не выводится.
+func (v *Validator) UnresolvedPropRef(r *snapshot.Relation) {
+	add(v.warnings, IssueUnresolvedPropRef, "prop type refers to an object missing from the snapshot, relation not derived", fmt.Sprintf("%s.%s -> %s", r.From, r.Prop, r.To))
+}
+
+// Meta сверяет objectCount из meta с числом прочитанных объектов (0 в meta означает «не указано»)
+// и indexVersion с версией формата, которую понимает бинарник, проверяет ссылки подсистем на объекты
+// и регистры, подчинённые регистратору, без движений.
+func (v *Validator) Meta(m snapshot.Meta) {
+	v.subsystemRefs()
+	// Без единой связи register_record снимок, скорее всего, движений не выгружает: предупреждать о каждом регистре незачем.
+	if v.records > 0 {
+		for _, id := range v.required {
+			if v.recorder[id] == RecorderRequired && !v.recorded[id] {
+				add(v.warnings, IssueNoRecorder, "register subordinate to a recorder has no document writing to it", id)
+			}
+		}
+	}
+	v.indexVersion(m)
+	if m.ObjectCount != 0 && m.ObjectCount != v.objects {
+		add(v.warnings, IssueObjectCountMismatch, "meta.objectCount differs from the number of objects", fmt.Sprintf("meta %d, objects %d", m.ObjectCount, v.objects))
+	}
+}
+
+// subsystemRefs проверяет ссылки подсистем, накопленные Object, когда прочитаны все объекты.
+func (v *Validator) subsystemRefs() {
+	for _, r := range v.subRefs {
+		if !v.HasObject(r.To) {
+			add(v.warnings, IssueUnresolvedSubsystem, "subsystem content or child subsystem refers to an object missing from the snapshot", fmt.Sprintf("%s -> %s (%s)", r.From, r.To, r.Kind))
+		}
+	}
+	v.subRefs = nil
+}
+
+func (v *Validator) indexVersion(m snapshot.Meta) {
+	if m.IndexVersion > snapshot.IndexVersion {
+		add(v.errors, IssueUnsupportedIndex, "snapshot indexVersion is newer than this binary supports, unknown data may be lost", fmt.Sprintf("indexVersion %d, supported %d", m.IndexVersion, snapshot.IndexVersion))
+	}
+}
+
+// Report возвращает отчёт; проблемы упорядочены по коду, списки не nil.
+func (v *Validator) Report() ValidationReport {
+	return ValidationReport{Errors: issues(v.errors), Warnings: issues(v.warnings)}
+}
+
+func add(m map[string]*ValidationIssue, code, message, example string) {
+	issue, ok := m[code]
+	if !ok {
+		issue = &ValidationIssue{Code: code, Message: message, Examples: []string{}}
+		m[code] = issue
+	}
+	issue.Count++
+	if len(issue.Examples) < maxIssueExamples {
+		issue.Examples = append(issue.Examples, example)
+	}
+}
+
+func issues(m map[string]*ValidationIssue) []ValidationIssue {
+	out := make([]ValidationIssue, 0, len(m))
+	for _, issue := range m {
+		out = append(out, *issue)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
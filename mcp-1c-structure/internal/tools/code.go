@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/bsl"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// MaxModuleLines — сколько строк модуля structure_get_module отдаёт за один вызов.
+const MaxModuleLines = 2000
+
+type GetModuleParams struct {
+	ConfigID string `json:"configId,omitempty"`
+	ObjectID string `json:"objectId"`
+	Module   string `json:"module,omitempty"`
+	FromLine int    `json:"fromLine,omitempty"`
+	ToLine   int    `json:"toLine,omitempty"`
+}
+
+// moduleSummary — модуль в списке модулей объекта. Lines — 0, если текста в снимке нет.
+type moduleSummary struct {
+	Name      string `json:"name"`
+	Path      string `json:"path,omitempty"`
+	HasSource bool   `json:"hasSource"`
+	Lines     int    `json:"lines"`
+}
+
+// GetModule отдаёт текст модуля объекта: без module — список модулей, с module — строки fromLine..toLine
+// (по умолчанию весь модуль, не больше MaxModuleLines строк).
+func GetModule(ctx context.Context, req *mcp.CallToolRequest, args GetModuleParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.ObjectID == "" {
+		return errResult("objectId обязателен"), nil, nil
+	}
+	if args.FromLine < 0 || args.ToLine < 0 || (args.ToLine > 0 && args.ToLine < args.FromLine) {
+		return errResult("неверный диапазон строк: fromLine и toLine считаются с 1, toLine не меньше fromLine"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if !ok {
+		return errResult("объект не найден: " + args.ObjectID), nil, nil
+	}
+	sources, err := currentStore.ObjectModules(ctx, configID, obj.ID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+
+	if args.Module == "" {
+		list := make([]moduleSummary, 0, len(obj.Modules))
+		loaded := 0
+		for _, m := range obj.Modules {
+			s := moduleSummary{Name: m.Name, Path: m.Path}
+			for _, src := range sources {
+				if src.Module == m.Name {
+					s.HasSource, s.Lines = true, len(bsl.Lines(src.Source))
+					loaded++
+					break
+				}
+			}
+			list = append(list, s)
+		}
+		out := map[string]any{
+			"summary":  fmt.Sprintf("Модулей у объекта: %d, с текстом в снимке: %d.", len(list), loaded),
+			"configId": configID,
+			"objectId": obj.ID,
+			"modules":  list,
+		}
+		return jsonResult(out), nil, nil
+	}
+
+	var src *store.ModuleSource
+	for i := range sources {
+		if strings.EqualFold(sources[i].Module, args.Module) {
+			src = &sources[i]
+			break
+		}
+	}
+	if src == nil {
+		for _, m := range obj.Modules {
+			if strings.EqualFold(m.Name, args.Module) {
+				return errResult(fmt.Sprintf("текст модуля %s не выгружен в снимок", m.Name)), nil, nil
+			}
+		}
+		return errResult(fmt.Sprintf("модуль не найден: %s", args.Module)), nil, nil
+	}
+	lines := bsl.Lines(src.Source)
+	from, to := max(args.FromLine, 1), len(lines)
+	if args.ToLine > 0 {
+		to = min(args.ToLine, len(lines))
+	}
+	if from > len(lines) {
+		return errResult(fmt.Sprintf("в модуле %d строк, fromLine %d за его концом", len(lines), from)), nil, nil
+	}
+	truncated := to-from+1 > MaxModuleLines
+	if truncated {
+		to = from + MaxModuleLines - 1
+	}
+	summary := fmt.Sprintf("Модуль %s: строки %d–%d из %d.", src.Module, from, to, len(lines))
+	if truncated {
+		summary += fmt.Sprintf(" Ответ ограничен %d строками, продолжение — fromLine %d.", MaxModuleLines, to+1)
+	}
+	out := map[string]any{
+		"summary":   summary,
+		"configId":  configID,
+		"objectId":  obj.ID,
+		"module":    src.Module,
+		"path":      src.Path,
+		"lineCount": len(lines),
+		"fromLine":  from,
+		"toLine":    to,
+		"truncated": truncated,
+		"source":    strings.Join(lines[from-1:to], "\n"),
+	}
+	return jsonResult(out), nil, nil
+}
+
+type SearchCodeParams struct {
+	ConfigID      string `json:"configId,omitempty"`
+	Query         string `json:"query"`
+	Regex         bool   `json:"regex,omitempty"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+	ObjectType    string `json:"objectType,omitempty"`
+	ObjectID      string `json:"objectId,omitempty"`
+	Module        string `json:"module,omitempty"`
+	Context       int    `json:"context,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+// SearchCode ищет строку или регулярное выражение в текстах модулей и отдаёт совпадения с номерами строк
+// и соседними строками.
+func SearchCode(ctx context.Context, req *mcp.CallToolRequest, args SearchCodeParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.Query == "" {
+		return errResult("query обязателен"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if args.ObjectID != "" {
+		obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		if !ok {
+			return errResult("объект не найден: " + args.ObjectID), nil, nil
+		}
+		args.ObjectID = obj.ID
+	}
+	q := store.CodeQuery{
+		Query: args.Query, Regex: args.Regex, CaseSensitive: args.CaseSensitive,
+		ObjectType: args.ObjectType, ObjectID: args.ObjectID, Module: args.Module,
+		Context: args.Context, Limit: args.Limit,
+	}
+	if _, err := store.NewCodeSearch(q); err != nil {
+		return errResult("неверный запрос: " + err.Error()), nil, nil
+	}
+	matches, truncated, err := currentStore.SearchCode(ctx, configID, q)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	summary := fmt.Sprintf("Совпадений: %d.", len(matches))
+	if truncated {
+		summary = fmt.Sprintf("Показаны первые %d совпадений, есть ещё: уточните запрос или фильтры.", len(matches))
+	}
+	out := map[string]any{
+		"summary":   summary,
+		"configId":  configID,
+		"matches":   matches,
+		"truncated": truncated,
+	}
+	return jsonResult(out), nil, nil
+}
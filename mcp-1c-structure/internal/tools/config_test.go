@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+func TestResolveConfigID(t *testing.T) {
+	t.Setenv("MCP_1C_STRUCTURE_CONFIG_ID", "")
+	ctx := context.Background()
+	s, err := memory.New("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s, snapshot.Meta{})
+	t.Cleanup(func() { SetStore(nil, snapshot.Meta{}) })
+	load := func(configID string) {
+		src := snapshot.FromSlices(snapshot.Meta{}, []snapshot.Object{{ID: "cat.Контрагенты", Type: "Catalog", Name: "Контрагенты"}}, nil)
+		if _, err := s.Import(ctx, configID, src, store.ImportOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(arg, want, wantErr string) {
+		t.Helper()
+		got, err := resolveConfigID(ctx, arg)
+		if wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Errorf("resolveConfigID(%q): want error with %q, got %q, %v", arg, wantErr, got, err)
+			}
+			return
+		}
+		if err != nil || got != want {
+			t.Errorf("resolveConfigID(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+
+	check("", store.DefaultConfigID, "")
+	load("erp")
+	check("", "erp", "")
+	load("bsp")
+	check("", "", "bsp, erp")
+	check("erp", "erp", "")
+	t.Setenv("MCP_1C_STRUCTURE_CONFIG_ID", "bsp")
+	check("", "bsp", "")
+}
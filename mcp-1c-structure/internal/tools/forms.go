@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+type GetFormParams struct {
+	ConfigID string `json:"configId,omitempty"`
+	ObjectID string `json:"objectId"`
+	Form     string `json:"form,omitempty"`
+	Element  string `json:"element,omitempty"`
+}
+
+// formSummary — форма в списке форм объекта.
+type formSummary struct {
+	Name       string `json:"name"`
+	Detailed   bool   `json:"detailed"`
+	Attributes int    `json:"attributes"`
+	Elements   int    `json:"elements"` // вместе с вложенными
+	Commands   int    `json:"commands"`
+	Handlers   int    `json:"handlers"` // обработчики формы, элементов и действия команд
+}
+
+// formBinding — обработчик события формы, элемента или команды. Path — имена элементов от верхнего уровня до элемента.
+type formBinding struct {
+	Source    string   `json:"source"` // form, element или command
+	Name      string   `json:"name,omitempty"`
+	Path      []string `json:"path,omitempty"`
+	DataPath  string   `json:"dataPath,omitempty"`
+	Event     string   `json:"event"`
+	Procedure string   `json:"procedure"`
+}
+
+// foundElement — элемент, подошедший под параметр element, и путь к нему.
+type foundElement struct {
+	Path    []string             `json:"path"`
+	Element snapshot.FormElement `json:"element"`
+}
+
+// GetForm отдаёт формы объекта: без form — список форм, с form — форму целиком и плоский список обработчиков,
+// с element — только элементы с таким именем или путём к данным и их обработчики.
+func GetForm(ctx context.Context, req *mcp.CallToolRequest, args GetFormParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.ObjectID == "" {
+		return errResult("objectId обязателен"), nil, nil
+	}
+	if args.Element != "" && args.Form == "" {
+		return errResult("element задаётся вместе с form"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if !ok {
+		return errResult("объект не найден: " + args.ObjectID), nil, nil
+	}
+
+	if args.Form == "" {
+		list := make([]formSummary, len(obj.Forms))
+		for i, f := range obj.Forms {
+			list[i] = formSummary{Name: f.Name, Detailed: f.Detailed(), Attributes: len(f.Attributes),
+				Elements: countElements(f.Elements), Commands: len(f.Commands), Handlers: len(formBindings(f))}
+		}
+		out := map[string]any{
+			"summary":  fmt.Sprintf("Форм у объекта: %d.", len(list)),
+			"configId": configID,
+			"objectId": obj.ID,
+			"forms":    list,
+		}
+		return jsonResult(out), nil, nil
+	}
+
+	var form *snapshot.Form
+	for i := range obj.Forms {
+		if strings.EqualFold(obj.Forms[i].Name, args.Form) {
+			form = &obj.Forms[i]
+			break
+		}
+	}
+	if form == nil {
+		return errResult(fmt.Sprintf("форма не найдена: %s (формы объекта: %s)", args.Form, strings.Join(snapshot.FormNames(obj.Forms), ", "))), nil, nil
+	}
+	out := map[string]any{
+		"configId": configID,
+		"objectId": obj.ID,
+	}
+	if !form.Detailed() {
+		out["summary"] = fmt.Sprintf("Форма %s: в снимке только имя, устройство формы не выгружено.", form.Name)
+		out["form"] = map[string]any{"name": form.Name}
+		return jsonResult(out), nil, nil
+	}
+	if args.Element != "" {
+		found := findElements(form.Elements, args.Element, nil)
+		bindings := []formBinding{}
+		for _, e := range found {
+			bindings = appendElementBindings(bindings, e.Element, e.Path, false)
+		}
+		out["summary"] = fmt.Sprintf("Элементов формы %s по «%s»: %d, обработчиков: %d.", form.Name, args.Element, len(found), len(bindings))
+		out["form"] = form.Name
+		out["elements"] = found
+		out["handlers"] = bindings
+		return jsonResult(out), nil, nil
+	}
+	bindings := formBindings(*form)
+	out["summary"] = fmt.Sprintf("Форма %s: реквизитов %d, элементов %d, команд %d, обработчиков %d.",
+		form.Name, len(form.Attributes), countElements(form.Elements), len(form.Commands), len(bindings))
+	out["form"] = form
+	out["handlers"] = bindings
+	return jsonResult(out), nil, nil
+}
+
+func countElements(elements []snapshot.FormElement) int {
+	n := len(elements)
+	for _, e := range elements {
+		n += countElements(e.Children)
+	}
+	return n
+}
+
+// formBindings — все обработчики формы: события формы, затем элементов (в глубину, в порядке описания), затем команд.
+func formBindings(f snapshot.Form) []formBinding {
+	out := []formBinding{}
+	for _, h := range f.Handlers {
+		out = append(out, formBinding{Source: "form", Event: h.Event, Procedure: h.Procedure})
+	}
+	for _, e := range f.Elements {
+		out = appendElementBindings(out, e, []string{e.Name}, true)
+	}
+	for _, c := range f.Commands {
+		if c.Action != "" {
+			out = append(out, formBinding{Source: "command", Name: c.Name, Event: "Действие", Procedure: c.Action})
+		}
+	}
+	return out
+}
+
+// appendElementBindings добавляет обработчики элемента e с путём path и, если recursive, его вложенных элементов.
+func appendElementBindings(out []formBinding, e snapshot.FormElement, path []string, recursive bool) []formBinding {
+	for _, h := range e.Handlers {
+		out = append(out, formBinding{Source: "element", Name: e.Name, Path: path, DataPath: e.DataPath, Event: h.Event, Procedure: h.Procedure})
+	}
+	if recursive {
+		for _, c := range e.Children {
+			out = appendElementBindings(out, c, append(append([]string{}, path...), c.Name), true)
+		}
+	}
+	return out
+}
+
+// findElements ищет элементы, у которых имя или путь к данным совпадает с query без учёта регистра;
+// путь к данным совпадает и по последним частям: «Контрагент» находит Объект.Контрагент.
+func findElements(elements []snapshot.FormElement, query string, parent []string) []foundElement {
+	out := []foundElement{}
+	q := strings.ToLower(query)
+	for _, e := range elements {
+		path := append(append([]string{}, parent...), e.Name)
+		dp := strings.ToLower(e.DataPath)
+		if strings.ToLower(e.Name) == q || dp == q || strings.HasSuffix(dp, "."+q) {
+			out = append(out, foundElement{Path: path, Element: e})
+		}
+		out = append(out, findElements(e.Children, query, path)...)
+	}
+	return out
+}
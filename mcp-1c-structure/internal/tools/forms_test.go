@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+func TestGetForm(t *testing.T) {
+	t.Setenv("MCP_1C_STRUCTURE_CONFIG_ID", "")
+	s, err := memory.New("default", "../../snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s, snapshot.Meta{})
+	t.Cleanup(func() { SetStore(nil, snapshot.Meta{}) })
+	call := func(args GetFormParams, v any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		res, _, err := GetForm(context.Background(), nil, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := res.Content[0].(*mcp.TextContent).Text
+		if v != nil && !res.IsError {
+			if err := json.Unmarshal([]byte(text), v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return res, text
+	}
+
+	var list struct {
+		ObjectID string        `json:"objectId"`
+		Forms    []formSummary `json:"forms"`
+	}
+	if res, text := call(GetFormParams{ObjectID: "Document.РеализацияТоваров"}, &list); res.IsError {
+		t.Fatal(text)
+	}
+	want := formSummary{Name: "ФормаДокумента", Detailed: true, Attributes: 1, Elements: 4, Commands: 1, Handlers: 3}
+	if list.ObjectID != "doc.РеализацияТоваров" || len(list.Forms) != 1 || list.Forms[0] != want {
+		t.Errorf("list: %+v", list)
+	}
+
+	var full struct {
+		Form     snapshot.Form `json:"form"`
+		Handlers []formBinding `json:"handlers"`
+	}
+	if res, text := call(GetFormParams{ObjectID: "doc.РеализацияТоваров", Form: "формадокумента"}, &full); res.IsError {
+		t.Fatal(text)
+	}
+	var sources []string
+	for _, b := range full.Handlers {
+		sources = append(sources, b.Source+":"+b.Procedure)
+	}
+	if got := strings.Join(sources, ","); got != "form:ПриСозданииНаСервере,element:КонтрагентПриИзменении,command:ЗаполнитьПоОснованию" {
+		t.Errorf("handlers: %s", got)
+	}
+	if b := full.Handlers[1]; strings.Join(b.Path, ".") != "ГруппаШапка.Контрагент" || b.DataPath != "Объект.Контрагент" {
+		t.Errorf("element handler: %+v", b)
+	}
+
+	var elem struct {
+		Elements []foundElement `json:"elements"`
+		Handlers []formBinding  `json:"handlers"`
+	}
+	if res, text := call(GetFormParams{ObjectID: "doc.РеализацияТоваров", Form: "ФормаДокумента", Element: "контрагент"}, &elem); res.IsError {
+		t.Fatal(text)
+	}
+	if len(elem.Elements) != 1 || strings.Join(elem.Elements[0].Path, ".") != "ГруппаШапка.Контрагент" || len(elem.Handlers) != 1 {
+		t.Errorf("element: %+v", elem)
+	}
+
+	if res, text := call(GetFormParams{ObjectID: "cat.Контрагенты", Form: "ФормаЭлемента"}, nil); res.IsError || !strings.Contains(text, "только имя") {
+		t.Errorf("name-only form: %s", text)
+	}
+
+	failures := []struct {
+		args GetFormParams
+		want string
+	}{
+		{GetFormParams{}, "objectId обязателен"},
+		{GetFormParams{ObjectID: "doc.РеализацияТоваров", Element: "Контрагент"}, "element задаётся вместе с form"},
+		{GetFormParams{ObjectID: "doc.НетТакого"}, "объект не найден"},
+		{GetFormParams{ObjectID: "doc.РеализацияТоваров", Form: "ФормаСписка"}, "форма не найдена"},
+	}
+	for _, tt := range failures {
+		if res, text := call(tt.args, nil); !res.IsError || !strings.Contains(text, tt.want) {
+			t.Errorf("%+v: want error %q, got %q", tt.args, tt.want, text)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/graph"
+)
+
+type ExportGraphParams struct {
+	ConfigID  string   `json:"configId,omitempty"`
+	ObjectIDs []string `json:"objectIds,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	Query     string   `json:"query,omitempty"`
+	Depth     *int     `json:"depth,omitempty"`
+	Direction string   `json:"direction,omitempty"`
+	Kinds     []string `json:"kinds,omitempty"`
+	Format    string   `json:"format,omitempty"`
+	MaxNodes  int      `json:"maxNodes,omitempty"`
+}
+
+func ExportGraph(ctx context.Context, req *mcp.CallToolRequest, args ExportGraphParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if len(args.ObjectIDs) == 0 && args.Type == "" && args.Query == "" {
+		return errResult("нужен хотя бы один из параметров objectIds, type, query"), nil, nil
+	}
+	if args.Format == "" {
+		args.Format = graph.FormatDOT
+	}
+	depth := -1
+	if args.Depth != nil {
+		depth = *args.Depth
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	g, err := graph.Build(ctx, currentStore, configID, graph.Selection{
+		IDs: args.ObjectIDs, Type: args.Type, Query: args.Query, Depth: depth,
+		Direction: args.Direction, Kinds: args.Kinds, MaxNodes: args.MaxNodes,
+	})
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	text, err := graph.Render(g, args.Format)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	out := map[string]any{
+		"summary":   fmt.Sprintf("Объектов: %d, связей: %d.", len(g.Nodes), len(g.Edges)),
+		"configId":  configID,
+		"format":    args.Format,
+		"nodeCount": len(g.Nodes),
+		"edgeCount": len(g.Edges),
+		"truncated": g.Truncated,
+		"graph":     text,
+	}
+	return jsonResult(out), nil, nil
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/diff"
+)
+
+type ListRevisionsParams struct {
+	ConfigID string `json:"configId,omitempty"`
+}
+
+func ListRevisions(ctx context.Context, req *mcp.CallToolRequest, args ListRevisionsParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	revs, err := currentStore.ListRevisions(ctx, configID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	rows := make([]map[string]any, len(revs))
+	for i, r := range revs {
+		rows[i] = map[string]any{
+			"revision": r.Number, "configName": r.Meta.ConfigName, "configVersion": r.Meta.ConfigVersion,
+			"exportedAt": r.Meta.ExportedAt, "objectCount": r.Meta.ObjectCount, "indexVersion": r.Meta.IndexVersion, "importedAt": r.ImportedAt,
+		}
+	}
+	out := map[string]any{"summary": fmt.Sprintf("Ревизий: %d.", len(revs)), "configId": configID, "revisions": rows}
+	return jsonResult(out), nil, nil
+}
+
+type DiffSnapshotsParams struct {
+	ConfigID     string `json:"configId,omitempty"`
+	FromRevision int    `json:"fromRevision,omitempty"`
+	ToRevision   int    `json:"toRevision,omitempty"`
+	FromVersion  string `json:"fromVersion,omitempty"`
+	ToVersion    string `json:"toVersion,omitempty"`
+}
+
+func DiffSnapshots(ctx context.Context, req *mcp.CallToolRequest, args DiffSnapshotsParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	rep, err := diff.Between(ctx, currentStore, configID, diff.Selection{
+		FromRevision: args.FromRevision, ToRevision: args.ToRevision,
+		FromVersion: args.FromVersion, ToVersion: args.ToVersion,
+	})
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	d := rep.Result
+	summary := fmt.Sprintf("Ревизия %d → %d: объектов добавлено %d, удалено %d, изменено %d; связей добавлено %d, удалено %d.",
+		rep.From.Number, rep.To.Number, len(d.AddedObjects), len(d.RemovedObjects), len(d.ChangedObjects),
+		len(d.AddedRelations), len(d.RemovedRelations))
+	out := map[string]any{"summary": summary, "configId": configID, "from": rep.From, "to": rep.To, "diff": d}
+	return jsonResult(out), nil, nil
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+type ImpactAnalysisParams struct {
+	ConfigID  string   `json:"configId,omitempty"`
+	ObjectID  string   `json:"objectId"`
+	Direction string   `json:"direction,omitempty"`
+	Kinds     []string `json:"kinds,omitempty"`
+	MaxDepth  int      `json:"maxDepth,omitempty"`
+	MaxNodes  int      `json:"maxNodes,omitempty"`
+}
+
+// impactLayer — объекты, достигнутые за одинаковое число шагов.
+type impactLayer struct {
+	Depth   int                `json:"depth"`
+	Objects []store.ImpactNode `json:"objects"`
+}
+
+func ImpactAnalysis(ctx context.Context, req *mcp.CallToolRequest, args ImpactAnalysisParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.ObjectID == "" {
+		return errResult("objectId обязателен"), nil, nil
+	}
+	if args.Direction != "" && args.Direction != "incoming" && args.Direction != "outgoing" {
+		return errResult("direction: incoming или outgoing"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if !ok {
+		return errResult("объект не найден: " + args.ObjectID), nil, nil
+	}
+	q := store.ImpactQuery{ObjectID: obj.ID, Direction: args.Direction, Kinds: args.Kinds, MaxDepth: args.MaxDepth, MaxNodes: args.MaxNodes}.Normalize()
+	nodes, truncated, err := currentStore.ImpactAnalysis(ctx, configID, q)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	layers := []impactLayer{}
+	for _, n := range nodes {
+		if len(layers) == 0 || layers[len(layers)-1].Depth != n.Depth {
+			layers = append(layers, impactLayer{Depth: n.Depth})
+		}
+		layers[len(layers)-1].Objects = append(layers[len(layers)-1].Objects, n)
+	}
+	summary := fmt.Sprintf("Затронуто объектов: %d, уровней: %d.", len(nodes), len(layers))
+	if q.Direction == "outgoing" {
+		summary = fmt.Sprintf("Зависимостей: %d, уровней: %d.", len(nodes), len(layers))
+	}
+	if truncated {
+		summary += fmt.Sprintf(" Показаны первые %d: увеличьте maxNodes или уменьшите maxDepth.", q.MaxNodes)
+	}
+	out := map[string]any{
+		"summary":   summary,
+		"configId":  configID,
+		"objectId":  q.ObjectID,
+		"direction": q.Direction,
+		"maxDepth":  q.MaxDepth,
+		"total":     len(nodes),
+		"truncated": truncated,
+		"layers":    layers,
+	}
+	return jsonResult(out), nil, nil
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// similarMethods — сколько похожих методов structure_find_method предлагает, если точного совпадения нет.
+const similarMethods = 10
+
+type FindMethodParams struct {
+	ConfigID   string `json:"configId,omitempty"`
+	Name       string `json:"name"`
+	Match      string `json:"match,omitempty"`
+	ObjectType string `json:"objectType,omitempty"`
+	ObjectID   string `json:"objectId,omitempty"`
+	Module     string `json:"module,omitempty"`
+	ExportOnly bool   `json:"exportOnly,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// FindMethod ищет процедуры и функции модулей по имени. Имя вида «ОбщийМодуль.Метод» или «CommonModule.X.Метод»
+// ищет метод в модулях этого объекта — так, как метод вызывают в коде.
+func FindMethod(ctx context.Context, req *mcp.CallToolRequest, args FindMethodParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if strings.TrimSpace(args.Name) == "" {
+		return errResult("name обязателен"), nil, nil
+	}
+	if !store.ValidMethodMatch(args.Match) {
+		return errResult("match: exact, prefix или contains"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if args.ObjectID != "" {
+		obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		if !ok {
+			return errResult("объект не найден: " + args.ObjectID), nil, nil
+		}
+		args.ObjectID = obj.ID
+	}
+	q := store.MethodQuery{
+		Name: strings.TrimSpace(args.Name), Match: args.Match, ObjectType: args.ObjectType, ObjectID: args.ObjectID,
+		Module: args.Module, ExportOnly: args.ExportOnly, Limit: args.Limit,
+	}
+	qualifier := ""
+	if i := strings.LastIndex(q.Name, "."); i > 0 && q.ObjectID == "" {
+		qualifier, q.Name = q.Name[:i], q.Name[i+1:]
+		if strings.Contains(qualifier, ".") {
+			// Объекта с таким id может не быть: тогда методов нет, а similar подскажет похожие.
+			q.ObjectID = qualifier
+			if obj, ok, err := currentStore.GetObject(ctx, configID, qualifier); err != nil {
+				return errResult(err.Error()), nil, nil
+			} else if ok {
+				q.ObjectID = obj.ID
+			}
+		} else {
+			q.ObjectName = qualifier
+		}
+	}
+	methods, truncated, err := currentStore.FindMethods(ctx, configID, q)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	out := map[string]any{
+		"configId":  configID,
+		"methods":   methods,
+		"truncated": truncated,
+	}
+	if qualifier != "" {
+		out["object"] = qualifier
+	}
+	switch {
+	case len(methods) > 0 && truncated:
+		out["summary"] = fmt.Sprintf("Показаны первые %d методов, есть ещё: уточните имя или фильтры.", len(methods))
+	case len(methods) > 0:
+		out["summary"] = fmt.Sprintf("Методов: %d.", len(methods))
+	default:
+		// Похожие — методы с первой половиной имени (у коротких имён — с именем целиком) при тех же фильтрах:
+		// они помогают, когда имя угадано неточно, а пустой similar значит, что такого метода в коде нет.
+		similar := q
+		similar.Match, similar.Limit = store.MethodMatchContains, similarMethods
+		if r := []rune(q.Name); len(r) > 6 {
+			similar.Name = string(r[:len(r)/2])
+		}
+		list, _, err := currentStore.FindMethods(ctx, configID, similar)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		out["similar"] = list
+		out["summary"] = fmt.Sprintf("Метод %s не найден среди модулей, текст которых выгружен в снимок; похожих по имени: %d.", args.Name, len(list))
+	}
+	return jsonResult(out), nil, nil
+}
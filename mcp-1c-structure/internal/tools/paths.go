@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+type FindPathParams struct {
+	ConfigID string   `json:"configId,omitempty"`
+	FromID   string   `json:"fromId"`
+	ToID     string   `json:"toId"`
+	Kinds    []string `json:"kinds,omitempty"`
+	MaxDepth int      `json:"maxDepth,omitempty"`
+	MaxPaths int      `json:"maxPaths,omitempty"`
+}
+
+// pathObject — объект на пути.
+type pathObject struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// foundPath — путь: объекты по порядку и связи между соседними.
+type foundPath struct {
+	Objects []pathObject        `json:"objects"`
+	Steps   []map[string]string `json:"steps"`
+}
+
+func FindPath(ctx context.Context, req *mcp.CallToolRequest, args FindPathParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.FromID == "" || args.ToID == "" {
+		return errResult("fromId и toId обязательны"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	// Концы пути — id, под которыми объекты хранятся: fromId и toId можно писать в любом виде.
+	ends := make([]string, 2)
+	for i, id := range []string{args.FromID, args.ToID} {
+		obj, ok, err := currentStore.GetObject(ctx, configID, id)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		if !ok {
+			return errResult("объект не найден: " + id), nil, nil
+		}
+		ends[i] = obj.ID
+	}
+	if ends[0] == ends[1] {
+		return errResult("fromId и toId совпадают"), nil, nil
+	}
+	q := store.PathQuery{From: ends[0], To: ends[1], Kinds: args.Kinds, MaxDepth: args.MaxDepth, MaxPaths: args.MaxPaths}.Normalize()
+	paths, truncated, err := currentStore.FindPaths(ctx, configID, q)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	out := map[string]any{
+		"configId": configID, "fromId": q.From, "toId": q.To, "maxDepth": q.MaxDepth,
+		"found": len(paths) > 0, "truncated": truncated,
+	}
+	if len(paths) == 0 {
+		// Зависимость может идти в обратную сторону — это тоже ответ на вопрос «как связаны объекты».
+		reverse := q
+		reverse.From, reverse.To = q.To, q.From
+		back, _, err := currentStore.FindPaths(ctx, configID, reverse)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		out["paths"] = []foundPath{}
+		out["reverseFound"] = len(back) > 0
+		out["summary"] = fmt.Sprintf("Путь от %s к %s не найден (maxDepth: %d).", q.From, q.To, q.MaxDepth)
+		if len(back) > 0 {
+			out["summary"] = fmt.Sprintf("Путь от %s к %s не найден (maxDepth: %d), но есть обратный — от %s к %s, длина: %d.", q.From, q.To, q.MaxDepth, q.To, q.From, len(back[0]))
+		}
+		return jsonResult(out), nil, nil
+	}
+	objects := make(map[string]pathObject)
+	result := make([]foundPath, len(paths))
+	for i, path := range paths {
+		fp := foundPath{Objects: []pathObject{}, Steps: make([]map[string]string, len(path))}
+		ids := []string{path[0].From}
+		for j, r := range path {
+			fp.Steps[j] = relationMap(r)
+			ids = append(ids, r.To)
+		}
+		for _, id := range ids {
+			o, ok := objects[id]
+			if !ok {
+				obj, _, err := currentStore.GetObject(ctx, configID, id)
+				if err != nil {
+					return errResult(err.Error()), nil, nil
+				}
+				o = pathObject{ID: id, Type: obj.Type, Name: obj.Name}
+				objects[id] = o
+			}
+			fp.Objects = append(fp.Objects, o)
+		}
+		result[i] = fp
+	}
+	out["paths"] = result
+	out["length"] = len(paths[0])
+	out["summary"] = fmt.Sprintf("Кратчайший путь от %s к %s, длина: %d, путей: %d.", q.From, q.To, len(paths[0]), len(paths))
+	if truncated {
+		out["summary"] = fmt.Sprintf("Кратчайший путь от %s к %s, длина: %d; путей больше, показано: %d (увеличьте maxPaths).", q.From, q.To, len(paths[0]), len(paths))
+	}
+	return jsonResult(out), nil, nil
+}
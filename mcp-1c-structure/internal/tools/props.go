@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+const defaultPropLimit = 50
+
+type FindByPropParams struct {
+	ConfigID       string `json:"configId,omitempty"`
+	PropName       string `json:"propName,omitempty"`
+	PropType       string `json:"propType,omitempty"`
+	TabularSection string `json:"tabularSection,omitempty"`
+	ObjectType     string `json:"objectType,omitempty"`
+	PropKind       string `json:"propKind,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	Offset         int    `json:"offset,omitempty"`
+}
+
+func FindByProp(ctx context.Context, req *mcp.CallToolRequest, args FindByPropParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.PropName == "" && args.PropType == "" && args.TabularSection == "" && args.PropKind == "" {
+		return errResult("нужен хотя бы один из параметров propName, propType, tabularSection, propKind"), nil, nil
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultPropLimit
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	filter := store.PropFilter{Name: args.PropName, Type: args.PropType, TabularSection: args.TabularSection, ObjectType: args.ObjectType, Kind: args.PropKind}
+	matches, total, err := currentStore.FindByProp(ctx, configID, filter, args.Limit, args.Offset)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if matches == nil {
+		matches = []store.PropMatch{}
+	}
+	out := map[string]any{"summary": fmt.Sprintf("Найдено реквизитов: %d.", total), "configId": configID, "total": total, "matches": matches}
+	return jsonResult(out), nil, nil
+}
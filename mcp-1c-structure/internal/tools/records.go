@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+type RegisterRecordsParams struct {
+	ConfigID   string `json:"configId,omitempty"`
+	DocumentID string `json:"documentId,omitempty"`
+	RegisterID string `json:"registerId,omitempty"`
+}
+
+// recordEnd — регистр, в который пишет документ, или документ, который пишет в регистр. Invalid — на другом конце
+// связи не регистр или не документ (предупреждение invalid_register_record при импорте).
+type recordEnd struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Invalid bool   `json:"invalid,omitempty"`
+}
+
+// RegisterRecords отвечает по связям register_record: с documentId — в какие регистры документ записывает движения,
+// с registerId — какие документы записывают движения в регистр.
+func RegisterRecords(ctx context.Context, req *mcp.CallToolRequest, args RegisterRecordsParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if (args.DocumentID == "") == (args.RegisterID == "") {
+		return errResult("укажите documentId или registerId (ровно один)"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	id, direction := args.DocumentID, "outgoing"
+	if args.RegisterID != "" {
+		id, direction = args.RegisterID, "incoming"
+	}
+	o, ok, err := currentStore.GetObject(ctx, configID, id)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if !ok {
+		return errResult("объект не найден: " + id), nil, nil
+	}
+	if direction == "outgoing" && !store.IsRecorder(o.Type) {
+		return errResult(fmt.Sprintf("объект не документ: %s (%s)", id, o.Type)), nil, nil
+	}
+	if direction == "incoming" && !store.IsRegister(o.Type) {
+		return errResult(fmt.Sprintf("объект не регистр: %s (%s)", id, o.Type)), nil, nil
+	}
+
+	q := store.ImpactQuery{ObjectID: o.ID, Direction: direction, Kinds: []string{store.KindRegisterRecord}, MaxDepth: 1, MaxNodes: store.MaxImpactNodes}.Normalize()
+	nodes, truncated, err := currentStore.ImpactAnalysis(ctx, configID, q)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	list := make([]recordEnd, 0, len(nodes))
+	for _, n := range nodes {
+		valid := store.IsRegister(n.Type)
+		if direction == "incoming" {
+			valid = store.IsRecorder(n.Type)
+		}
+		list = append(list, recordEnd{ID: n.ID, Type: n.Type, Name: n.Name, Invalid: !valid})
+	}
+	more := ""
+	if truncated {
+		more = fmt.Sprintf(" Показаны первые %d.", q.MaxNodes)
+	}
+	out := map[string]any{
+		"configId":  configID,
+		"total":     len(list),
+		"truncated": truncated,
+	}
+	if direction == "outgoing" {
+		out["summary"] = fmt.Sprintf("Регистров, в которые документ записывает движения: %d.%s", len(list), more)
+		out["documentId"] = q.ObjectID
+		out["registers"] = list
+	} else {
+		mode := store.RecorderMode(o.Type, o.Register)
+		if mode == store.RecorderForbidden {
+			more += " Регистр сведений независимый: регистраторов у него быть не должно."
+		}
+		out["summary"] = fmt.Sprintf("Документов, записывающих движения в регистр: %d.%s", len(list), more)
+		out["registerId"] = q.ObjectID
+		out["recorderMode"] = mode
+		out["recorders"] = list
+	}
+	return jsonResult(out), nil, nil
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+func TestRegisterRecords(t *testing.T) {
+	t.Setenv("MCP_1C_STRUCTURE_CONFIG_ID", "")
+	s, err := memory.New("default", "../../snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s, snapshot.Meta{})
+	t.Cleanup(func() { SetStore(nil, snapshot.Meta{}) })
+	call := func(args RegisterRecordsParams) (*mcp.CallToolResult, string) {
+		t.Helper()
+		res, _, err := RegisterRecords(context.Background(), nil, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, res.Content[0].(*mcp.TextContent).Text
+	}
+
+	var out struct {
+		DocumentID   string      `json:"documentId"`
+		RegisterID   string      `json:"registerId"`
+		RecorderMode string      `json:"recorderMode"`
+		Registers    []recordEnd `json:"registers"`
+		Recorders    []recordEnd `json:"recorders"`
+	}
+	if res, text := call(RegisterRecordsParams{DocumentID: "Document.РеализацияТоваров"}); res.IsError {
+		t.Fatal(text)
+	} else if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.DocumentID != "doc.РеализацияТоваров" || len(out.Registers) != 1 || out.Registers[0].ID != "accumulationregister.ВзаиморасчетыСКонтрагентами" || out.Registers[0].Invalid {
+		t.Errorf("documentId: unexpected answer %+v", out)
+	}
+
+	out.Registers = nil
+	if res, text := call(RegisterRecordsParams{RegisterID: "AccumulationRegister.ВзаиморасчетыСКонтрагентами"}); res.IsError {
+		t.Fatal(text)
+	} else if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.RecorderMode != "required" || len(out.Recorders) != 1 || out.Recorders[0].ID != "doc.РеализацияТоваров" {
+		t.Errorf("registerId: unexpected answer %+v", out)
+	}
+
+	failures := []struct {
+		args RegisterRecordsParams
+		want string
+	}{
+		{RegisterRecordsParams{}, "ровно один"},
+		{RegisterRecordsParams{DocumentID: "doc.РеализацияТоваров", RegisterID: "accumulationregister.ВзаиморасчетыСКонтрагентами"}, "ровно один"},
+		{RegisterRecordsParams{DocumentID: "cat.Контрагенты"}, "объект не документ"},
+		{RegisterRecordsParams{RegisterID: "doc.РеализацияТоваров"}, "объект не регистр"},
+		{RegisterRecordsParams{DocumentID: "doc.НетТакого"}, "объект не найден"},
+	}
+	for _, tt := range failures {
+		if res, text := call(tt.args); !res.IsError || !strings.Contains(text, tt.want) {
+			t.Errorf("%+v: want error %q, got %q", tt.args, tt.want, text)
+		}
+	}
+}
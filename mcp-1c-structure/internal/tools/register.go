@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+// registerSummary — сводка регистра для structure_get_object: измерения, ресурсы и реквизиты по именам и
+// виртуальные таблицы, которые допускает вид регистра. nil — объект не регистр.
+func registerSummary(o snapshot.Object) map[string]any {
+	if !store.IsRegister(o.Type) {
+		return nil
+	}
+	out := map[string]any{}
+	if o.Register != nil {
+		out["properties"] = o.Register
+	}
+	fields := map[string][]string{snapshot.PropDimension: {}, snapshot.PropResource: {}, snapshot.PropAttribute: {}}
+	known := false
+	for _, p := range o.Props {
+		if _, ok := fields[p.Kind]; ok {
+			fields[p.Kind] = append(fields[p.Kind], p.Name)
+			known = true
+		}
+	}
+	if known {
+		out["dimensions"] = fields[snapshot.PropDimension]
+		out["resources"] = fields[snapshot.PropResource]
+		out["attributes"] = fields[snapshot.PropAttribute]
+	} else {
+		out["note"] = "виды полей (измерение, ресурс, реквизит) в снимке не указаны"
+	}
+	if vt := virtualTables(o); vt != nil {
+		out["virtualTables"] = vt
+	}
+	return out
+}
+
+// virtualTables — виртуальные таблицы регистра в языке запросов; nil — неизвестно (регистр расчёта или нет свойств).
+func virtualTables(o snapshot.Object) []string {
+	r := o.Register
+	switch strings.ToLower(o.Type) {
+	case "informationregister":
+		if r == nil || r.Periodicity == "" {
+			return nil
+		}
+		if strings.EqualFold(r.Periodicity, "Nonperiodical") {
+			return []string{}
+		}
+		return []string{"СрезПоследних", "СрезПервых"}
+	case "accumulationregister":
+		if r == nil || r.RegisterType == "" {
+			return nil
+		}
+		if strings.EqualFold(r.RegisterType, "Turnovers") {
+			return []string{"Обороты"}
+		}
+		return []string{"Остатки", "Обороты", "ОстаткиИОбороты"}
+	case "accountingregister":
+		tables := []string{"Остатки", "Обороты", "ОстаткиИОбороты", "ДвиженияССубконто"}
+		if r != nil && r.Correspondence {
+			tables = append(tables, "ОборотыДтКт")
+		}
+		return tables
+	}
+	return nil
+}
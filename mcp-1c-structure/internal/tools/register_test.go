@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+)
+
+func TestRegisterSummary(t *testing.T) {
+	fields := []snapshot.Prop{
+		{Name: "Номенклатура", Kind: snapshot.PropDimension},
+		{Name: "Склад", Kind: snapshot.PropDimension},
+		{Name: "Количество", Kind: snapshot.PropResource},
+		{Name: "Комментарий", Kind: snapshot.PropAttribute},
+	}
+	tests := []struct {
+		name   string
+		object snapshot.Object
+		want   map[string]any
+	}{
+		{name: "не регистр", object: snapshot.Object{Type: "Catalog"}, want: nil},
+		{name: "остатки", object: snapshot.Object{Type: "AccumulationRegister", Props: fields, Register: &snapshot.Register{RegisterType: "Balance"}},
+			want: map[string]any{
+				"properties":    &snapshot.Register{RegisterType: "Balance"},
+				"dimensions":    []string{"Номенклатура", "Склад"},
+				"resources":     []string{"Количество"},
+				"attributes":    []string{"Комментарий"},
+				"virtualTables": []string{"Остатки", "Обороты", "ОстаткиИОбороты"},
+			}},
+		{name: "обороты", object: snapshot.Object{Type: "AccumulationRegister", Props: fields, Register: &snapshot.Register{RegisterType: "Turnovers"}},
+			want: map[string]any{
+				"properties":    &snapshot.Register{RegisterType: "Turnovers"},
+				"dimensions":    []string{"Номенклатура", "Склад"},
+				"resources":     []string{"Количество"},
+				"attributes":    []string{"Комментарий"},
+				"virtualTables": []string{"Обороты"},
+			}},
+		{name: "непериодический регистр сведений без видов полей",
+			object: snapshot.Object{Type: "InformationRegister", Props: []snapshot.Prop{{Name: "Курс"}}, Register: &snapshot.Register{Periodicity: "Nonperiodical"}},
+			want: map[string]any{
+				"properties":    &snapshot.Register{Periodicity: "Nonperiodical"},
+				"note":          "виды полей (измерение, ресурс, реквизит) в снимке не указаны",
+				"virtualTables": []string{},
+			}},
+		{name: "периодический регистр сведений", object: snapshot.Object{Type: "InformationRegister", Register: &snapshot.Register{Periodicity: "Day"}},
+			want: map[string]any{
+				"properties":    &snapshot.Register{Periodicity: "Day"},
+				"note":          "виды полей (измерение, ресурс, реквизит) в снимке не указаны",
+				"virtualTables": []string{"СрезПоследних", "СрезПервых"},
+			}},
+		{name: "регистр бухгалтерии с корреспонденцией", object: snapshot.Object{Type: "AccountingRegister", Register: &snapshot.Register{Correspondence: true}},
+			want: map[string]any{
+				"properties":    &snapshot.Register{Correspondence: true},
+				"note":          "виды полей (измерение, ресурс, реквизит) в снимке не указаны",
+				"virtualTables": []string{"Остатки", "Обороты", "ОстаткиИОбороты", "ДвиженияССубконто", "ОборотыДтКт"},
+			}},
+		{name: "свойства не выгружены", object: snapshot.Object{Type: "InformationRegister"},
+			want: map[string]any{"note": "виды полей (измерение, ресурс, реквизит) в снимке не указаны"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registerSummary(tt.object); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("registerSummary:\n got %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/store"
+)
+
+const (
+	defaultSubsystemLimit = 200
+	maxSubsystemLimit     = 1000
+)
+
+type SubsystemTreeParams struct {
+	ConfigID    string `json:"configId,omitempty"`
+	SubsystemID string `json:"subsystemId,omitempty"`
+	ObjectID    string `json:"objectId,omitempty"`
+	Recursive   bool   `json:"recursive,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	Offset      int    `json:"offset,omitempty"`
+}
+
+// subsystemObject — объект состава подсистемы. Missing — объекта нет в снимке (предупреждение при импорте).
+type subsystemObject struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Synonym   string `json:"synonym"`
+	Subsystem string `json:"subsystem"`
+	Missing   bool   `json:"missing,omitempty"`
+}
+
+// subsystemTitle — подсистема на пути от корня.
+type subsystemTitle struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Synonym string `json:"synonym"`
+}
+
+// SubsystemTree отвечает в одном из трёх режимов: без параметров — дерево подсистем, с subsystemId — подсистема
+// с вложенными и её состав, с objectId — подсистемы, в которые входит объект.
+func SubsystemTree(ctx context.Context, req *mcp.CallToolRequest, args SubsystemTreeParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
+	}
+	if args.SubsystemID != "" && args.ObjectID != "" {
+		return errResult("укажите subsystemId или objectId, но не оба"), nil, nil
+	}
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	subs, err := currentStore.Subsystems(ctx, configID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	idx := store.NewSubsystemIndex(subs, func(id string) (string, bool) {
+		o, ok, err := currentStore.GetObject(ctx, configID, id)
+		return o.ID, ok && err == nil
+	})
+
+	switch {
+	case args.ObjectID != "":
+		obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		if !ok {
+			return errResult("объект не найден: " + args.ObjectID), nil, nil
+		}
+		type membership struct {
+			subsystemTitle
+			Path []subsystemTitle `json:"path"`
+		}
+		list := []membership{}
+		for _, ref := range idx.Containing(obj.ID) {
+			m := membership{subsystemTitle: titleOf(idx, ref.ID), Path: []subsystemTitle{}}
+			for _, id := range ref.Path {
+				m.Path = append(m.Path, titleOf(idx, id))
+			}
+			list = append(list, m)
+		}
+		out := map[string]any{
+			"summary":    fmt.Sprintf("Подсистем, в которые входит объект: %d.", len(list)),
+			"configId":   configID,
+			"objectId":   obj.ID,
+			"subsystems": list,
+		}
+		return jsonResult(out), nil, nil
+
+	case args.SubsystemID != "":
+		tree := idx.Tree(args.SubsystemID)
+		if len(tree) == 0 {
+			return errResult("подсистема не найдена: " + args.SubsystemID), nil, nil
+		}
+		if args.Limit <= 0 {
+			args.Limit = defaultSubsystemLimit
+		}
+		if args.Limit > maxSubsystemLimit {
+			args.Limit = maxSubsystemLimit
+		}
+		if args.Offset < 0 {
+			args.Offset = 0
+		}
+		members := idx.Objects(tree[0].ID, args.Recursive)
+		total := len(members)
+		members = members[min(args.Offset, total):min(args.Offset+args.Limit, total)]
+		objects := make([]subsystemObject, 0, len(members))
+		for _, m := range members {
+			item := subsystemObject{ID: m.ObjectID, Subsystem: m.Subsystem}
+			o, ok, err := currentStore.GetObject(ctx, configID, m.ObjectID)
+			if err != nil {
+				return errResult(err.Error()), nil, nil
+			}
+			if ok {
+				item.Type, item.Name, item.Synonym = o.Type, o.Name, o.Synonym
+			} else {
+				item.Missing = true
+			}
+			objects = append(objects, item)
+		}
+		out := map[string]any{
+			"summary":   fmt.Sprintf("Объектов в подсистеме: %d.", total),
+			"configId":  configID,
+			"subsystem": tree[0],
+			"path":      titlesOf(idx, idx.Path(tree[0].ID)),
+			"recursive": args.Recursive,
+			"total":     total,
+			"objects":   objects,
+		}
+		return jsonResult(out), nil, nil
+	}
+
+	tree := idx.Tree("")
+	if tree == nil {
+		tree = []store.SubsystemNode{}
+	}
+	out := map[string]any{
+		"summary":        fmt.Sprintf("Подсистем: %d.", len(subs)),
+		"configId":       configID,
+		"subsystemCount": len(subs),
+		"tree":           tree,
+	}
+	return jsonResult(out), nil, nil
+}
+
+func titleOf(idx *store.SubsystemIndex, id string) subsystemTitle {
+	o, _ := idx.Get(id)
+	return subsystemTitle{ID: id, Name: o.Name, Synonym: o.Synonym}
+}
+
+func titlesOf(idx *store.SubsystemIndex, ids []string) []subsystemTitle {
+	out := make([]subsystemTitle, len(ids))
+	for i, id := range ids {
+		out[i] = titleOf(idx, id)
+	}
+	return out
+}
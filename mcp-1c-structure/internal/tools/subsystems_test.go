@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/ser/mcp-1c-structure/internal/snapshot"
+	"github.com/ser/mcp-1c-structure/internal/store"
+	"github.com/ser/mcp-1c-structure/internal/store/memory"
+)
+
+// Подсистемы и объекты состава отдаются с id, под которыми они хранятся, а не в нормализованном виде.
+func TestSubsystemTreeStoredIDs(t *testing.T) {
+	t.Setenv("MCP_1C_STRUCTURE_CONFIG_ID", "")
+	ctx := context.Background()
+	s, err := memory.New("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s, snapshot.Meta{})
+	t.Cleanup(func() { SetStore(nil, snapshot.Meta{}) })
+	objects := []snapshot.Object{
+		{ID: "Subsystem.Продажи", Type: "Subsystem", Name: "Продажи", Subsystems: []string{"subsystem.Продажи.Опт"}},
+		{ID: "Subsystem.Продажи.Опт", Type: "Subsystem", Name: "Опт", Content: []string{"cat.Контрагенты", "Catalog.Контрагенты"}},
+		{ID: "Catalog.Контрагенты", Type: "Catalog", Name: "Контрагенты"},
+	}
+	if _, err := s.Import(ctx, store.DefaultConfigID, snapshot.FromSlices(snapshot.Meta{}, objects, nil), store.ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	call := func(args SubsystemTreeParams, out any) {
+		t.Helper()
+		res, _, err := SubsystemTree(ctx, nil, args)
+		if err != nil || res.IsError {
+			t.Fatalf("SubsystemTree(%+v): %v %+v", args, err, res)
+		}
+		if err := json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var members struct {
+		Subsystem store.SubsystemNode `json:"subsystem"`
+		Path      []subsystemTitle    `json:"path"`
+		Objects   []subsystemObject   `json:"objects"`
+	}
+	call(SubsystemTreeParams{SubsystemID: "subsystem.Продажи.Опт"}, &members)
+	if members.Subsystem.ID != "Subsystem.Продажи.Опт" || len(members.Path) != 2 || members.Path[0].ID != "Subsystem.Продажи" {
+		t.Errorf("subsystemId: unexpected subsystem %+v, path %+v", members.Subsystem, members.Path)
+	}
+	if len(members.Objects) != 1 || members.Objects[0].ID != "Catalog.Контрагенты" || members.Objects[0].Missing {
+		t.Errorf("subsystemId: want Catalog.Контрагенты once, got %+v", members.Objects)
+	}
+
+	var containing struct {
+		ObjectID   string `json:"objectId"`
+		Subsystems []struct {
+			ID   string           `json:"id"`
+			Path []subsystemTitle `json:"path"`
+		} `json:"subsystems"`
+	}
+	call(SubsystemTreeParams{ObjectID: "cat.Контрагенты"}, &containing)
+	if containing.ObjectID != "Catalog.Контрагенты" || len(containing.Subsystems) != 1 || containing.Subsystems[0].ID != "Subsystem.Продажи.Опт" {
+		t.Errorf("objectId: unexpected answer %+v", containing)
+	}
+}
@@ -3,10 +3,14 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/ser/mcp-1c-structure/internal/config"
+	"github.com/ser/mcp-1c-structure/internal/search"
 	"github.com/ser/mcp-1c-structure/internal/snapshot"
 	"github.com/ser/mcp-1c-structure/internal/store"
 )
@@ -22,13 +26,47 @@ func SetStore(s store.Store, m snapshot.Meta) {
 const defaultLimit = 20
 const maxLimit = 50
 
-type SnapshotInfoParams struct{}
+// resolveConfigID выбирает конфигурацию для чтения: явный configId, затем MCP_1C_STRUCTURE_CONFIG_ID,
+// затем единственная загруженная конфигурация; пустая база — store.DefaultConfigID. Если загружено несколько
+// конфигураций, а configId не указан, угадывать нельзя: ошибка перечисляет доступные.
+func resolveConfigID(ctx context.Context, id string) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+	if id = config.ConfigID(); id != "" {
+		return id, nil
+	}
+	configs, err := currentStore.ListConfigs(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch len(configs) {
+	case 0:
+		return store.DefaultConfigID, nil
+	case 1:
+		return configs[0].ID, nil
+	}
+	ids := make([]string, len(configs))
+	for i, c := range configs {
+		ids[i] = c.ID
+	}
+	return "", fmt.Errorf("загружено несколько конфигураций, укажите configId: %s", strings.Join(ids, ", "))
+}
+
+type SnapshotInfoParams struct {
+	ConfigID string `json:"configId,omitempty"`
+}
 
 func SnapshotInfo(ctx context.Context, req *mcp.CallToolRequest, args SnapshotInfoParams) (*mcp.CallToolResult, any, error) {
 	meta := currentMeta
+	configID := args.ConfigID
 	if currentStore != nil {
 		var err error
-		meta, err = currentStore.Meta(ctx)
+		configID, err = resolveConfigID(ctx, args.ConfigID)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		meta, err = currentStore.Meta(ctx, configID)
 		if err != nil {
 			return errResult("Meta: " + err.Error()), nil, nil
 		}
@@ -40,19 +78,51 @@ func SnapshotInfo(ctx context.Context, req *mcp.CallToolRequest, args SnapshotIn
 	}
 	summary := fmt.Sprintf("Снимок %s %s, %d объектов, выгрузка от %s.", meta.ConfigName, meta.ConfigVersion, meta.ObjectCount, meta.ExportedAt)
 	out := map[string]any{
-		"summary": summary, "configName": meta.ConfigName, "configVersion": meta.ConfigVersion,
+		"summary": summary, "configId": configID, "configName": meta.ConfigName, "configVersion": meta.ConfigVersion,
 		"exportedAt": meta.ExportedAt, "source": meta.Source, "objectCount": meta.ObjectCount,
+		"indexVersion": meta.IndexVersion, "supportedIndexVersion": snapshot.IndexVersion,
+	}
+	return jsonResult(out), nil, nil
+}
+
+type ListConfigsParams struct{}
+
+func ListConfigs(ctx context.Context, req *mcp.CallToolRequest, args ListConfigsParams) (*mcp.CallToolResult, any, error) {
+	if currentStore == nil {
+		return errResult("хранилище не инициализировано"), nil, nil
 	}
+	configs, err := currentStore.ListConfigs(ctx)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	rows := make([]map[string]any, len(configs))
+	for i, c := range configs {
+		rows[i] = map[string]any{
+			"configId": c.ID, "configName": c.Meta.ConfigName, "configVersion": c.Meta.ConfigVersion,
+			"exportedAt": c.Meta.ExportedAt, "source": c.Meta.Source, "objectCount": c.Meta.ObjectCount,
+		}
+	}
+	out := map[string]any{"summary": fmt.Sprintf("Загружено конфигураций: %d.", len(configs)), "configs": rows}
 	return jsonResult(out), nil, nil
 }
 
 type SearchParams struct {
-	Query  string `json:"query"`
-	Type   string `json:"type"`
-	Limit  int    `json:"limit"`
-	Offset int    `json:"offset"`
+	ConfigID string `json:"configId,omitempty"`
+	Query    string `json:"query"`
+	Type     string `json:"type,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+	Mode     string `json:"mode,omitempty"`  // substring (по умолчанию), fulltext или fuzzy
+	Fuzzy    bool   `json:"fuzzy,omitempty"` // substring: если ничего не нашлось, повторить поиск в режиме fuzzy
 }
 
+// Режимы structure_search.
+const (
+	searchModeSubstring = "substring"
+	searchModeFullText  = "fulltext"
+	searchModeFuzzy     = "fuzzy"
+)
+
 func Search(ctx context.Context, req *mcp.CallToolRequest, args SearchParams) (*mcp.CallToolResult, any, error) {
 	if currentStore == nil {
 		return errResult("хранилище не инициализировано"), nil, nil
@@ -60,16 +130,36 @@ func Search(ctx context.Context, req *mcp.CallToolRequest, args SearchParams) (*
 	if args.Query == "" {
 		return errResult("query обязателен"), nil, nil
 	}
+	if args.Mode != "" && args.Mode != searchModeSubstring && args.Mode != searchModeFullText && args.Mode != searchModeFuzzy {
+		return errResult("mode: ожидается substring, fulltext или fuzzy"), nil, nil
+	}
 	if args.Limit <= 0 {
 		args.Limit = defaultLimit
 	}
 	if args.Limit > maxLimit {
 		args.Limit = maxLimit
 	}
-	objects, total, err := currentStore.Search(ctx, args.Query, args.Type, args.Limit, args.Offset)
+	configID, err := resolveConfigID(ctx, args.ConfigID)
 	if err != nil {
 		return errResult(err.Error()), nil, nil
 	}
+	switch args.Mode {
+	case searchModeFullText:
+		hits, total, err := currentStore.SearchFullText(ctx, configID, args.Query, args.Type, args.Limit, args.Offset)
+		if err != nil {
+			return errResult(err.Error()), nil, nil
+		}
+		return searchHitsResult(searchModeFullText, hits, total, nil), nil, nil
+	case searchModeFuzzy:
+		return searchFuzzy(ctx, configID, args, nil)
+	}
+	objects, total, err := currentStore.Search(ctx, configID, args.Query, args.Type, args.Limit, args.Offset)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if total == 0 && args.Fuzzy {
+		return searchFuzzy(ctx, configID, args, map[string]any{"fallback": true})
+	}
 	matches := make([]map[string]string, len(objects))
 	for i := range objects {
 		matches[i] = map[string]string{
@@ -81,7 +171,45 @@ func Search(ctx context.Context, req *mcp.CallToolRequest, args SearchParams) (*
 	return jsonResult(out), nil, nil
 }
 
+// searchFuzzy ищет по сходству с запросом и его вариантами в другой раскладке и транслитерации.
+func searchFuzzy(ctx context.Context, configID string, args SearchParams, extra map[string]any) (*mcp.CallToolResult, any, error) {
+	variants := search.Variants(args.Query)
+	hits, total, err := currentStore.SearchFuzzy(ctx, configID, variants, args.Type, args.Limit, args.Offset)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	if extra == nil {
+		extra = map[string]any{}
+	}
+	extra["variants"] = variants
+	return searchHitsResult(searchModeFuzzy, hits, total, extra), nil, nil
+}
+
+// searchHitsResult оформляет ранжированные результаты: к полям substring-поиска добавляются score и matchedField,
+// для fuzzy — ещё matchedQuery и variant (query, layout или translit).
+func searchHitsResult(mode string, hits []store.SearchHit, total int, extra map[string]any) *mcp.CallToolResult {
+	matches := make([]map[string]any, len(hits))
+	for i, h := range hits {
+		m := map[string]any{
+			"id": h.Object.ID, "type": h.Object.Type,
+			"name": h.Object.Name, "synonym": h.Object.Synonym,
+			"score": math.Round(h.Score*1e4) / 1e4, "matchedField": h.MatchedField,
+		}
+		if h.Variant.Text != "" {
+			m["matchedQuery"] = h.Variant.Text
+			m["variant"] = h.Variant.Kind
+		}
+		matches[i] = m
+	}
+	out := map[string]any{"summary": fmt.Sprintf("Найдено %d объектов.", total), "total": total, "mode": mode, "matches": matches}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return jsonResult(out)
+}
+
 type GetObjectParams struct {
+	ConfigID string `json:"configId,omitempty"`
 	ObjectID string `json:"objectId"`
 }
 
@@ -92,22 +220,44 @@ func GetObject(ctx context.Context, req *mcp.CallToolRequest, args GetObjectPara
 	if args.ObjectID == "" {
 		return errResult("objectId обязателен"), nil, nil
 	}
-	obj, ok, err := currentStore.GetObject(ctx, args.ObjectID)
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	obj, ok, err := currentStore.GetObject(ctx, configID, args.ObjectID)
 	if err != nil {
 		return errResult(err.Error()), nil, nil
 	}
 	if !ok {
 		return errResult("Объект не найден: " + args.ObjectID), nil, nil
 	}
+	// Формы отдаются именами, как в прежних снимках: их устройство отдаёт structure_get_form.
+	// Срез заменяется новым: backend memory отдаёт объект, разделяющий формы с хранилищем.
+	detailed := false
+	if obj.Forms != nil {
+		forms := make([]snapshot.Form, len(obj.Forms))
+		for i, f := range obj.Forms {
+			detailed = detailed || f.Detailed()
+			forms[i] = snapshot.Form{Name: f.Name}
+		}
+		obj.Forms = forms
+	}
 	out := map[string]any{"summary": "Объект " + obj.Name + ".", "object": obj, "source": "snapshot/objects.json"}
+	if detailed {
+		out["formsNote"] = "у форм есть реквизиты, элементы и обработчики: structure_get_form"
+	}
+	if reg := registerSummary(obj); reg != nil {
+		out["register"] = reg
+	}
 	return jsonResult(out), nil, nil
 }
 
 type FindReferencesParams struct {
+	ConfigID  string `json:"configId,omitempty"`
 	ObjectID  string `json:"objectId"`
-	Direction string `json:"direction"`
-	Kind      string `json:"kind"`
-	Limit     int    `json:"limit"`
+	Direction string `json:"direction,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
 }
 
 func FindReferences(ctx context.Context, req *mcp.CallToolRequest, args FindReferencesParams) (*mcp.CallToolResult, any, error) {
@@ -123,17 +273,21 @@ func FindReferences(ctx context.Context, req *mcp.CallToolRequest, args FindRefe
 	if args.Limit > 100 {
 		args.Limit = 100
 	}
-	incoming, outgoing, err := currentStore.FindReferences(ctx, args.ObjectID, args.Direction, args.Kind, args.Limit)
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	incoming, outgoing, err := currentStore.FindReferences(ctx, configID, args.ObjectID, args.Direction, args.Kind, args.Limit)
 	if err != nil {
 		return errResult(err.Error()), nil, nil
 	}
 	inMaps := make([]map[string]string, len(incoming))
 	for i := range incoming {
-		inMaps[i] = map[string]string{"from": incoming[i].From, "to": incoming[i].To, "kind": incoming[i].Kind}
+		inMaps[i] = relationMap(incoming[i])
 	}
 	outMaps := make([]map[string]string, len(outgoing))
 	for i := range outgoing {
-		outMaps[i] = map[string]string{"from": outgoing[i].From, "to": outgoing[i].To, "kind": outgoing[i].Kind}
+		outMaps[i] = relationMap(outgoing[i])
 	}
 	out := map[string]any{
 		"summary":  fmt.Sprintf("Входящих: %d, исходящих: %d.", len(incoming), len(outgoing)),
@@ -142,13 +296,29 @@ func FindReferences(ctx context.Context, req *mcp.CallToolRequest, args FindRefe
 	return jsonResult(out), nil, nil
 }
 
-type ListTypesParams struct{}
+// relationMap — связь в ответе; prop есть у связей, выведенных из типа реквизита (путь реквизита) и из кода модулей
+// (место обращения, у вызова — с вызываемым методом).
+func relationMap(r snapshot.Relation) map[string]string {
+	m := map[string]string{"from": r.From, "to": r.To, "kind": r.Kind}
+	if r.Prop != "" {
+		m["prop"] = r.Prop
+	}
+	return m
+}
+
+type ListTypesParams struct {
+	ConfigID string `json:"configId,omitempty"`
+}
 
 func ListTypes(ctx context.Context, req *mcp.CallToolRequest, args ListTypesParams) (*mcp.CallToolResult, any, error) {
 	if currentStore == nil {
 		return errResult("хранилище не инициализировано"), nil, nil
 	}
-	types, err := currentStore.ListTypes(ctx)
+	configID, err := resolveConfigID(ctx, args.ConfigID)
+	if err != nil {
+		return errResult(err.Error()), nil, nil
+	}
+	types, err := currentStore.ListTypes(ctx, configID)
 	if err != nil {
 		return errResult(err.Error()), nil, nil
 	}
@@ -161,7 +331,9 @@ func ListTypes(ctx context.Context, req *mcp.CallToolRequest, args ListTypesPara
 }
 
 type ImportSnapshotParams struct {
+	ConfigID    string `json:"configId,omitempty"`
 	SnapshotDir string `json:"snapshotDir"`
+	Strict      bool   `json:"strict,omitempty"`
 }
 
 func ImportSnapshot(ctx context.Context, req *mcp.CallToolRequest, args ImportSnapshotParams) (*mcp.CallToolResult, any, error) {
@@ -175,20 +347,43 @@ func ImportSnapshot(ctx context.Context, req *mcp.CallToolRequest, args ImportSn
 	if dir == "" {
 		return errResult("snapshotDir обязателен или задайте MCP_1C_STRUCTURE_SNAPSHOT_DIR"), nil, nil
 	}
-	meta, objects, relations, err := snapshot.LoadSnapshot(dir)
+	src, err := snapshot.OpenDir(dir)
 	if err != nil {
-		return errResult("LoadSnapshot: " + err.Error()), nil, nil
+		return errResult("OpenDir: " + err.Error()), nil, nil
+	}
+	// Импорт не выбирает единственную загруженную конфигурацию сам: без configId снимок не должен её затереть.
+	configID := args.ConfigID
+	if configID == "" {
+		configID = config.ConfigID()
 	}
-	if err := currentStore.Import(ctx, meta, objects, relations); err != nil {
+	if configID == "" {
+		configID = store.DefaultConfigID
+	}
+	res, err := currentStore.Import(ctx, configID, src, store.ImportOptions{Strict: args.Strict})
+	var verr *store.ValidationError
+	if errors.As(err, &verr) {
+		out := map[string]any{"summary": "Импорт отклонён: в снимке есть ошибки (strict).", "configId": configID, "validation": verr.Report}
+		res := jsonResult(out)
+		res.IsError = true
+		return res, nil, nil
+	}
+	if err != nil {
 		return errResult("Import: " + err.Error()), nil, nil
 	}
-	summary := fmt.Sprintf("Импорт завершён: %s %s, объектов %d, связей %d.", meta.ConfigName, meta.ConfigVersion, len(objects), len(relations))
+	summary := fmt.Sprintf("Импорт завершён: %s %s, объектов %d, связей %d, ревизия %d.", res.Meta.ConfigName, res.Meta.ConfigVersion, res.ObjectCount, res.RelationCount, res.Revision)
+	if nErr, nWarn := res.Validation.Counts(); nErr+nWarn > 0 {
+		summary += fmt.Sprintf(" Проверка: ошибок %d, предупреждений %d (см. validation).", nErr, nWarn)
+	}
 	out := map[string]any{
 		"summary":           summary,
-		"objectCount":       len(objects),
-		"relationsImported": len(relations),
-		"configName":        meta.ConfigName,
-		"configVersion":     meta.ConfigVersion,
+		"configId":          configID,
+		"revision":          res.Revision,
+		"objectCount":       res.ObjectCount,
+		"relationsImported": res.RelationCount,
+		"configName":        res.Meta.ConfigName,
+		"configVersion":     res.Meta.ConfigVersion,
+		"stats":             res.Stats,
+		"validation":        res.Validation,
 	}
 	return jsonResult(out), nil, nil
 }
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Повторные импорты дублировали рёбра: оставляем по одному и запрещаем дубли.
+DELETE FROM relations a
+USING relations b
+WHERE a.ctid < b.ctid
+  AND a.from_id = b.from_id
+  AND a.to_id = b.to_id
+  AND a.kind = b.kind;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_relations_unique ON relations(from_id, to_id, kind);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_relations_unique;
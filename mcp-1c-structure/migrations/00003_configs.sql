@@ -0,0 +1,42 @@
+-- +goose Up
+-- Несколько конфигураций в одной базе: config_id входит в ключи meta, objects и relations.
+-- Уже загруженный снимок попадает в конфигурацию 'default'.
+ALTER TABLE meta ADD COLUMN IF NOT EXISTS config_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE meta DROP CONSTRAINT IF EXISTS meta_pkey;
+ALTER TABLE meta ADD PRIMARY KEY (config_id, key);
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS config_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE objects DROP CONSTRAINT IF EXISTS objects_pkey;
+ALTER TABLE objects ADD PRIMARY KEY (config_id, id);
+DROP INDEX IF EXISTS idx_objects_type;
+CREATE INDEX IF NOT EXISTS idx_objects_type ON objects(config_id, type);
+
+ALTER TABLE relations ADD COLUMN IF NOT EXISTS config_id TEXT NOT NULL DEFAULT 'default';
+DROP INDEX IF EXISTS idx_relations_from;
+DROP INDEX IF EXISTS idx_relations_to;
+DROP INDEX IF EXISTS idx_relations_unique;
+CREATE INDEX IF NOT EXISTS idx_relations_from ON relations(config_id, from_id);
+CREATE INDEX IF NOT EXISTS idx_relations_to ON relations(config_id, to_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_relations_unique ON relations(config_id, from_id, to_id, kind);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_relations_unique;
+DROP INDEX IF EXISTS idx_relations_to;
+DROP INDEX IF EXISTS idx_relations_from;
+DELETE FROM relations WHERE config_id <> 'default';
+ALTER TABLE relations DROP COLUMN config_id;
+CREATE INDEX IF NOT EXISTS idx_relations_from ON relations(from_id);
+CREATE INDEX IF NOT EXISTS idx_relations_to ON relations(to_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_relations_unique ON relations(from_id, to_id, kind);
+
+DROP INDEX IF EXISTS idx_objects_type;
+DELETE FROM objects WHERE config_id <> 'default';
+ALTER TABLE objects DROP CONSTRAINT objects_pkey;
+ALTER TABLE objects DROP COLUMN config_id;
+ALTER TABLE objects ADD PRIMARY KEY (id);
+CREATE INDEX IF NOT EXISTS idx_objects_type ON objects(type);
+
+DELETE FROM meta WHERE config_id <> 'default';
+ALTER TABLE meta DROP CONSTRAINT meta_pkey;
+ALTER TABLE meta DROP COLUMN config_id;
+ALTER TABLE meta ADD PRIMARY KEY (key);
@@ -0,0 +1,39 @@
+-- +goose Up
+-- История импортов: каждый снимок сохраняется пронумерованной ревизией в пределах конфигурации.
+CREATE TABLE IF NOT EXISTS revisions (
+    config_id      TEXT NOT NULL,
+    revision       INTEGER NOT NULL,
+    config_name    TEXT NOT NULL DEFAULT '',
+    config_version TEXT NOT NULL DEFAULT '',
+    exported_at    TEXT NOT NULL DEFAULT '',
+    source         TEXT NOT NULL DEFAULT '',
+    object_count   INTEGER NOT NULL DEFAULT 0,
+    imported_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (config_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_revisions_version ON revisions(config_id, config_version);
+
+-- Объект ревизии хранится целиком (JSON snapshot.Object), чтобы сравнивать любые поля.
+CREATE TABLE IF NOT EXISTS revision_objects (
+    config_id   TEXT NOT NULL,
+    revision    INTEGER NOT NULL,
+    id          TEXT NOT NULL,
+    object_json TEXT NOT NULL,
+    PRIMARY KEY (config_id, revision, id)
+);
+
+CREATE TABLE IF NOT EXISTS revision_relations (
+    config_id TEXT NOT NULL,
+    revision  INTEGER NOT NULL,
+    from_id   TEXT NOT NULL,
+    to_id     TEXT NOT NULL,
+    kind      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_revision_relations ON revision_relations(config_id, revision);
+
+-- +goose Down
+DROP TABLE IF EXISTS revision_relations;
+DROP TABLE IF EXISTS revision_objects;
+DROP TABLE IF EXISTS revisions;
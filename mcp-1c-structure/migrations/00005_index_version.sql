@@ -0,0 +1,6 @@
+-- +goose Up
+-- Версия формата индекса снимка (meta.indexVersion) сохраняется в ревизии, чтобы её можно было сравнить с версией бинарника.
+ALTER TABLE revisions ADD COLUMN IF NOT EXISTS index_version INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE revisions DROP COLUMN IF EXISTS index_version;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Полнотекстовый поиск (structure_search с mode=fulltext): tsvector со словарём russian по имени (вес A), синониму (B),
+-- описанию (C), реквизитам и колонкам табличных частей (D). Идентификаторы 1С разбиваются на слова по заглавным буквам,
+-- как в internal/search.Words: ДоговорКонтрагента -> Договор Контрагента.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION structure_words(t TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$ SELECT regexp_replace(t, '([а-яёa-z])([А-ЯЁA-Z])', '\1 \2', 'g') $$;
+-- +goose StatementEnd
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', structure_words(name)), 'A') ||
+    setweight(to_tsvector('russian', structure_words(synonym)), 'B') ||
+    setweight(to_tsvector('russian', structure_words(description)), 'C') ||
+    setweight(to_tsvector('russian', structure_words(
+        jsonb_path_query_array(props_json::jsonb, '$[*].name')::text || ' ' ||
+        jsonb_path_query_array(props_json::jsonb, '$[*].synonym')::text || ' ' ||
+        jsonb_path_query_array(tabular_sections_json::jsonb, '$[*].name')::text || ' ' ||
+        jsonb_path_query_array(tabular_sections_json::jsonb, '$[*].props[*].name')::text || ' ' ||
+        jsonb_path_query_array(tabular_sections_json::jsonb, '$[*].props[*].synonym')::text
+    )), 'D')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_objects_search ON objects USING gin (search_tsv);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_objects_search;
+ALTER TABLE objects DROP COLUMN IF EXISTS search_tsv;
+DROP FUNCTION IF EXISTS structure_words(TEXT);
@@ -0,0 +1,94 @@
+-- +goose Up
+-- Реквизиты объектов и колонки табличных частей построчно — для поиска объектов по имени и типу реквизита
+-- (structure_find_by_prop). Таблица ведётся триггерами на objects из props_json и tabular_sections_json,
+-- поэтому импорт и дельта пишут только в objects.
+CREATE TABLE IF NOT EXISTS object_props (
+    config_id TEXT    NOT NULL,
+    object_id TEXT    NOT NULL,
+    section   TEXT    NOT NULL DEFAULT '', -- '' — реквизит объекта, иначе имя табличной части
+    position  INTEGER NOT NULL,            -- порядковый номер реквизита в объекте или табличной части, с 1
+    name      TEXT    NOT NULL,
+    type      TEXT    NOT NULL,
+    synonym   TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_object_props_object ON object_props(config_id, object_id);
+CREATE INDEX IF NOT EXISTS idx_object_props_name ON object_props(config_id, LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_object_props_type ON object_props(config_id, LOWER(type));
+
+-- +goose StatementBegin
+-- Строки object_props одного объекта. props_json бывает 'null' (пустой срез в Go), такие значения пропускаются.
+CREATE OR REPLACE FUNCTION structure_object_props(config_id TEXT, object_id TEXT, props_json TEXT, tabular_sections_json TEXT)
+RETURNS SETOF object_props
+LANGUAGE sql IMMUTABLE
+AS $$
+    SELECT config_id, object_id, '', p.ord::int,
+           COALESCE(p.v->>'name', ''), COALESCE(p.v->>'type', ''), COALESCE(p.v->>'synonym', '')
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(props_json::jsonb) = 'array' THEN props_json::jsonb ELSE '[]' END)
+         WITH ORDINALITY AS p(v, ord)
+    UNION ALL
+    SELECT config_id, object_id, COALESCE(t.v->>'name', ''), c.ord::int,
+           COALESCE(c.v->>'name', ''), COALESCE(c.v->>'type', ''), COALESCE(c.v->>'synonym', '')
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(tabular_sections_json::jsonb) = 'array' THEN tabular_sections_json::jsonb ELSE '[]' END) AS t(v),
+         jsonb_array_elements(CASE WHEN jsonb_typeof(t.v->'props') = 'array' THEN t.v->'props' ELSE '[]' END)
+         WITH ORDINALITY AS c(v, ord)
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION structure_object_props_insert() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    INSERT INTO object_props
+    SELECT p.* FROM new_rows n, structure_object_props(n.config_id, n.id, n.props_json, n.tabular_sections_json) p;
+    RETURN NULL;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION structure_object_props_delete() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM object_props p USING old_rows o WHERE p.config_id = o.config_id AND p.object_id = o.id;
+    RETURN NULL;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION structure_object_props_update() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    DELETE FROM object_props p USING old_rows o WHERE p.config_id = o.config_id AND p.object_id = o.id;
+    INSERT INTO object_props
+    SELECT p.* FROM new_rows n, structure_object_props(n.config_id, n.id, n.props_json, n.tabular_sections_json) p;
+    RETURN NULL;
+END
+$$;
+-- +goose StatementEnd
+
+-- Триггеры уровня оператора: импорт переносит объекты одним INSERT … SELECT, и реквизиты разбираются тем же
+-- одним запросом по таблице переходов, а не по строке.
+CREATE TRIGGER objects_props_insert AFTER INSERT ON objects
+    REFERENCING NEW TABLE AS new_rows FOR EACH STATEMENT EXECUTE FUNCTION structure_object_props_insert();
+CREATE TRIGGER objects_props_delete AFTER DELETE ON objects
+    REFERENCING OLD TABLE AS old_rows FOR EACH STATEMENT EXECUTE FUNCTION structure_object_props_delete();
+CREATE TRIGGER objects_props_update AFTER UPDATE ON objects
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows FOR EACH STATEMENT EXECUTE FUNCTION structure_object_props_update();
+
+INSERT INTO object_props
+SELECT p.* FROM objects o, structure_object_props(o.config_id, o.id, o.props_json, o.tabular_sections_json) p;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS objects_props_update ON objects;
+DROP TRIGGER IF EXISTS objects_props_delete ON objects;
+DROP TRIGGER IF EXISTS objects_props_insert ON objects;
+DROP FUNCTION IF EXISTS structure_object_props_update();
+DROP FUNCTION IF EXISTS structure_object_props_delete();
+DROP FUNCTION IF EXISTS structure_object_props_insert();
+DROP FUNCTION IF EXISTS structure_object_props(TEXT, TEXT, TEXT, TEXT);
+DROP TABLE IF EXISTS object_props;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Связи reference, выведенные из типов реквизитов, помечаются путём реквизита (Контрагент, Товары.Номенклатура).
+-- Один объект может ссылаться на другой из нескольких реквизитов, поэтому prop входит в ключ связи.
+-- Уже загруженные снимки получат выведенные связи при следующем импорте.
+ALTER TABLE relations ADD COLUMN IF NOT EXISTS prop TEXT NOT NULL DEFAULT '';
+ALTER TABLE revision_relations ADD COLUMN IF NOT EXISTS prop TEXT NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS idx_relations_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_relations_unique ON relations(config_id, from_id, to_id, kind, prop);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_relations_unique;
+DELETE FROM relations WHERE prop <> '';
+ALTER TABLE relations DROP COLUMN IF EXISTS prop;
+ALTER TABLE revision_relations DROP COLUMN IF EXISTS prop;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_relations_unique ON relations(config_id, from_id, to_id, kind);
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Подсистемы (объекты типа Subsystem): id вложенных подсистем и состав — id входящих в подсистему объектов.
+-- У прочих объектов столбцы пустые. Уже загруженные снимки получат данные подсистем при следующем импорте.
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS subsystems TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS content TEXT NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE objects DROP COLUMN IF EXISTS content;
+ALTER TABLE objects DROP COLUMN IF EXISTS subsystems;
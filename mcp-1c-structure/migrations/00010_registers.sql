@@ -0,0 +1,49 @@
+-- +goose Up
+-- Свойства регистров (периодичность, режим записи, вид регистра накопления, план счетов) — JSON snapshot.Register;
+-- 'null' у прочих объектов. Вид поля регистра (измерение, ресурс, реквизит) хранится в props_json в поле kind
+-- и попадает в object_props для поиска structure_find_by_prop.
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS register_json TEXT NOT NULL DEFAULT 'null';
+ALTER TABLE object_props ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION structure_object_props(config_id TEXT, object_id TEXT, props_json TEXT, tabular_sections_json TEXT)
+RETURNS SETOF object_props
+LANGUAGE sql IMMUTABLE
+AS $$
+    SELECT config_id, object_id, '', p.ord::int,
+           COALESCE(p.v->>'name', ''), COALESCE(p.v->>'type', ''), COALESCE(p.v->>'synonym', ''), COALESCE(p.v->>'kind', '')
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(props_json::jsonb) = 'array' THEN props_json::jsonb ELSE '[]' END)
+         WITH ORDINALITY AS p(v, ord)
+    UNION ALL
+    SELECT config_id, object_id, COALESCE(t.v->>'name', ''), c.ord::int,
+           COALESCE(c.v->>'name', ''), COALESCE(c.v->>'type', ''), COALESCE(c.v->>'synonym', ''), COALESCE(c.v->>'kind', '')
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(tabular_sections_json::jsonb) = 'array' THEN tabular_sections_json::jsonb ELSE '[]' END) AS t(v),
+         jsonb_array_elements(CASE WHEN jsonb_typeof(t.v->'props') = 'array' THEN t.v->'props' ELSE '[]' END)
+         WITH ORDINALITY AS c(v, ord)
+$$;
+-- +goose StatementEnd
+
+DELETE FROM object_props;
+INSERT INTO object_props
+SELECT p.* FROM objects o, structure_object_props(o.config_id, o.id, o.props_json, o.tabular_sections_json) p;
+
+-- +goose Down
+ALTER TABLE object_props DROP COLUMN IF EXISTS kind;
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION structure_object_props(config_id TEXT, object_id TEXT, props_json TEXT, tabular_sections_json TEXT)
+RETURNS SETOF object_props
+LANGUAGE sql IMMUTABLE
+AS $$
+    SELECT config_id, object_id, '', p.ord::int,
+           COALESCE(p.v->>'name', ''), COALESCE(p.v->>'type', ''), COALESCE(p.v->>'synonym', '')
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(props_json::jsonb) = 'array' THEN props_json::jsonb ELSE '[]' END)
+         WITH ORDINALITY AS p(v, ord)
+    UNION ALL
+    SELECT config_id, object_id, COALESCE(t.v->>'name', ''), c.ord::int,
+           COALESCE(c.v->>'name', ''), COALESCE(c.v->>'type', ''), COALESCE(c.v->>'synonym', '')
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(tabular_sections_json::jsonb) = 'array' THEN tabular_sections_json::jsonb ELSE '[]' END) AS t(v),
+         jsonb_array_elements(CASE WHEN jsonb_typeof(t.v->'props') = 'array' THEN t.v->'props' ELSE '[]' END)
+         WITH ORDINALITY AS c(v, ord)
+$$;
+-- +goose StatementEnd
+ALTER TABLE objects DROP COLUMN IF EXISTS register_json;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Тексты модулей объектов (structure_get_module, structure_search_code). Хранятся отдельно от objects, чтобы
+-- выборки объектов не читали мегабайты кода, и только для текущего снимка: revision_objects держат имя и путь модуля.
+-- Импорт и дельта пишут таблицу сами; object_id — нормализованный id объекта.
+CREATE TABLE IF NOT EXISTS module_sources (
+    config_id   TEXT    NOT NULL,
+    object_id   TEXT    NOT NULL,
+    module      TEXT    NOT NULL,
+    position    INTEGER NOT NULL, -- порядковый номер модуля в объекте, с 1
+    object_type TEXT    NOT NULL,
+    path        TEXT    NOT NULL DEFAULT '',
+    source      TEXT    NOT NULL,
+    PRIMARY KEY (config_id, object_id, module)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS module_sources;
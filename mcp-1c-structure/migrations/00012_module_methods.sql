@@ -0,0 +1,22 @@
+-- +goose Up
+-- Процедуры и функции модулей (structure_find_method), разобранные при импорте из module_sources (internal/bsl).
+-- Таблицу пишут импорт и дельта вместе с module_sources; у модулей, загруженных до этой миграции, методы появятся
+-- после следующего импорта. name_lower — имя в нижнем регистре (strings.ToLower), поиск идёт по нему.
+CREATE TABLE IF NOT EXISTS module_methods (
+    config_id   TEXT    NOT NULL,
+    object_id   TEXT    NOT NULL,
+    module      TEXT    NOT NULL,
+    line        INTEGER NOT NULL, -- строка объявления, с 1
+    name        TEXT    NOT NULL,
+    name_lower  TEXT    NOT NULL,
+    object_type TEXT    NOT NULL,
+    object_name TEXT    NOT NULL,
+    export      BOOLEAN NOT NULL,
+    method_json TEXT    NOT NULL, -- bsl.Method целиком
+    PRIMARY KEY (config_id, object_id, module, line)
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_methods_name ON module_methods (config_id, name_lower);
+
+-- +goose Down
+DROP TABLE IF EXISTS module_methods;
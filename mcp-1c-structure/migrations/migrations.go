@@ -0,0 +1,8 @@
+// Package migrations встраивает миграции схемы PostgreSQL (формат goose) в бинарники:
+// indexer и MCP-сервер применяют или проверяют их при старте (internal/store/postgres).
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS